@@ -0,0 +1,140 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// Field keys of HTTP request logs.
+const (
+	FieldKeyHTTPRemoteAddr = "remote_addr"
+	FieldKeyHTTPUser       = "user"
+	FieldKeyHTTPMethod     = "method"
+	FieldKeyHTTPPath       = "path"
+	FieldKeyHTTPProto      = "proto"
+	FieldKeyHTTPStatus     = "status"
+	FieldKeyHTTPBytes      = "bytes"
+	FieldKeyHTTPReferer    = "referer"
+	FieldKeyHTTPUserAgent  = "user_agent"
+	FieldKeyHTTPDuration   = "duration"
+)
+
+// AccessLogOutput is an implementation of Output by writing HTTP request logs to io.Writer w in the Apache combined log format:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.0" 200 2326 "http://example.com/" "Mozilla/4.08"
+//
+// The values are taken from the fields with the keys FieldKeyHTTP*. Missing values are printed as "-".
+type AccessLogOutput struct {
+	mu sync.RWMutex
+	w  io.Writer
+	health
+}
+
+// NewAccessLogOutput creates a new AccessLogOutput.
+func NewAccessLogOutput(w io.Writer) *AccessLogOutput {
+	return &AccessLogOutput{
+		w: w,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *AccessLogOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	values := make(map[string]string, len(log.Fields))
+	for _, field := range log.Fields {
+		values[field.Key] = fmt.Sprintf("%v", field.Value)
+	}
+	value := func(key string) string {
+		if v := values[key]; v != "" {
+			return v
+		}
+		return "-"
+	}
+
+	host := value(FieldKeyHTTPRemoteAddr)
+	if h, _, e := net.SplitHostPort(host); e == nil {
+		host = h
+	}
+
+	size := value(FieldKeyHTTPBytes)
+	if size == "0" {
+		size = "-"
+	}
+
+	request := "-"
+	if values[FieldKeyHTTPMethod] != "" || values[FieldKeyHTTPPath] != "" {
+		request = value(FieldKeyHTTPMethod) + " " + value(FieldKeyHTTPPath)
+		if proto := values[FieldKeyHTTPProto]; proto != "" {
+			request += " " + proto
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	buf.WriteString(host)
+	buf.WriteString(" - ")
+	buf.WriteString(value(FieldKeyHTTPUser))
+	buf.WriteString(" [")
+	buf.WriteString(log.Time.Format("02/Jan/2006:15:04:05 -0700"))
+	buf.WriteString("] ")
+	buf.WriteString(strconv.Quote(request))
+	buf.WriteRune(' ')
+	buf.WriteString(value(FieldKeyHTTPStatus))
+	buf.WriteRune(' ')
+	buf.WriteString(size)
+	buf.WriteRune(' ')
+	buf.WriteString(strconv.Quote(value(FieldKeyHTTPReferer)))
+	buf.WriteRune(' ')
+	buf.WriteString(strconv.Quote(value(FieldKeyHTTPUserAgent)))
+	buf.WriteRune('\n')
+
+	_, err = io.Copy(o.w, buf)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *AccessLogOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer.
+// It returns the underlying AccessLogOutput.
+func (o *AccessLogOutput) SetWriter(w io.Writer) *AccessLogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying AccessLogOutput.
+func (o *AccessLogOutput) SetOnError(f func(error)) *AccessLogOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *AccessLogOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *AccessLogOutput) Healthy() bool {
+	return o.healthy()
+}
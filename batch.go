@@ -0,0 +1,34 @@
+package logng
+
+import (
+	"fmt"
+)
+
+// BatchOutput is an optional interface for Output implementations which can write multiple logs at once,
+// e.g. by a single write or request. QueuedOutput passes its batches to LogBatch if the output implements it.
+type BatchOutput interface {
+	Output
+	LogBatch(logs []*Log)
+}
+
+// logBatch passes the given logs to output.LogBatch if the output implements BatchOutput,
+// otherwise to output.Log one by one.
+func logBatch(output Output, logs []*Log) {
+	if b, ok := output.(BatchOutput); ok && len(logs) > 1 {
+		b.LogBatch(logs)
+		return
+	}
+	for _, log := range logs {
+		output.Log(log)
+	}
+}
+
+// safeLogBatch calls logBatch by recovering panics and reporting them to the error handler.
+func safeLogBatch(output Output, logs []*Log) {
+	defer func() {
+		if r := recover(); r != nil {
+			handleError(fmt.Errorf("%w: %v", ErrOutputPanic, r))
+		}
+	}()
+	logBatch(output, logs)
+}
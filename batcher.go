@@ -0,0 +1,178 @@
+package logng
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// batcher collects the pending items of a batching output, and passes them in batches to the send function
+// by a background goroutine, when the batch is full or the flush interval elapses. It also keeps the last error
+// and the health of the output. The batching outputs such as KafkaOutput are built on it.
+type batcher struct {
+	mu            sync.Mutex
+	items         []interface{}
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	stopped       bool
+	send          func(items []interface{}) error
+	drop          func(items []interface{}, err error)
+	sendMu        sync.Mutex
+	flushCh       chan struct{}
+	stopCh        chan struct{}
+	wg            sync.WaitGroup
+	health
+}
+
+// start initializes the batcher and starts the background goroutine. By default, the maximum pending count is 10000.
+// send is called with the items of each batch. drop is called with the items which are dropped, if it is not nil.
+func (b *batcher) start(batchSize int, flushInterval time.Duration,
+	send func(items []interface{}) error, drop func(items []interface{}, err error)) {
+	b.batchSize = batchSize
+	b.flushInterval = flushInterval
+	b.maxPending = 10000
+	b.send = send
+	b.drop = drop
+	b.flushCh = make(chan struct{}, 1)
+	b.stopCh = make(chan struct{})
+	b.wg.Add(1)
+	go b.worker()
+}
+
+// add appends the given item to the pending items. The item is dropped with ErrClosed if the batcher has been
+// closed, and with ErrQueueFull if the pending items have reached the maximum.
+func (b *batcher) add(item interface{}) {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		b.handleError(ErrClosed)
+		return
+	}
+	if b.maxPending > 0 && len(b.items) >= b.maxPending {
+		b.mu.Unlock()
+		b.handleDrop([]interface{}{item}, fmt.Errorf("unable to queue log: %w", ErrQueueFull))
+		return
+	}
+	b.items = append(b.items, item)
+	full := len(b.items) >= b.batchSize
+	b.mu.Unlock()
+	if full {
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush sends the pending items in batches immediately. If a batch can't be sent, it is dropped, and flush returns
+// the error. The rest of the pending items are kept for the next flush.
+func (b *batcher) flush() error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+	for {
+		b.mu.Lock()
+		n := len(b.items)
+		if n > b.batchSize {
+			n = b.batchSize
+		}
+		items := b.items[:n:n]
+		b.items = b.items[n:]
+		if len(b.items) == 0 {
+			b.items = nil
+		}
+		b.mu.Unlock()
+		if len(items) == 0 {
+			return nil
+		}
+		if err := b.send(items); err != nil {
+			b.handleDrop(items, err)
+			return err
+		}
+		b.setHealthy()
+	}
+}
+
+// close stops the background goroutine and sends the pending items.
+func (b *batcher) close() error {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return nil
+	}
+	b.stopped = true
+	close(b.stopCh)
+	b.mu.Unlock()
+	b.wg.Wait()
+	return b.flush()
+}
+
+// sleep waits for the given duration, and returns false if the batcher is closed meanwhile.
+// It is used by the send functions to abort retrying on closing.
+func (b *batcher) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-b.stopCh:
+		return false
+	}
+}
+
+func (b *batcher) setBatchSize(batchSize int) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batchSize = batchSize
+}
+
+func (b *batcher) setFlushInterval(flushInterval time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushInterval = flushInterval
+}
+
+func (b *batcher) setMaxPending(maxPending int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.maxPending = maxPending
+}
+
+func (b *batcher) worker() {
+	defer b.wg.Done()
+	for {
+		b.mu.Lock()
+		flushInterval := b.flushInterval
+		b.mu.Unlock()
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if flushInterval > 0 {
+			timer = time.NewTimer(flushInterval)
+			timeout = timer.C
+		}
+		select {
+		case <-b.stopCh:
+		case <-b.flushCh:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-b.stopCh:
+			return
+		default:
+		}
+		_ = b.flush()
+	}
+}
+
+func (b *batcher) handleDrop(items []interface{}, err error) {
+	b.handleError(err)
+	if b.drop != nil {
+		b.drop(items, err)
+	}
+}
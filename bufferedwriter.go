@@ -0,0 +1,150 @@
+package logng
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// BufferedWriter is an io.WriteCloser that batches writes to the underlying io.Writer.
+// It is designed to sit under TextOutput or JSONOutput, and it is safe for concurrency.
+// The buffer is flushed when it is full, when the flush interval elapses, and on Flush or Close.
+type BufferedWriter struct {
+	mu      sync.Mutex
+	bw      *bufio.Writer
+	closed  bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+	onError *func(error)
+}
+
+// NewBufferedWriter creates a new BufferedWriter by the given writer, buffer size and flush interval.
+// If size is less or equal than 0, the default buffer size of bufio is used.
+// If flushInterval is less or equal than 0, the buffer is never flushed periodically.
+func NewBufferedWriter(w io.Writer, size int, flushInterval time.Duration) *BufferedWriter {
+	var bw *bufio.Writer
+	if size > 0 {
+		bw = bufio.NewWriterSize(w, size)
+	} else {
+		bw = bufio.NewWriter(w)
+	}
+	bufw := &BufferedWriter{
+		bw:      bw,
+		closeCh: make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		bufw.wg.Add(1)
+		go bufw.flusher(flushInterval)
+	}
+	return bufw
+}
+
+// Write is the implementation of io.Writer.
+// It returns ErrClosed if the underlying BufferedWriter has been closed.
+func (w *BufferedWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	return w.bw.Write(p)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *BufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.bw.Flush()
+}
+
+// Close stops periodic flushing and flushes any buffered data.
+// It doesn't close the underlying writer.
+func (w *BufferedWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.closeCh)
+	w.mu.Unlock()
+	w.wg.Wait()
+	return w.Flush()
+}
+
+// SetOnError sets a function to call when error occurs while periodic flushing.
+// It returns the underlying BufferedWriter.
+func (w *BufferedWriter) SetOnError(f func(error)) *BufferedWriter {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&w.onError)), unsafe.Pointer(&f))
+	return w
+}
+
+func (w *BufferedWriter) flusher(flushInterval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.closeCh:
+			return
+		case <-ticker.C:
+			err := w.Flush()
+			if err == nil {
+				break
+			}
+			onError := w.onError
+			if onError != nil && *onError != nil {
+				(*onError)(fmt.Errorf("unable to flush: %w", err))
+			}
+		}
+	}
+}
+
+type bufferedOutput struct {
+	output Output
+	w      *BufferedWriter
+	closer io.Closer
+}
+
+func (o *bufferedOutput) Log(log *Log) {
+	o.output.Log(log)
+}
+
+func (o *bufferedOutput) LogBatch(logs []*Log) {
+	logBatch(o.output, logs)
+}
+
+func (o *bufferedOutput) Flush() error {
+	if err := flushOutput(o.output); err != nil {
+		return err
+	}
+	return o.w.Flush()
+}
+
+func (o *bufferedOutput) Close() error {
+	err := o.w.Close()
+	if e := closeOutput(o.output); e != nil && err == nil {
+		err = e
+	}
+	if o.closer != nil {
+		if e := o.closer.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// BufferedOutput creates an output that passes its logs to the given output, which writes to the given
+// BufferedWriter, e.g. TextOutput or JSONOutput. Unlike the given output, the created output flushes
+// the BufferedWriter on Flush, and closes it on Close before closing the given output.
+// So, the buffered bytes aren't lost by Logger.Sync and Logger.Close.
+func BufferedOutput(output Output, w *BufferedWriter) Output {
+	return &bufferedOutput{
+		output: output,
+		w:      w,
+	}
+}
@@ -0,0 +1,34 @@
+package logng
+
+import (
+	"runtime/debug"
+)
+
+// BuildInfoFields returns the fields "module_path" and "module_version" of the main module, and "vcs_revision" and
+// "vcs_modified" of the version control, read from the build info embedded in the running binary.
+// It returns nil if the build info isn't available, e.g. the binary isn't built with module support.
+// The VCS fields are available only if the binary is built by Go 1.18 or later in a version-controlled directory.
+func BuildInfoFields() Fields {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok || bi == nil {
+		return nil
+	}
+	fields := make(Fields, 0, 4)
+	if bi.Main.Path != "" {
+		fields = append(fields, Field{Key: "module_path", Value: bi.Main.Path})
+	}
+	if bi.Main.Version != "" {
+		fields = append(fields, Field{Key: "module_version", Value: bi.Main.Version})
+	}
+	return append(fields, buildInfoVCSFields(bi)...)
+}
+
+// WithBuildInfo clones the default Logger with the build info fields. See BuildInfoFields.
+func WithBuildInfo() *Logger {
+	return defaultLogger.WithBuildInfo()
+}
+
+// WithBuildInfo clones the underlying Logger with the build info fields. See BuildInfoFields.
+func (l *Logger) WithBuildInfo() *Logger {
+	return l.WithFields(BuildInfoFields()...)
+}
@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package logng
+
+import (
+	"runtime/debug"
+)
+
+// buildInfoVCSFields returns the VCS fields of the given build info.
+func buildInfoVCSFields(bi *debug.BuildInfo) Fields {
+	var fields Fields
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			fields = append(fields, Field{Key: "vcs_revision", Value: setting.Value})
+		case "vcs.modified":
+			fields = append(fields, Field{Key: "vcs_modified", Value: setting.Value == "true"})
+		}
+	}
+	return fields
+}
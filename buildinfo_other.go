@@ -0,0 +1,13 @@
+//go:build !go1.18
+// +build !go1.18
+
+package logng
+
+import (
+	"runtime/debug"
+)
+
+// buildInfoVCSFields returns nil, because the VCS info isn't embedded in the build info before Go 1.18.
+func buildInfoVCSFields(bi *debug.BuildInfo) Fields {
+	return nil
+}
@@ -0,0 +1,87 @@
+package logng
+
+import (
+	"sync"
+	"time"
+)
+
+// callSiteLimit limits the logs of the Logger by their call sites.
+type callSiteLimit struct {
+	once     bool
+	interval time.Duration
+}
+
+// callSiteKey is the key of a call site and its limit.
+type callSiteKey struct {
+	pc    uintptr
+	limit callSiteLimit
+}
+
+// callSiteState holds the state of a call site.
+type callSiteState struct {
+	mu      sync.Mutex
+	logged  bool
+	last    time.Time
+	skipped int
+}
+
+var callSites sync.Map
+
+// allow reports whether the log at the given program counter is allowed by the limit,
+// and returns the number of the skipped logs since the last allowed log.
+func (c callSiteLimit) allow(pc uintptr, now time.Time) (bool, int) {
+	if pc == 0 {
+		return true, 0
+	}
+	v, ok := callSites.Load(callSiteKey{pc: pc, limit: c})
+	if !ok {
+		v, _ = callSites.LoadOrStore(callSiteKey{pc: pc, limit: c}, &callSiteState{})
+	}
+	s := v.(*callSiteState)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logged && (c.once || now.Sub(s.last) < c.interval) {
+		s.skipped++
+		return false, 0
+	}
+	skipped := s.skipped
+	s.logged, s.last, s.skipped = true, now, 0
+	return true, skipped
+}
+
+// Once clones the default Logger to log only once by the call site. See Logger.Once.
+func Once() *Logger {
+	return defaultLogger.Once()
+}
+
+// Every clones the default Logger to log at most once per the given interval by the call site. See Logger.Every.
+func Every(interval time.Duration) *Logger {
+	return defaultLogger.Every(interval)
+}
+
+// Once clones the underlying Logger to log only once by the call site, e.g. a message inside a hot loop.
+// The call site is the caller of the log method, so Once should be called in the same expression:
+//
+//	logger.Once().Warning("deprecated option is used")
+func (l *Logger) Once() *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	l2.callSiteLimit = &callSiteLimit{once: true}
+	return l2
+}
+
+// Every clones the underlying Logger to log at most once per the given interval by the call site.
+// The log after the skipped logs has the field "skipped" with the number of the skipped logs.
+// The call site is the caller of the log method, so Every should be called in the same expression:
+//
+//	logger.Every(time.Minute).Warning("queue is full")
+func (l *Logger) Every(interval time.Duration) *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	l2.callSiteLimit = &callSiteLimit{interval: interval}
+	return l2
+}
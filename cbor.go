@@ -0,0 +1,107 @@
+package logng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// jsonToCBOR transcodes the given json value into CBOR, preserving the order of object keys.
+func jsonToCBOR(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	b, err := appendCBORValue(make([]byte, 0, len(data)), dec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to transcode json to cbor: %w", err)
+	}
+	return b, nil
+}
+
+func appendCBORValue(dst []byte, dec *json.Decoder) ([]byte, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return dst, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		var body []byte
+		n := 0
+		for dec.More() {
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return dst, err
+				}
+				body = appendCBORString(body, key.(string))
+			}
+			if body, err = appendCBORValue(body, dec); err != nil {
+				return dst, err
+			}
+			n++
+		}
+		if _, err = dec.Token(); err != nil {
+			return dst, err
+		}
+		if t == '{' {
+			dst = appendCBORHead(dst, 5, uint64(n))
+		} else {
+			dst = appendCBORHead(dst, 4, uint64(n))
+		}
+		return append(dst, body...), nil
+	case nil:
+		return append(dst, 0xf6), nil
+	case bool:
+		if t {
+			return append(dst, 0xf5), nil
+		}
+		return append(dst, 0xf4), nil
+	case string:
+		return appendCBORString(dst, t), nil
+	case json.Number:
+		if x, e := strconv.ParseInt(string(t), 10, 64); e == nil {
+			if x < 0 {
+				return appendCBORHead(dst, 1, uint64(-(x + 1))), nil
+			}
+			return appendCBORHead(dst, 0, uint64(x)), nil
+		}
+		if x, e := strconv.ParseUint(string(t), 10, 64); e == nil {
+			return appendCBORHead(dst, 0, x), nil
+		}
+		x, e := strconv.ParseFloat(string(t), 64)
+		if e != nil {
+			return dst, e
+		}
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(x))
+		return append(append(dst, 0xfb), b[:]...), nil
+	default:
+		return dst, io.ErrUnexpectedEOF
+	}
+}
+
+// appendCBORHead appends the head of a CBOR data item by the given major type and argument.
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(dst, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		return append(append(dst, major|27), b[:]...)
+	}
+}
+
+func appendCBORString(dst []byte, s string) []byte {
+	return append(appendCBORHead(dst, 3, uint64(len(s))), s...)
+}
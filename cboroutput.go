@@ -0,0 +1,105 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CBOROutput is an implementation of Output by writing CBOR to io.Writer w.
+// Each log is encoded as a map with the same keys and values as JSONOutput by the given flags.
+// The maps are written one after another as a CBOR sequence.
+type CBOROutput struct {
+	mu   sync.RWMutex
+	w    io.Writer
+	json *JSONOutput
+	health
+}
+
+// NewCBOROutput creates a new CBOROutput.
+func NewCBOROutput(w io.Writer, flags JSONOutputFlag) *CBOROutput {
+	return &CBOROutput{
+		w:    w,
+		json: NewJSONOutput(nil, flags),
+	}
+}
+
+// Log is the implementation of Output.
+func (o *CBOROutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.json.mu.RLock()
+	err = o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		return
+	}
+
+	var b []byte
+	b, err = jsonToCBOR(buf.Bytes())
+	if err != nil {
+		return
+	}
+
+	_, err = o.w.Write(b)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *CBOROutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer.
+// It returns the underlying CBOROutput.
+func (o *CBOROutput) SetWriter(w io.Writer) *CBOROutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	return o
+}
+
+// SetFlags sets the flags of the json schema.
+// It returns the underlying CBOROutput.
+func (o *CBOROutput) SetFlags(flags JSONOutputFlag) *CBOROutput {
+	o.json.SetFlags(flags)
+	return o
+}
+
+// SetTimeLayout sets a time layout to format time field.
+// It returns the underlying CBOROutput.
+func (o *CBOROutput) SetTimeLayout(timeLayout string) *CBOROutput {
+	o.json.SetTimeLayout(timeLayout)
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying CBOROutput.
+func (o *CBOROutput) SetOnError(f func(error)) *CBOROutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *CBOROutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *CBOROutput) Healthy() bool {
+	return o.healthy()
+}
@@ -0,0 +1,210 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CEFOutput is an implementation of Output by writing ArcSight Common Event Format (CEF) records to io.Writer w:
+//
+//	CEF:0|Vendor|Product|1.0|log|connection refused|7|rt=1289567655000 reason=EOF src=10.0.0.1
+//
+// The message is used as the name, and the severity is mapped to the range 0-10. Fields are written as extensions
+// with their keys, or with the CEF keys which are mapped by SetExtensionMapping. If the signature ID key is set,
+// the value of the field with this key is used as the signature ID instead of "log".
+//
+// Optionally, IBM QRadar Log Event Extended Format (LEEF) 1.0 records are written with tab delimited attributes.
+type CEFOutput struct {
+	mu             sync.RWMutex
+	w              io.Writer
+	vendor         string
+	product        string
+	version        string
+	mapping        map[string]string
+	signatureIDKey string
+	leef           bool
+	health
+}
+
+// NewCEFOutput creates a new CEFOutput by the given writer, and the device vendor, product and version of the header.
+func NewCEFOutput(w io.Writer, vendor, product, version string) *CEFOutput {
+	return &CEFOutput{
+		w:       w,
+		vendor:  vendor,
+		product: product,
+		version: version,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *CEFOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.format(buf, log)
+
+	_, err = io.Copy(o.w, buf)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+func (o *CEFOutput) format(buf *bytes.Buffer, log *Log) {
+	signatureID := "log"
+	extensions := make([][2]string, 0, len(log.Fields)+2)
+	for _, field := range log.Fields.Flatten() {
+		value := fmt.Sprintf("%v", fieldValue(field.Value))
+		if o.signatureIDKey != "" && field.Key == o.signatureIDKey {
+			signatureID = value
+			continue
+		}
+		key := field.Key
+		if k, ok := o.mapping[key]; ok {
+			key = k
+		}
+		extensions = append(extensions, [2]string{key, value})
+	}
+	if log.Error != nil {
+		extensions = append(extensions, [2]string{"reason", log.Error.Error()})
+	}
+	severity := cefSeverity(log.Severity)
+	rt := strconv.FormatInt(log.Time.UnixNano()/1e6, 10)
+
+	if o.leef {
+		header := strings.NewReplacer("|", " ", "\r", " ", "\n", " ")
+		buf.WriteString("LEEF:1.0|")
+		for _, s := range []string{o.vendor, o.product, o.version, signatureID} {
+			buf.WriteString(header.Replace(s))
+			buf.WriteRune('|')
+		}
+		value := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+		buf.WriteString("devTime=" + rt + "\tdevTimeFormat=epoch\tsev=" + strconv.Itoa(severity))
+		buf.WriteString("\tmsg=" + value.Replace(string(log.Message)))
+		for _, e := range extensions {
+			buf.WriteString("\t" + cefKey(e[0]) + "=" + value.Replace(e[1]))
+		}
+		buf.WriteRune('\n')
+		return
+	}
+
+	header := strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", " ", "\n", " ")
+	buf.WriteString("CEF:0|")
+	for _, s := range []string{o.vendor, o.product, o.version, signatureID, string(log.Message)} {
+		buf.WriteString(header.Replace(s))
+		buf.WriteRune('|')
+	}
+	buf.WriteString(strconv.Itoa(severity))
+	buf.WriteString("|rt=" + rt)
+	value := strings.NewReplacer(`\`, `\\`, "=", `\=`, "\r", `\r`, "\n", `\n`)
+	for _, e := range extensions {
+		buf.WriteString(" " + cefKey(e[0]) + "=" + value.Replace(e[1]))
+	}
+	buf.WriteRune('\n')
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *CEFOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer.
+// It returns the underlying CEFOutput.
+func (o *CEFOutput) SetWriter(w io.Writer) *CEFOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	return o
+}
+
+// SetExtensionMapping sets the mapping from field keys to CEF extension keys, e.g. "remote_addr" to "src".
+// It returns the underlying CEFOutput.
+func (o *CEFOutput) SetExtensionMapping(mapping map[string]string) *CEFOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.mapping = make(map[string]string, len(mapping))
+	for key, value := range mapping {
+		o.mapping[key] = value
+	}
+	return o
+}
+
+// SetSignatureIDKey sets the key of the field whose value is used as the signature ID, or the event ID in LEEF.
+// It returns the underlying CEFOutput.
+func (o *CEFOutput) SetSignatureIDKey(signatureIDKey string) *CEFOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.signatureIDKey = signatureIDKey
+	return o
+}
+
+// SetLEEF sets whether LEEF 1.0 records are written instead of CEF records.
+// It returns the underlying CEFOutput.
+func (o *CEFOutput) SetLEEF(leef bool) *CEFOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.leef = leef
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying CEFOutput.
+func (o *CEFOutput) SetOnError(f func(error)) *CEFOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *CEFOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *CEFOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// cefSeverity returns the CEF severity between 0 and 10 by the given Severity.
+func cefSeverity(severity Severity) int {
+	switch severity {
+	case SeverityEmergency, SeverityAlert, SeverityFatal, SeverityPanic:
+		return 10
+	case SeverityCritical:
+		return 9
+	case SeverityError:
+		return 7
+	case SeverityWarning:
+		return 5
+	case SeverityNotice:
+		return 4
+	case SeverityInfo:
+		return 3
+	case SeverityDebug:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// cefKey returns the given key by replacing the characters which aren't allowed in extension keys.
+func cefKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '|' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,229 @@
+package logng
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState describes the state of CircuitBreakerOutput.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state which passes the logs to the output.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen is the state which rejects the logs without passing them to the output.
+	CircuitOpen
+
+	// CircuitHalfOpen is the state which probes the output with a log to decide closing or opening the circuit.
+	CircuitHalfOpen
+)
+
+// String is the implementation of fmt.Stringer.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerOutput is an implementation of Output by passing the logs to the underlying output through
+// a circuit breaker. It protects the latency of the application from a dead destination.
+// The output is considered as failed when the health function returns false after the log. By default,
+// the health function uses HealthChecker if the output implements it, otherwise the output never fails.
+//
+// The circuit opens after the given count of consecutive failures. While the circuit is open, the logs are passed
+// to the fallback output if it is set, otherwise they are dropped with ErrCircuitOpen. After the open timeout,
+// the circuit becomes half-open and the next log probes the output. The circuit closes if the probe succeeds,
+// otherwise it opens again.
+type CircuitBreakerOutput struct {
+	mu            sync.Mutex
+	output        Output
+	fallback      Output
+	threshold     int
+	openTimeout   time.Duration
+	state         CircuitState
+	failures      int
+	openedAt      time.Time
+	probing       bool
+	healthFunc    func(Output) bool
+	onStateChange func(from, to CircuitState)
+}
+
+// NewCircuitBreakerOutput creates a new CircuitBreakerOutput by the given output, the count of consecutive failures
+// to open the circuit, and the duration to keep the circuit open before probing.
+// If threshold is less than 1, it is 1. If openTimeout is less or equal than 0, it is 10 seconds.
+func NewCircuitBreakerOutput(output Output, threshold int, openTimeout time.Duration) *CircuitBreakerOutput {
+	if threshold < 1 {
+		threshold = 1
+	}
+	if openTimeout <= 0 {
+		openTimeout = 10 * time.Second
+	}
+	return &CircuitBreakerOutput{
+		output:      output,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		healthFunc:  defaultHealthFunc,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *CircuitBreakerOutput) Log(log *Log) {
+	o.mu.Lock()
+	fallback, healthFunc := o.fallback, o.healthFunc
+	var changes [][2]CircuitState
+	if o.state == CircuitOpen && time.Since(o.openedAt) >= o.openTimeout {
+		changes = append(changes, o.setState(CircuitHalfOpen))
+	}
+	var probe bool
+	switch o.state {
+	case CircuitOpen:
+		o.mu.Unlock()
+		o.reject(fallback, log)
+		return
+	case CircuitHalfOpen:
+		if o.probing {
+			o.mu.Unlock()
+			o.reject(fallback, log)
+			return
+		}
+		o.probing = true
+		probe = true
+	}
+	o.mu.Unlock()
+	o.notify(changes)
+	changes = changes[:0]
+
+	o.output.Log(log)
+	ok := healthFunc(o.output)
+
+	o.mu.Lock()
+	if probe {
+		o.probing = false
+	}
+	if ok {
+		o.failures = 0
+		if probe {
+			changes = append(changes, o.setState(CircuitClosed))
+		}
+	} else {
+		o.failures++
+		if probe || (o.state == CircuitClosed && o.failures >= o.threshold) {
+			o.openedAt = time.Now()
+			changes = append(changes, o.setState(CircuitOpen))
+		}
+	}
+	o.mu.Unlock()
+	o.notify(changes)
+
+	if !ok && fallback != nil {
+		fallback.Log(log)
+	}
+}
+
+// Close is the implementation of io.Closer. It closes the output and the fallback output.
+func (o *CircuitBreakerOutput) Close() error {
+	o.mu.Lock()
+	outputs := []Output{o.output}
+	if o.fallback != nil {
+		outputs = append(outputs, o.fallback)
+	}
+	o.mu.Unlock()
+	return closeOutputs(outputs)
+}
+
+// Flush is the implementation of Flusher. It flushes the output and the fallback output.
+func (o *CircuitBreakerOutput) Flush() error {
+	o.mu.Lock()
+	outputs := []Output{o.output}
+	if o.fallback != nil {
+		outputs = append(outputs, o.fallback)
+	}
+	o.mu.Unlock()
+	return flushOutputs(outputs)
+}
+
+// State returns the current state of the circuit.
+func (o *CircuitBreakerOutput) State() CircuitState {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// Healthy is the implementation of HealthChecker. It reports whether the circuit isn't open.
+// So, CircuitBreakerOutput can be used in FailoverOutput.
+func (o *CircuitBreakerOutput) Healthy() bool {
+	return o.State() != CircuitOpen
+}
+
+// SetFallback sets the output to pass the logs while the circuit is open, and the logs which have failed.
+// If fallback is nil, these logs are dropped.
+// It returns the underlying CircuitBreakerOutput.
+func (o *CircuitBreakerOutput) SetFallback(fallback Output) *CircuitBreakerOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fallback = fallback
+	return o
+}
+
+// SetHealthFunc sets the function which reports whether the output has logged the last log successfully.
+// If f is nil, the default health function is used.
+// It returns the underlying CircuitBreakerOutput.
+func (o *CircuitBreakerOutput) SetHealthFunc(f func(output Output) bool) *CircuitBreakerOutput {
+	if f == nil {
+		f = defaultHealthFunc
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.healthFunc = f
+	return o
+}
+
+// SetOnStateChange sets a function to call when the state of the circuit has changed.
+// It returns the underlying CircuitBreakerOutput.
+func (o *CircuitBreakerOutput) SetOnStateChange(f func(from, to CircuitState)) *CircuitBreakerOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onStateChange = f
+	return o
+}
+
+// setState sets the state, and returns the change. It must be called with o.mu held.
+func (o *CircuitBreakerOutput) setState(state CircuitState) [2]CircuitState {
+	change := [2]CircuitState{o.state, state}
+	o.state = state
+	return change
+}
+
+// notify calls the state change function with the given changes.
+func (o *CircuitBreakerOutput) notify(changes [][2]CircuitState) {
+	if len(changes) == 0 {
+		return
+	}
+	o.mu.Lock()
+	f := o.onStateChange
+	o.mu.Unlock()
+	if f == nil {
+		return
+	}
+	for _, change := range changes {
+		f(change[0], change[1])
+	}
+}
+
+// reject passes the given log to the fallback output if it is not nil, otherwise drops the log.
+func (o *CircuitBreakerOutput) reject(fallback Output, log *Log) {
+	if fallback != nil {
+		fallback.Log(log)
+		return
+	}
+	handleError(fmt.Errorf("unable to log: %w", ErrCircuitOpen))
+}
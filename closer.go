@@ -0,0 +1,46 @@
+package logng
+
+import (
+	"io"
+)
+
+// closeOutput closes the given output if it implements io.Closer.
+func closeOutput(output Output) error {
+	if c, ok := output.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeOutputs closes the given outputs by closeOutput, and returns the first error.
+func closeOutputs(outputs []Output) (err error) {
+	for _, output := range outputs {
+		if e := closeOutput(output); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Close closes the default Logger's output tree and the outputs registered by RegisterOutput, and returns
+// the first error. The default Logger's output is closed once even if it has been registered. See Logger.Close.
+func Close() error {
+	defaultLogger.mu.RLock()
+	output := defaultLogger.output
+	defaultLogger.mu.RUnlock()
+	return closeOutputs(registeredOutputs(output))
+}
+
+// Close closes the underlying Logger's output tree. The outputs which implement io.Closer are closed,
+// and the wrapper outputs such as MultiOutput close their outputs in turn. QueuedOutput closes its output
+// only if QueuedOutput.SetCloseOutput has been enabled.
+// It returns the first error. The Logger must not be used for logging after Close.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	output := l.output
+	l.mu.RUnlock()
+	return closeOutput(output)
+}
@@ -0,0 +1,151 @@
+package logng
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// CloudLoggingOutput is an implementation of Output by writing json to io.Writer w with the keys which
+// Google Cloud Logging expects from the structured logs of GKE, Cloud Run and Cloud Functions:
+//
+//	{"severity":"ERROR","message":"...","time":"...","logging.googleapis.com/sourceLocation":{...},...}
+//
+// The value of the field with the key FieldKeyTraceID is written as logging.googleapis.com/trace
+// by the project ID, and the value of the field with the key FieldKeySpanID is written as logging.googleapis.com/spanId.
+// Other fields are written with their keys, Log.Error as error and Log.StackTrace as stack_trace.
+type CloudLoggingOutput struct {
+	mu        sync.Mutex
+	json      *JSONOutput
+	projectID string
+}
+
+// NewCloudLoggingOutput creates a new CloudLoggingOutput by the given writer, e.g. os.Stdout, and the project ID.
+// If projectID is empty, the trace ID is written without the project prefix.
+func NewCloudLoggingOutput(w io.Writer, projectID string) *CloudLoggingOutput {
+	o := &CloudLoggingOutput{
+		json:      NewJSONOutput(w, 0),
+		projectID: projectID,
+	}
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *CloudLoggingOutput) Log(log *Log) {
+	o.json.Log(log)
+}
+
+// preset returns the jsonPreset by the project ID.
+func (o *CloudLoggingOutput) preset() *jsonPreset {
+	projectID := o.projectID
+	return &jsonPreset{
+		head: func(log *Log) []jsonAttr {
+			attrs := []jsonAttr{
+				{"severity", cloudLoggingSeverity(log.Severity)},
+				{"message", string(log.Message)},
+				{"time", log.Time.Format(time.RFC3339Nano)},
+			}
+			if log.StackCaller.Function != "" {
+				sourceLocation := struct {
+					File     string `json:"file"`
+					Line     string `json:"line"`
+					Function string `json:"function"`
+				}{
+					File:     log.StackCaller.File,
+					Line:     fmt.Sprintf("%d", log.StackCaller.Line),
+					Function: log.StackCaller.Function,
+				}
+				attrs = append(attrs, jsonAttr{"logging.googleapis.com/sourceLocation", &sourceLocation})
+			}
+			return attrs
+		},
+		fieldKeys: map[string]jsonFieldKey{
+			FieldKeyTraceID: {"logging.googleapis.com/trace", func(v interface{}) interface{} {
+				trace := fmt.Sprintf("%v", v)
+				if projectID != "" {
+					trace = fmt.Sprintf("projects/%s/traces/%s", projectID, trace)
+				}
+				return trace
+			}},
+			FieldKeySpanID: {"logging.googleapis.com/spanId", func(v interface{}) interface{} {
+				return fmt.Sprintf("%v", v)
+			}},
+		},
+		tail: func(log *Log) []jsonAttr {
+			var attrs []jsonAttr
+			if log.Error != nil {
+				attrs = append(attrs, jsonAttr{"error", log.Error.Error()})
+			}
+			if log.StackTrace != nil {
+				attrs = append(attrs, jsonAttr{"stack_trace", fmt.Sprintf("%+.1s", log.StackTrace)})
+			}
+			return attrs
+		},
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *CloudLoggingOutput) Flush() error {
+	return o.json.Flush()
+}
+
+// SetWriter sets writer.
+// It returns the underlying CloudLoggingOutput.
+func (o *CloudLoggingOutput) SetWriter(w io.Writer) *CloudLoggingOutput {
+	o.json.SetWriter(w)
+	return o
+}
+
+// SetProjectID sets the project ID which is used to write trace IDs.
+// It returns the underlying CloudLoggingOutput.
+func (o *CloudLoggingOutput) SetProjectID(projectID string) *CloudLoggingOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.projectID = projectID
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying CloudLoggingOutput.
+func (o *CloudLoggingOutput) SetOnError(f func(error)) *CloudLoggingOutput {
+	o.json.SetOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *CloudLoggingOutput) Err() error {
+	return o.json.Err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *CloudLoggingOutput) Healthy() bool {
+	return o.json.Healthy()
+}
+
+// cloudLoggingSeverity returns the Cloud Logging severity by the given Severity.
+func cloudLoggingSeverity(severity Severity) string {
+	switch severity {
+	case SeverityEmergency:
+		return "EMERGENCY"
+	case SeverityAlert:
+		return "ALERT"
+	case SeverityFatal, SeverityPanic, SeverityCritical:
+		return "CRITICAL"
+	case SeverityError:
+		return "ERROR"
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityNotice:
+		return "NOTICE"
+	case SeverityInfo:
+		return "INFO"
+	case SeverityDebug:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
@@ -0,0 +1,29 @@
+package logng
+
+import (
+	"io"
+	"os/exec"
+)
+
+type commandLogCloser []*LineWriter
+
+func (c commandLogCloser) Close() error {
+	for _, w := range c {
+		_ = w.Close()
+	}
+	return nil
+}
+
+// CommandLogger wires the given command's stdout to SeverityInfo logs and stderr to SeverityError logs of the given logger.
+// The logs have the field "cmd" with the command's path. It must be called before the command starts.
+// The returned io.Closer should be closed after the command has finished, for logging the remaining partial lines.
+// The stdout and stderr of the command are logged concurrently, so the writer of the logger's output must be safe
+// for concurrent use.
+func CommandLogger(cmd *exec.Cmd, logger *Logger) io.Closer {
+	logger = logger.WithFieldKeyVals("cmd", cmd.Path)
+	stdout := NewLineWriter(logger, SeverityInfo)
+	stderr := NewLineWriter(logger, SeverityError)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return commandLogCloser{stdout, stderr}
+}
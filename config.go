@@ -0,0 +1,571 @@
+package logng
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config describes a Logger declaratively. The zero values of the fields mean their defaults.
+type Config struct {
+	// Severity is the severity of the Logger. By default, SeverityInfo.
+	Severity Severity `json:"severity"`
+
+	// Verbose is the verbose of the Logger.
+	Verbose Verbose `json:"verbose"`
+
+	// VModule is the per-module verbose of the Logger. See Logger.SetVModule.
+	VModule string `json:"vmodule"`
+
+	// PrintSeverity is the severity of the Print methods. By default, SeverityInfo.
+	PrintSeverity Severity `json:"print_severity"`
+
+	// StackTraceSeverity is the severity which the logs at or above have the stack trace.
+	// By default, SeverityNone which means no stack trace.
+	StackTraceSeverity Severity `json:"stack_trace_severity"`
+
+	// StackTraceSize is the maximum number of callers in the stack trace. By default, 64.
+	StackTraceSize int `json:"stack_trace_size"`
+
+	// Output holds the output settings.
+	Output OutputConfig `json:"output"`
+
+	// Outputs holds the settings of additional outputs. The logs are written to all outputs by MultiOutput.
+	Outputs []OutputConfig `json:"outputs"`
+
+	// Fields holds the static fields of every log. They are added in the order of their keys.
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// OutputConfig describes the output of a Logger which is created by Config.
+type OutputConfig struct {
+	// Output is the output to use as it is. If it is set, the other settings are ignored.
+	Output Output `json:"-"`
+
+	// Writer is the writer to write logs. If it is set, Path is ignored.
+	Writer io.Writer `json:"-"`
+
+	// Path is "stderr", "stdout" or the path of the file to write logs by FileOutput. By default, "stderr".
+	Path string `json:"path"`
+
+	// Format is "text" for TextOutput or "json" for JSONOutput. By default, "text".
+	Format string `json:"format"`
+
+	// TextFlags holds the flags of TextOutput. By default, TextOutputFlagDefault.
+	TextFlags TextOutputFlag `json:"text_flags"`
+
+	// JSONFlags holds the flags of JSONOutput. By default, JSONOutputFlagDefault.
+	JSONFlags JSONOutputFlag `json:"json_flags"`
+
+	// MaxSize is the maximum size of the file in bytes before rotation. By default, 0 which means no rotation.
+	MaxSize int64 `json:"max_size"`
+
+	// MaxAge is the maximum age of the rotated files. By default, 0 which means no removal by age.
+	MaxAge time.Duration `json:"max_age"`
+
+	// MaxBackups is the maximum count of the rotated files. By default, 0 which means no removal by count.
+	MaxBackups int `json:"max_backups"`
+
+	// Compress enables compressing the rotated files by gzip.
+	Compress bool `json:"compress"`
+
+	// QueueLen is the queue length of QueuedOutput which wraps the output if it is greater than 0.
+	QueueLen int `json:"queue_len"`
+
+	// BufferSize is the buffer size of BufferedWriter which wraps the writer if BufferSize or FlushInterval is
+	// greater than 0. By default, 0 which means the default buffer size of bufio.
+	BufferSize int `json:"buffer_size"`
+
+	// FlushInterval is the flush interval of BufferedWriter. By default, 0 which means no periodic flushing.
+	FlushInterval time.Duration `json:"flush_interval"`
+}
+
+// NewLogger creates a new Logger by the underlying Config.
+// It returns an error if the output can't be created, e.g. the file can't be opened. In this case, the outputs
+// which have already been created are closed, except the outputs given by OutputConfig.Output.
+func (c Config) NewLogger() (*Logger, error) {
+	vm, err := parseVModule(c.VModule)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.Output.newOutput()
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Outputs) > 0 {
+		outputs := []Output{output}
+		created := make([]Output, 0, 1+len(c.Outputs))
+		if c.Output.Output == nil {
+			created = append(created, output)
+		}
+		for _, oc := range c.Outputs {
+			o, err := oc.newOutput()
+			if err != nil {
+				_ = closeOutputs(created)
+				return nil, err
+			}
+			outputs = append(outputs, o)
+			if oc.Output == nil {
+				created = append(created, o)
+			}
+		}
+		output = MultiOutput(outputs...)
+	}
+	severity := c.Severity
+	if severity == SeverityNone {
+		severity = SeverityInfo
+	}
+	logger := NewLogger(output, severity, c.Verbose)
+	logger.vmodule = vm
+	if c.PrintSeverity != SeverityNone {
+		logger.SetPrintSeverity(c.PrintSeverity)
+	}
+	logger.SetStackTraceSeverity(c.StackTraceSeverity)
+	if c.StackTraceSize > 0 {
+		logger.SetStackTraceSize(c.StackTraceSize)
+	}
+	if len(c.Fields) > 0 {
+		keys := make([]string, 0, len(c.Fields))
+		for key := range c.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make(Fields, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, Field{Key: key, Value: c.Fields[key]})
+		}
+		logger = logger.WithFields(fields...)
+	}
+	return logger, nil
+}
+
+func (c OutputConfig) newOutput() (Output, error) {
+	output, err := c.newBaseOutput()
+	if err != nil {
+		return nil, err
+	}
+	if c.QueueLen > 0 {
+		output = NewQueuedOutput(output, c.QueueLen).SetCloseOutput(c.Output == nil)
+	}
+	return output, nil
+}
+
+func (c OutputConfig) newBaseOutput() (Output, error) {
+	if c.Output != nil {
+		return c.Output, nil
+	}
+	textFlags := c.TextFlags
+	if textFlags == 0 {
+		textFlags = TextOutputFlagDefault
+	}
+	jsonFlags := c.JSONFlags
+	if jsonFlags == 0 {
+		jsonFlags = JSONOutputFlagDefault
+	}
+
+	w := c.Writer
+	var file *FileOutput
+	if w == nil {
+		switch c.Path {
+		case "", "stderr":
+			w = os.Stderr
+		case "stdout":
+			w = os.Stdout
+		default:
+			var err error
+			file, err = NewFileOutput(c.Path, textFlags)
+			if err != nil {
+				return nil, fmt.Errorf("unable to create file output: %w", err)
+			}
+			file.SetMaxSize(c.MaxSize).SetMaxAge(c.MaxAge).SetMaxBackups(c.MaxBackups).SetCompress(c.Compress)
+			w = file
+		}
+	}
+
+	var bufw *BufferedWriter
+	if c.BufferSize > 0 || c.FlushInterval > 0 {
+		bufw = NewBufferedWriter(w, c.BufferSize, c.FlushInterval)
+		w = bufw
+	}
+
+	var output Output
+	switch c.Format {
+	case "", "text":
+		if file != nil && bufw == nil {
+			return file, nil
+		}
+		output = NewTextOutput(w, textFlags)
+	case "json":
+		output = NewJSONOutput(w, jsonFlags)
+	default:
+		if bufw != nil {
+			_ = bufw.Close()
+		}
+		if file != nil {
+			_ = file.Close()
+		}
+		return nil, fmt.Errorf("unknown output format %q", c.Format)
+	}
+	if bufw != nil {
+		o := &bufferedOutput{
+			output: output,
+			w:      bufw,
+		}
+		if file != nil {
+			o.closer = file
+		}
+		output = o
+	} else if file != nil {
+		output = &fileWriterOutput{
+			output: output,
+			file:   file,
+		}
+	}
+	return output, nil
+}
+
+// fileWriterOutput passes its logs to the output which writes to the file, and closes the file on Close.
+type fileWriterOutput struct {
+	output Output
+	file   *FileOutput
+}
+
+func (o *fileWriterOutput) Log(log *Log) {
+	o.output.Log(log)
+}
+
+func (o *fileWriterOutput) LogBatch(logs []*Log) {
+	logBatch(o.output, logs)
+}
+
+func (o *fileWriterOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+func (o *fileWriterOutput) Close() error {
+	err := closeOutput(o.output)
+	if e := o.file.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+func (o *fileWriterOutput) Healthy() bool {
+	return defaultHealthFunc(o.output)
+}
+
+// UnmarshalJSON is the implementation of json.Unmarshaler.
+// The severities are parsed by ParseSeverity, so they accept the aliases such as "warn" and the numeric values
+// in addition to the names.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+	var v struct {
+		*config
+		Severity           json.RawMessage `json:"severity"`
+		PrintSeverity      json.RawMessage `json:"print_severity"`
+		StackTraceSeverity json.RawMessage `json:"stack_trace_severity"`
+	}
+	v.config = (*config)(c)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	for _, x := range []struct {
+		data     json.RawMessage
+		severity *Severity
+		name     string
+	}{
+		{v.Severity, &c.Severity, "severity"},
+		{v.PrintSeverity, &c.PrintSeverity, "print severity"},
+		{v.StackTraceSeverity, &c.StackTraceSeverity, "stack trace severity"},
+	} {
+		if len(x.data) == 0 {
+			continue
+		}
+		severity, err := unmarshalSeverity(x.data)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal %s: %w", x.name, err)
+		}
+		*x.severity = severity
+	}
+	return nil
+}
+
+// unmarshalSeverity unmarshals Severity from a json string or number by ParseSeverity.
+func unmarshalSeverity(data []byte) (Severity, error) {
+	var x interface{}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return SeverityNone, err
+	}
+	switch x := x.(type) {
+	case nil:
+		return SeverityNone, nil
+	case string:
+		return ParseSeverity(x)
+	case float64:
+		return ParseSeverity(string(data))
+	default:
+		return SeverityNone, fmt.Errorf("invalid severity %s", data)
+	}
+}
+
+// UnmarshalJSON is the implementation of json.Unmarshaler.
+// In addition to the numeric values, MaxAge and FlushInterval accept duration strings such as "24h", and TextFlags
+// and JSONFlags accept flag names as a string separated by "|" or an array, e.g. "date|time|severity" or
+// ["date", "time", "severity"].
+// The flag names are the snake case names of the flag constants without the prefix, e.g. "short_file" and "default".
+func (c *OutputConfig) UnmarshalJSON(data []byte) error {
+	type outputConfig OutputConfig
+	var v struct {
+		*outputConfig
+		TextFlags     json.RawMessage `json:"text_flags"`
+		JSONFlags     json.RawMessage `json:"json_flags"`
+		MaxAge        json.RawMessage `json:"max_age"`
+		FlushInterval json.RawMessage `json:"flush_interval"`
+	}
+	v.outputConfig = (*outputConfig)(c)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.TextFlags) > 0 {
+		flags, err := unmarshalFlags(v.TextFlags, textOutputFlagNames)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal text flags: %w", err)
+		}
+		c.TextFlags = TextOutputFlag(flags)
+	}
+	if len(v.JSONFlags) > 0 {
+		flags, err := unmarshalFlags(v.JSONFlags, jsonOutputFlagNames)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal json flags: %w", err)
+		}
+		c.JSONFlags = JSONOutputFlag(flags)
+	}
+	if len(v.MaxAge) > 0 {
+		d, err := unmarshalDuration(v.MaxAge)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal max age: %w", err)
+		}
+		c.MaxAge = d
+	}
+	if len(v.FlushInterval) > 0 {
+		d, err := unmarshalDuration(v.FlushInterval)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal flush interval: %w", err)
+		}
+		c.FlushInterval = d
+	}
+	return nil
+}
+
+// textOutputFlagNames maps the names of TextOutputFlag's to their values.
+var textOutputFlagNames = map[string]int{
+	"date":                   int(TextOutputFlagDate),
+	"time":                   int(TextOutputFlagTime),
+	"microseconds":           int(TextOutputFlagMicroseconds),
+	"utc":                    int(TextOutputFlagUTC),
+	"severity":               int(TextOutputFlagSeverity),
+	"padding":                int(TextOutputFlagPadding),
+	"long_func":              int(TextOutputFlagLongFunc),
+	"short_func":             int(TextOutputFlagShortFunc),
+	"long_file":              int(TextOutputFlagLongFile),
+	"short_file":             int(TextOutputFlagShortFile),
+	"fields":                 int(TextOutputFlagFields),
+	"stack_trace":            int(TextOutputFlagStackTrace),
+	"stack_trace_short_file": int(TextOutputFlagStackTraceShortFile),
+	"rfc3339":                int(TextOutputFlagRFC3339),
+	"rfc3339_milli":          int(TextOutputFlagRFC3339Milli),
+	"color":                  int(TextOutputFlagColor),
+	"uptime":                 int(TextOutputFlagUptime),
+	"default":                int(TextOutputFlagDefault),
+}
+
+// jsonOutputFlagNames maps the names of JSONOutputFlag's to their values.
+var jsonOutputFlagNames = map[string]int{
+	"severity":               int(JSONOutputFlagSeverity),
+	"time":                   int(JSONOutputFlagTime),
+	"local_tz":               int(JSONOutputFlagLocalTZ),
+	"utc":                    int(JSONOutputFlagUTC),
+	"timestamp":              int(JSONOutputFlagTimestamp),
+	"timestamp_micro":        int(JSONOutputFlagTimestampMicro),
+	"severity_level":         int(JSONOutputFlagSeverityLevel),
+	"verbosity":              int(JSONOutputFlagVerbosity),
+	"long_func":              int(JSONOutputFlagLongFunc),
+	"short_func":             int(JSONOutputFlagShortFunc),
+	"long_file":              int(JSONOutputFlagLongFile),
+	"short_file":             int(JSONOutputFlagShortFile),
+	"stack_trace":            int(JSONOutputFlagStackTrace),
+	"stack_trace_short_file": int(JSONOutputFlagStackTraceShortFile),
+	"fields":                 int(JSONOutputFlagFields),
+	"error":                  int(JSONOutputFlagError),
+	"nested_fields":          int(JSONOutputFlagNestedFields),
+	"uptime":                 int(JSONOutputFlagUptime),
+	"default":                int(JSONOutputFlagDefault),
+}
+
+// unmarshalFlags unmarshals flags from a json number, a string of names separated by "|", or an array of names.
+func unmarshalFlags(data []byte, names map[string]int) (int, error) {
+	var x interface{}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return 0, err
+	}
+	var list []string
+	switch x := x.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int(x), nil
+	case string:
+		list = strings.Split(x, "|")
+	case []interface{}:
+		for _, e := range x {
+			name, ok := e.(string)
+			if !ok {
+				return 0, fmt.Errorf("invalid flag %v", e)
+			}
+			list = append(list, name)
+		}
+	default:
+		return 0, fmt.Errorf("invalid flags %s", data)
+	}
+	flags := 0
+	for _, name := range list {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		flag, ok := names[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown flag %q", name)
+		}
+		flags |= flag
+	}
+	return flags, nil
+}
+
+// ParseConfig parses the given json document as Config, and creates a new Logger by it.
+// The severities are given by their names or aliases, e.g. "debug" or "warn"; see Config.UnmarshalJSON, and
+// OutputConfig.UnmarshalJSON for the output settings.
+// YAML documents can be parsed by the package github.com/goinsane/logng/v2/yamlconfig.
+func ParseConfig(data []byte) (*Logger, error) {
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unable to parse config: %w", err)
+	}
+	return c.NewLogger()
+}
+
+// LoadConfig reads the json document from the given file, and creates a new Logger by ParseConfig.
+func LoadConfig(path string) (*Logger, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// Option is a function to configure a Logger which is created by NewLoggerWithOptions.
+type Option func(c *Config)
+
+// NewLoggerWithOptions creates a new Logger by applying the given options to the zero Config in order.
+func NewLoggerWithOptions(opts ...Option) (*Logger, error) {
+	var c Config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.NewLogger()
+}
+
+// OptionConfig returns an Option which replaces the whole Config with the given one.
+func OptionConfig(config Config) Option {
+	return func(c *Config) {
+		*c = config
+	}
+}
+
+// OptionSeverity returns an Option which sets the severity.
+func OptionSeverity(severity Severity) Option {
+	return func(c *Config) {
+		c.Severity = severity
+	}
+}
+
+// OptionVerbose returns an Option which sets the verbose.
+func OptionVerbose(verbose Verbose) Option {
+	return func(c *Config) {
+		c.Verbose = verbose
+	}
+}
+
+// OptionPrintSeverity returns an Option which sets the severity of the Print methods.
+func OptionPrintSeverity(printSeverity Severity) Option {
+	return func(c *Config) {
+		c.PrintSeverity = printSeverity
+	}
+}
+
+// OptionStackTraceSeverity returns an Option which sets the stack trace severity.
+func OptionStackTraceSeverity(stackTraceSeverity Severity) Option {
+	return func(c *Config) {
+		c.StackTraceSeverity = stackTraceSeverity
+	}
+}
+
+// OptionStackTraceSize returns an Option which sets the stack trace size.
+func OptionStackTraceSize(stackTraceSize int) Option {
+	return func(c *Config) {
+		c.StackTraceSize = stackTraceSize
+	}
+}
+
+// OptionOutput returns an Option which sets the output to use as it is.
+func OptionOutput(output Output) Option {
+	return func(c *Config) {
+		c.Output.Output = output
+	}
+}
+
+// OptionOutputConfig returns an Option which sets the output settings.
+func OptionOutputConfig(outputConfig OutputConfig) Option {
+	return func(c *Config) {
+		c.Output = outputConfig
+	}
+}
+
+// OptionFields returns an Option which adds the given static fields.
+func OptionFields(fields map[string]interface{}) Option {
+	return func(c *Config) {
+		if c.Fields == nil {
+			c.Fields = make(map[string]interface{}, len(fields))
+		}
+		for key, value := range fields {
+			c.Fields[key] = value
+		}
+	}
+}
+
+// unmarshalDuration unmarshals the given JSON number of nanoseconds or duration string such as "24h".
+func unmarshalDuration(data []byte) (time.Duration, error) {
+	var x interface{}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return 0, err
+	}
+	switch x := x.(type) {
+	case float64:
+		return time.Duration(x), nil
+	case string:
+		d, err := time.ParseDuration(x)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse duration: %w", err)
+		}
+		return d, nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("invalid duration %s", data)
+	}
+}
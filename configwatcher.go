@@ -0,0 +1,232 @@
+package logng
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ConfigWatcher watches a json config file by polling, and applies the changes to a Logger at runtime.
+// So, operators can turn on debug logging without restarting.
+//
+// When the file changes, a new Config is parsed and the severity, verbose, vmodule, print severity,
+// stack trace settings, static fields and the output of the Logger are replaced at once. The static fields of
+// the config replace the ones of the previous config, and the Logger's own fields are kept after them.
+//
+// The Logger writes to the ConfigWatcher's current output. So, the loggers which have been cloned from the Logger
+// before, e.g. by the With methods, write to the new output too; but their other settings aren't affected.
+// The previous output is closed after the replacement, if it has been created by the ConfigWatcher and
+// implements io.Closer.
+type ConfigWatcher struct {
+	mu       sync.Mutex
+	path     string
+	logger   *Logger
+	interval time.Duration
+	data     []byte
+	outputMu sync.RWMutex
+	output   Output
+	nFields  int
+	stopCh   chan struct{}
+	stopped  bool
+	wg       sync.WaitGroup
+	onReload *func(Config)
+	health
+}
+
+// NewConfigWatcher creates a new ConfigWatcher by the given config file path and Logger, and starts watching.
+// If logger is nil, the default Logger is used. It applies the config immediately, and returns the error if
+// the config can't be applied. By default, the polling interval is 2 seconds.
+func NewConfigWatcher(path string, logger *Logger) (*ConfigWatcher, error) {
+	if logger == nil {
+		logger = defaultLogger
+	}
+	w := &ConfigWatcher{
+		path:     path,
+		logger:   logger,
+		interval: 2 * time.Second,
+		stopCh:   make(chan struct{}),
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.worker()
+	return w, nil
+}
+
+// Reload reads the config file, and applies it if it has changed since the last reload.
+// It is called periodically by the polling, and can also be called explicitly, e.g. on SIGHUP.
+// The OnReload function is called after the config has been applied, without holding the lock of ConfigWatcher.
+func (w *ConfigWatcher) Reload() error {
+	c, changed, err := w.reload()
+	if err != nil || !changed {
+		return err
+	}
+	onReload := (*func(Config))(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&w.onReload))))
+	if onReload != nil && *onReload != nil {
+		(*onReload)(c)
+	}
+	return nil
+}
+
+// reload applies the config file if it has changed, and returns the applied config.
+func (w *ConfigWatcher) reload() (c Config, changed bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return c, false, fmt.Errorf("unable to read config file: %w", err)
+	}
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return c, false, nil
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, false, fmt.Errorf("unable to parse config: %w", err)
+	}
+	l, err := c.NewLogger()
+	if err != nil {
+		return c, false, err
+	}
+
+	w.outputMu.Lock()
+	prevOutput := w.output
+	w.output = l.output
+	w.outputMu.Unlock()
+
+	w.logger.mu.Lock()
+	w.logger.output = configWatcherOutput{w}
+	w.logger.severity = l.severity
+	w.logger.verbose = l.verbose
+	w.logger.vmodule = l.vmodule
+	w.logger.printSeverity = l.printSeverity
+	w.logger.stackTraceSeverity = l.stackTraceSeverity
+	w.logger.stackTraceSize = l.stackTraceSize
+	// the Logger's own fields are kept after the fields of the config, so its groups are shifted.
+	shift := len(l.fields) - w.nFields
+	fields := make(Fields, 0, len(w.logger.fields)+shift)
+	fields = append(fields, l.fields...)
+	fields = append(fields, w.logger.fields[w.nFields:]...)
+	w.logger.fields = fields
+	if shift != 0 && len(w.logger.groups) > 0 {
+		groups := make([]loggerGroup, 0, len(w.logger.groups))
+		for _, g := range w.logger.groups {
+			groups = append(groups, loggerGroup{name: g.name, start: g.start + shift})
+		}
+		w.logger.groups = groups
+	}
+	w.logger.mu.Unlock()
+
+	if prevOutput != nil {
+		_ = closeOutput(prevOutput)
+	}
+	w.nFields = len(l.fields)
+	w.data = data
+	return c, true, nil
+}
+
+// Close stops watching. It doesn't close the current output of the Logger.
+func (w *ConfigWatcher) Close() error {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return nil
+	}
+	w.stopped = true
+	close(w.stopCh)
+	w.mu.Unlock()
+	w.wg.Wait()
+	return nil
+}
+
+func (w *ConfigWatcher) worker() {
+	defer w.wg.Done()
+	for {
+		w.mu.Lock()
+		interval := w.interval
+		w.mu.Unlock()
+		timer := time.NewTimer(interval)
+		select {
+		case <-w.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := w.Reload(); err != nil {
+			w.handleError(err)
+			continue
+		}
+		w.setHealthy()
+	}
+}
+
+// SetInterval sets the polling interval.
+// It returns the underlying ConfigWatcher.
+func (w *ConfigWatcher) SetInterval(interval time.Duration) *ConfigWatcher {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.interval = interval
+	return w
+}
+
+// SetOnError sets a function to call when the config can't be reloaded by the polling.
+// The Logger keeps the last applied config in this case.
+// It returns the underlying ConfigWatcher.
+func (w *ConfigWatcher) SetOnError(f func(error)) *ConfigWatcher {
+	w.setOnError(f)
+	return w
+}
+
+// SetOnReload sets a function to call after a changed config has been applied.
+// It returns the underlying ConfigWatcher.
+func (w *ConfigWatcher) SetOnReload(f func(config Config)) *ConfigWatcher {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&w.onReload)), unsafe.Pointer(&f))
+	return w
+}
+
+// Err returns the most recent error occurred while reloading by the polling, or nil if no error has occurred yet.
+func (w *ConfigWatcher) Err() error {
+	return w.err()
+}
+
+// Healthy reports whether the last reload by the polling has been successful.
+// It returns true if no reload has been done yet.
+func (w *ConfigWatcher) Healthy() bool {
+	return w.healthy()
+}
+
+// configWatcherOutput is an implementation of Output which writes to the ConfigWatcher's current output.
+type configWatcherOutput struct {
+	w *ConfigWatcher
+}
+
+// Log is the implementation of Output.
+func (o configWatcherOutput) Log(log *Log) {
+	o.w.outputMu.RLock()
+	output := o.w.output
+	o.w.outputMu.RUnlock()
+	if output != nil {
+		output.Log(log)
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the ConfigWatcher's current output.
+func (o configWatcherOutput) Flush() error {
+	o.w.outputMu.RLock()
+	output := o.w.output
+	o.w.outputMu.RUnlock()
+	return flushOutput(output)
+}
+
+// Close is the implementation of io.Closer. It closes the ConfigWatcher's current output.
+func (o configWatcherOutput) Close() error {
+	o.w.outputMu.RLock()
+	output := o.w.output
+	o.w.outputMu.RUnlock()
+	return closeOutput(output)
+}
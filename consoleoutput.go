@@ -0,0 +1,221 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// consoleMessageColumn is the column of the message after the time, severity and caller columns.
+const consoleMessageColumn = 12 + 4 + 21
+
+// ConsoleOutput is an implementation of Output by writing human-oriented texts to io.Writer w for development.
+// Each log is written in aligned columns:
+//
+//	1.234s WRN main.go:42           login failed            user=john attempt=3 error="bad password"
+//
+// The time is relative to the start time, the severity is abbreviated, and the continuation lines of the message
+// are aligned with the message column. The stack trace is written on the following lines with indentation.
+// Severities, field keys, errors and stack traces are colored if the writer is a terminal,
+// unless the environment variable NO_COLOR is set.
+type ConsoleOutput struct {
+	mu           sync.RWMutex
+	w            io.Writer
+	start        time.Time
+	messageWidth int
+	cw           io.Writer
+	color        bool
+	health
+}
+
+// NewConsoleOutput creates a new ConsoleOutput. The start time is the creation time.
+func NewConsoleOutput(w io.Writer) *ConsoleOutput {
+	o := &ConsoleOutput{
+		w:            w,
+		start:        time.Now(),
+		messageWidth: 40,
+	}
+	o.cw, o.color = colorWriter(w)
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *ConsoleOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.format(buf, log)
+
+	_, err = io.Copy(o.cw, buf)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+func (o *ConsoleOutput) format(buf *bytes.Buffer, log *Log) {
+	buf.WriteString(fmt.Sprintf("%10.3fs ", log.Time.Sub(o.start).Seconds()))
+	o.colorize(buf, defaultSeverityColor(log.Severity), consoleSeverity(log.Severity))
+	buf.WriteRune(' ')
+
+	caller := "???"
+	if log.StackCaller.File != "" {
+		caller = fmt.Sprintf("%s:%d", trimDirs(log.StackCaller.File), log.StackCaller.Line)
+	}
+	o.colorize(buf, "90", fmt.Sprintf("%-20s", caller))
+	buf.WriteRune(' ')
+
+	padding := strings.Repeat(" ", consoleMessageColumn)
+	lines := bytes.Split(log.Message, []byte("\n"))
+	for idx, line := range lines {
+		if idx > 0 {
+			buf.WriteRune('\n')
+			buf.WriteString(padding)
+		}
+		buf.Write(line)
+	}
+
+	if len(log.Fields) > 0 || log.Error != nil {
+		if n := len(lines[len(lines)-1]); n < o.messageWidth {
+			buf.WriteString(strings.Repeat(" ", o.messageWidth-n))
+		}
+		for _, field := range log.Fields.Flatten() {
+			buf.WriteRune(' ')
+			o.colorize(buf, "36", field.Key)
+			buf.WriteRune('=')
+			buf.WriteString(consoleValue(fmt.Sprintf("%v", fieldValue(field.Value))))
+		}
+		if log.Error != nil {
+			buf.WriteRune(' ')
+			o.colorize(buf, "31", "error="+consoleValue(log.Error.Error()))
+		}
+	}
+	buf.WriteRune('\n')
+
+	if log.StackTrace != nil {
+		o.colorize(buf, "90", fmt.Sprintf("% +#4.2s", log.StackTrace))
+		buf.WriteRune('\n')
+	}
+}
+
+// colorize writes s into buf in the color of the given ANSI SGR parameters if coloring is enabled.
+func (o *ConsoleOutput) colorize(buf *bytes.Buffer, color string, s string) {
+	if !o.color || color == "" {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString("\x1b[" + color + "m")
+	buf.WriteString(s)
+	buf.WriteString("\x1b[0m")
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *ConsoleOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer. Coloring is enabled or disabled by the new writer.
+// It returns the underlying ConsoleOutput.
+func (o *ConsoleOutput) SetWriter(w io.Writer) *ConsoleOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	o.cw, o.color = colorWriter(w)
+	return o
+}
+
+// SetStartTime sets the time which the log times are relative to.
+// It returns the underlying ConsoleOutput.
+func (o *ConsoleOutput) SetStartTime(start time.Time) *ConsoleOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.start = start
+	return o
+}
+
+// SetMessageWidth sets the width of the message column which fields are aligned after. By default, 40.
+// It returns the underlying ConsoleOutput.
+func (o *ConsoleOutput) SetMessageWidth(messageWidth int) *ConsoleOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messageWidth = messageWidth
+	return o
+}
+
+// SetColor forces enabling or disabling coloring regardless of the writer and NO_COLOR.
+// It returns the underlying ConsoleOutput.
+func (o *ConsoleOutput) SetColor(color bool) *ConsoleOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.color = color
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying ConsoleOutput.
+func (o *ConsoleOutput) SetOnError(f func(error)) *ConsoleOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *ConsoleOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *ConsoleOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// consoleSeverity returns the abbreviation of the given Severity.
+func consoleSeverity(severity Severity) string {
+	switch severity {
+	case SeverityEmergency:
+		return "EMR"
+	case SeverityAlert:
+		return "ALR"
+	case SeverityFatal:
+		return "FTL"
+	case SeverityPanic:
+		return "PNC"
+	case SeverityCritical:
+		return "CRT"
+	case SeverityError:
+		return "ERR"
+	case SeverityWarning:
+		return "WRN"
+	case SeverityNotice:
+		return "NTC"
+	case SeverityInfo:
+		return "INF"
+	case SeverityDebug:
+		return "DBG"
+	default:
+		return "---"
+	}
+}
+
+// consoleValue returns the given value as it is, or quoted if it is empty or has spaces, quotes or equal signs.
+func consoleValue(value string) string {
+	if value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || !unicode.IsPrint(r) || r == '"' || r == '='
+	}) >= 0 {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package logng
+
+import (
+	"context"
+	"sync"
+)
+
+// loggerContextKey is the context key of the Logger carried by context.
+type loggerContextKey struct{}
+
+// NewContext returns a new context by the given parent context which carries the given Logger.
+// So, the request-scoped loggers with prebound fields can travel through the call stacks.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the Logger carried by the given context.
+// If the context doesn't carry a Logger, it returns the default Logger.
+// If any context extractor has been registered, it returns a clone of the Logger with the fields extracted from
+// the context.
+func FromContext(ctx context.Context) *Logger {
+	logger := defaultLogger
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+			logger = l
+		}
+	}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		return logger.WithFields(fields...)
+	}
+	return logger
+}
+
+// ContextExtractor is a function which extracts the fields from the given context,
+// e.g. request id, user id or tenant id.
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	contextExtractorsMu sync.RWMutex
+	contextExtractors   []ContextExtractor
+)
+
+// RegisterContextExtractor registers the given context extractor. The fields extracted by the registered
+// extractors are attached to the logs emitted through the context-aware methods: FromContext, Logger.WithContext
+// and SlogHandler. It should be called at the initialization, e.g. in init functions.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractors = append(contextExtractors, extractor)
+}
+
+// contextFields returns the fields extracted from the given context by the registered context extractors.
+func contextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	contextExtractorsMu.RLock()
+	defer contextExtractorsMu.RUnlock()
+	var fields Fields
+	for _, extractor := range contextExtractors {
+		fields = append(fields, extractor(ctx)...)
+	}
+	return fields
+}
+
+// WithContext clones the default Logger with the fields extracted from the given context by the registered
+// context extractors.
+func WithContext(ctx context.Context) *Logger {
+	return defaultLogger.WithContext(ctx)
+}
+
+// WithContext clones the underlying Logger with the fields extracted from the given context by the registered
+// context extractors.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return l.WithFields(contextFields(ctx)...)
+}
@@ -0,0 +1,166 @@
+package logng
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// DatadogOutput is an implementation of Output by writing json to io.Writer w with the attributes which
+// the Datadog agent parses automatically:
+//
+//	{"status":"error","message":"...","timestamp":"...","logger.name":"...","error.stack":"...","dd.trace_id":"..."}
+//
+// The values of the fields with the keys FieldKeyTraceID and FieldKeySpanID are written as dd.trace_id and dd.span_id,
+// in decimal as Datadog expects. Hex IDs such as OpenTelemetry trace IDs are converted by their lower 64 bits.
+// The service, environment and version are injected as dd.service, dd.env and dd.version to correlate logs with traces.
+// Other fields are written with their keys.
+type DatadogOutput struct {
+	mu         sync.Mutex
+	json       *JSONOutput
+	loggerName string
+	service    string
+	env        string
+	version    string
+}
+
+// NewDatadogOutput creates a new DatadogOutput by the given writer and the logger name.
+func NewDatadogOutput(w io.Writer, loggerName string) *DatadogOutput {
+	o := &DatadogOutput{
+		json:       NewJSONOutput(w, 0),
+		loggerName: loggerName,
+	}
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *DatadogOutput) Log(log *Log) {
+	o.json.Log(log)
+}
+
+// preset returns the jsonPreset by the logger name, the service, the environment and the version.
+func (o *DatadogOutput) preset() *jsonPreset {
+	var static []jsonAttr
+	if o.loggerName != "" {
+		static = append(static, jsonAttr{"logger.name", o.loggerName})
+	}
+	id := func(v interface{}) interface{} {
+		return datadogID(v)
+	}
+	correlation := []jsonAttr{{"dd.service", o.service}, {"dd.env", o.env}, {"dd.version", o.version}}
+	return &jsonPreset{
+		head: func(log *Log) []jsonAttr {
+			attrs := []jsonAttr{
+				{"status", datadogStatus(log.Severity)},
+				{"message", string(log.Message)},
+				{"timestamp", log.Time.UTC().Format("2006-01-02T15:04:05.000Z")},
+			}
+			attrs = append(attrs, static...)
+			if log.StackCaller.Function != "" {
+				attrs = append(attrs, jsonAttr{"logger.method_name", log.StackCaller.Function})
+			}
+			if log.Error != nil {
+				attrs = append(attrs,
+					jsonAttr{"error.kind", fmt.Sprintf("%T", log.Error)},
+					jsonAttr{"error.message", log.Error.Error()})
+			}
+			if log.StackTrace != nil {
+				attrs = append(attrs, jsonAttr{"error.stack", fmt.Sprintf("%+.1s", log.StackTrace)})
+			}
+			for _, attr := range correlation {
+				if attr.value != "" {
+					attrs = append(attrs, attr)
+				}
+			}
+			return attrs
+		},
+		fieldKeys: map[string]jsonFieldKey{
+			FieldKeyTraceID: {"dd.trace_id", id},
+			FieldKeySpanID:  {"dd.span_id", id},
+		},
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *DatadogOutput) Flush() error {
+	return o.json.Flush()
+}
+
+// SetWriter sets writer.
+// It returns the underlying DatadogOutput.
+func (o *DatadogOutput) SetWriter(w io.Writer) *DatadogOutput {
+	o.json.SetWriter(w)
+	return o
+}
+
+// SetCorrelation sets the service, environment and version which are injected as dd.service, dd.env and dd.version.
+// The empty ones aren't injected.
+// It returns the underlying DatadogOutput.
+func (o *DatadogOutput) SetCorrelation(service, env, version string) *DatadogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.service = service
+	o.env = env
+	o.version = version
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying DatadogOutput.
+func (o *DatadogOutput) SetOnError(f func(error)) *DatadogOutput {
+	o.json.SetOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *DatadogOutput) Err() error {
+	return o.json.Err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *DatadogOutput) Healthy() bool {
+	return o.json.Healthy()
+}
+
+// datadogStatus returns the Datadog status by the given Severity.
+func datadogStatus(severity Severity) string {
+	switch severity {
+	case SeverityEmergency:
+		return "emergency"
+	case SeverityAlert:
+		return "alert"
+	case SeverityFatal, SeverityPanic, SeverityCritical:
+		return "critical"
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	case SeverityDebug:
+		return "debug"
+	default:
+		return "notice"
+	}
+}
+
+// datadogID returns the given trace or span ID in decimal.
+// Hex IDs are converted by their lower 64 bits, and other values are returned as they are.
+func datadogID(value interface{}) string {
+	s := fmt.Sprintf("%v", value)
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return s
+	}
+	h := s
+	if len(h) > 16 {
+		h = h[len(h)-16:]
+	}
+	if x, err := strconv.ParseUint(h, 16, 64); err == nil {
+		return strconv.FormatUint(x, 10)
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package logng
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ecsVersion is the version of Elastic Common Schema which ECSOutput complies with.
+const ecsVersion = "1.6.0"
+
+// ECSOutput is an implementation of Output by writing json to io.Writer w in Elastic Common Schema (ECS):
+//
+//	{"@timestamp":"...","log.level":"error","message":"...","ecs.version":"1.6.0","log.origin":{...},"error":{...},"labels":{...}}
+//
+// The caller is written as log.origin, Log.Error and Log.StackTrace as error, and fields as labels.
+// The values of the fields with the keys FieldKeyTraceID and FieldKeySpanID are written as trace.id and span.id.
+type ECSOutput struct {
+	mu          sync.Mutex
+	json        *JSONOutput
+	serviceName string
+}
+
+// NewECSOutput creates a new ECSOutput.
+func NewECSOutput(w io.Writer) *ECSOutput {
+	o := &ECSOutput{
+		json: NewJSONOutput(w, 0),
+	}
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *ECSOutput) Log(log *Log) {
+	o.json.Log(log)
+}
+
+// preset returns the jsonPreset by the service name.
+func (o *ECSOutput) preset() *jsonPreset {
+	serviceName := o.serviceName
+	id := func(v interface{}) interface{} {
+		return fmt.Sprintf("%v", v)
+	}
+	return &jsonPreset{
+		head: func(log *Log) []jsonAttr {
+			attrs := []jsonAttr{
+				{"@timestamp", log.Time.UTC().Format("2006-01-02T15:04:05.000Z")},
+				{"log.level", strings.ToLower(log.Severity.String())},
+				{"message", string(log.Message)},
+				{"ecs.version", ecsVersion},
+			}
+			if serviceName != "" {
+				attrs = append(attrs, jsonAttr{"service.name", serviceName})
+			}
+			if log.StackCaller.Function != "" {
+				var origin struct {
+					File struct {
+						Name string `json:"name"`
+						Line int    `json:"line"`
+					} `json:"file"`
+					Function string `json:"function"`
+				}
+				origin.File.Name = log.StackCaller.File
+				origin.File.Line = log.StackCaller.Line
+				origin.Function = log.StackCaller.Function
+				attrs = append(attrs, jsonAttr{"log.origin", &origin})
+			}
+			if log.Error != nil || log.StackTrace != nil {
+				var e struct {
+					Type       string `json:"type,omitempty"`
+					Message    string `json:"message,omitempty"`
+					StackTrace string `json:"stack_trace,omitempty"`
+				}
+				if log.Error != nil {
+					e.Type = fmt.Sprintf("%T", log.Error)
+					e.Message = log.Error.Error()
+				}
+				if log.StackTrace != nil {
+					e.StackTrace = fmt.Sprintf("%+.1s", log.StackTrace)
+				}
+				attrs = append(attrs, jsonAttr{"error", &e})
+			}
+			return attrs
+		},
+		fieldKeys: map[string]jsonFieldKey{
+			FieldKeyTraceID: {"trace.id", id},
+			FieldKeySpanID:  {"span.id", id},
+		},
+		fieldsKey: "labels",
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *ECSOutput) Flush() error {
+	return o.json.Flush()
+}
+
+// SetWriter sets writer.
+// It returns the underlying ECSOutput.
+func (o *ECSOutput) SetWriter(w io.Writer) *ECSOutput {
+	o.json.SetWriter(w)
+	return o
+}
+
+// SetServiceName sets the service name which is written as service.name. If serviceName is empty, it isn't written.
+// It returns the underlying ECSOutput.
+func (o *ECSOutput) SetServiceName(serviceName string) *ECSOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.serviceName = serviceName
+	o.json.setPreset(o.preset())
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying ECSOutput.
+func (o *ECSOutput) SetOnError(f func(error)) *ECSOutput {
+	o.json.SetOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *ECSOutput) Err() error {
+	return o.json.Err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *ECSOutput) Healthy() bool {
+	return o.json.Healthy()
+}
@@ -2,9 +2,37 @@ package logng
 
 import (
 	"errors"
+	"sync/atomic"
+	"unsafe"
 )
 
 var (
-	ErrInvalidSeverity = errors.New("invalid severity")
-	ErrUnknownSeverity = errors.New("unknown severity")
+	ErrInvalidSeverity  = errors.New("invalid severity")
+	ErrUnknownSeverity  = errors.New("unknown severity")
+	ErrClosed           = errors.New("closed")
+	ErrOutputPanic      = errors.New("output panic")
+	ErrOutputTimeout    = errors.New("output timeout")
+	ErrQueueFull        = errors.New("queue full")
+	ErrDropLog          = errors.New("drop log")
+	ErrRetriesExhausted = errors.New("retries exhausted")
+	ErrCircuitOpen      = errors.New("circuit open")
 )
+
+var (
+	errorHandler *func(error)
+)
+
+// SetErrorHandler sets a function to call when an internal error occurs, such as a panic recovered from an Output.
+// By default, internal errors are ignored.
+func SetErrorHandler(f func(error)) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&errorHandler)), unsafe.Pointer(&f))
+}
+
+// handleError calls the error handler with the given error, if it is set.
+func handleError(err error) {
+	f := (*func(error))(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&errorHandler))))
+	if f == nil || *f == nil {
+		return
+	}
+	(*f)(err)
+}
@@ -0,0 +1,153 @@
+package logng
+
+import (
+	"sync"
+	"time"
+)
+
+// HealthChecker is the interface that wraps the Healthy method.
+// The outputs which write to external destinations, e.g. HTTPOutput and SyslogOutput, implement it.
+type HealthChecker interface {
+	Healthy() bool
+}
+
+// FailoverOutput is an implementation of Output by passing the logs to the primary output, and failing over
+// to the next fallback output when the output fails. The output is considered as failed when its health function
+// returns false after the log. By default, the health function uses HealthChecker if the output implements it,
+// otherwise the output never fails.
+//
+// A failed output is skipped until the retry interval elapses, then it is retried with the next log.
+// So, FailoverOutput returns to the primary output when it recovers. The last output is never skipped.
+//
+// The outputs which deliver logs asynchronously, e.g. HTTPOutput, KafkaOutput and QueuedOutput, report the health
+// of their last delivery instead of the given log, which they keep queued. So, by the default health function,
+// a log after a failed delivery is also passed to the fallback output, and it is delivered twice if the output
+// recovers. Set a health function which suits the output to avoid duplicates, or use synchronous outputs.
+type FailoverOutput struct {
+	mu            sync.Mutex
+	outputs       []Output
+	failedAt      []time.Time
+	retryInterval time.Duration
+	healthFunc    func(Output) bool
+	onFailover    func(from, to Output)
+}
+
+// NewFailoverOutput creates a new FailoverOutput by the given primary and fallback outputs.
+// By default, the retry interval is 10 seconds.
+func NewFailoverOutput(primary Output, fallbacks ...Output) *FailoverOutput {
+	outputs := make([]Output, 0, 1+len(fallbacks))
+	outputs = append(outputs, primary)
+	outputs = append(outputs, fallbacks...)
+	return &FailoverOutput{
+		outputs:       outputs,
+		failedAt:      make([]time.Time, len(outputs)),
+		retryInterval: 10 * time.Second,
+		healthFunc:    defaultHealthFunc,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *FailoverOutput) Log(log *Log) {
+	now := time.Now()
+	o.mu.Lock()
+	outputs, healthFunc, onFailover := o.outputs, o.healthFunc, o.onFailover
+	skipped := make([]bool, len(outputs))
+	for i := range outputs[:len(outputs)-1] {
+		skipped[i] = !o.failedAt[i].IsZero() && now.Sub(o.failedAt[i]) < o.retryInterval
+	}
+	o.mu.Unlock()
+
+	var failed Output
+	for i, output := range outputs {
+		if skipped[i] {
+			continue
+		}
+		output.Log(log)
+		healthy := healthFunc(output)
+		o.mu.Lock()
+		if healthy {
+			o.failedAt[i] = time.Time{}
+		} else {
+			o.failedAt[i] = now
+		}
+		o.mu.Unlock()
+		if healthy {
+			if failed != nil && onFailover != nil {
+				onFailover(failed, output)
+			}
+			return
+		}
+		failed = output
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the primary and fallback outputs.
+func (o *FailoverOutput) Flush() error {
+	o.mu.Lock()
+	outputs := o.outputs
+	o.mu.Unlock()
+	return flushOutputs(outputs)
+}
+
+// Close is the implementation of io.Closer. It closes the primary and fallback outputs.
+func (o *FailoverOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return closeOutputs(o.outputs)
+}
+
+// Active returns the first output which isn't skipped as failed.
+func (o *FailoverOutput) Active() Output {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	last := len(o.outputs) - 1
+	for i, output := range o.outputs {
+		if i < last && !o.failedAt[i].IsZero() && now.Sub(o.failedAt[i]) < o.retryInterval {
+			continue
+		}
+		return output
+	}
+	return o.outputs[last]
+}
+
+// SetRetryInterval sets the interval to skip a failed output before retrying it.
+// It returns the underlying FailoverOutput.
+func (o *FailoverOutput) SetRetryInterval(retryInterval time.Duration) *FailoverOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retryInterval = retryInterval
+	return o
+}
+
+// SetHealthFunc sets the function which reports whether the output has logged the last log successfully.
+// If f is nil, the default health function is used.
+// It returns the underlying FailoverOutput.
+func (o *FailoverOutput) SetHealthFunc(f func(output Output) bool) *FailoverOutput {
+	if f == nil {
+		f = defaultHealthFunc
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.healthFunc = f
+	return o
+}
+
+// SetOnFailover sets a function to call when a log has been passed to another output, because the output failed.
+// It returns the underlying FailoverOutput.
+func (o *FailoverOutput) SetOnFailover(f func(from, to Output)) *FailoverOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onFailover = f
+	return o
+}
+
+// defaultHealthFunc reports whether the given output is healthy by HealthChecker.
+// It returns true if the output doesn't implement HealthChecker.
+// The health of the asynchronous outputs may be stale, as described in FailoverOutput.
+func defaultHealthFunc(output Output) bool {
+	if hc, ok := output.(HealthChecker); ok {
+		return hc.Healthy()
+	}
+	return true
+}
@@ -1,5 +1,10 @@
 package logng
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // Field is the type of field.
 type Field struct {
 	Key   string
@@ -20,3 +25,147 @@ func (f Fields) Clone() Fields {
 	}
 	return f2
 }
+
+// lazyValue is the value of the field created by Lazy.
+type lazyValue func() interface{}
+
+// Lazy creates a new Field whose value is evaluated by the given function, only when a log having the field
+// passes the filters of the Logger, e.g. severity and verbosity. So, expensive values aren't built for
+// the suppressed logs. The function is called once for each log, before the hooks and the output.
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, Value: lazyValue(fn)}
+}
+
+// FieldMarshaler is the interface that field values can implement to control their own structured representation.
+// The outputs render the value returned by MarshalField instead of the field value itself. For example,
+// a struct can hide its internal members by returning a map of the members to log.
+type FieldMarshaler interface {
+	MarshalField() interface{}
+}
+
+// maxFieldMarshalDepth is the maximum number of nested FieldMarshaler resolutions.
+const maxFieldMarshalDepth = 100
+
+// fieldValue returns the representation of the given field value, by resolving FieldMarshaler repeatedly.
+func fieldValue(v interface{}) interface{} {
+	for i := 0; i < maxFieldMarshalDepth; i++ {
+		m, ok := v.(FieldMarshaler)
+		if !ok {
+			return v
+		}
+		v = m.MarshalField()
+	}
+	return v
+}
+
+// resolveValues evaluates the values of the fields created by Lazy, and resolves FieldMarshaler values in place.
+// The groups are copied before resolving their fields.
+func (f Fields) resolveValues() {
+	for i := range f {
+		if fn, ok := f[i].Value.(lazyValue); ok {
+			if fn == nil {
+				f[i].Value = nil
+				continue
+			}
+			f[i].Value = fn()
+		}
+		if g, ok := f[i].Value.(GroupValue); ok {
+			g2 := make(GroupValue, len(g))
+			copy(g2, g)
+			Fields(g2).resolveValues()
+			f[i].Value = g2
+			continue
+		}
+		f[i].Value = fieldValue(f[i].Value)
+	}
+}
+
+// GroupValue is the value of the field created by Group. The json encoded outputs render it as a nested object,
+// and the text outputs render its fields with the group key prefix, e.g. "request.method".
+type GroupValue Fields
+
+// Group creates a new Field which groups the given fields under the given key.
+func Group(key string, fields ...Field) Field {
+	g := make(GroupValue, len(fields))
+	copy(g, fields)
+	return Field{Key: key, Value: g}
+}
+
+// MarshalJSON is the implementation of json.Marshaler.
+// It encodes the underlying GroupValue as a json object by keeping the order of the fields.
+func (g GroupValue) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 256))
+	buf.WriteRune('{')
+	for i, field := range g {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		b, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteRune(':')
+		b, err = json.Marshal(fieldValue(field.Value))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteRune('}')
+	return buf.Bytes(), nil
+}
+
+// Flatten returns the fields by expanding the groups into their fields, whose keys are prefixed by the group keys
+// and a dot. It returns the underlying Fields if it has no group.
+func (f Fields) Flatten() Fields {
+	hasGroup := false
+	for _, field := range f {
+		if _, ok := field.Value.(GroupValue); ok {
+			hasGroup = true
+			break
+		}
+	}
+	if !hasGroup {
+		return f
+	}
+	result := make(Fields, 0, len(f))
+	for _, field := range f {
+		g, ok := field.Value.(GroupValue)
+		if !ok {
+			result = append(result, field)
+			continue
+		}
+		for _, field2 := range Fields(g).Flatten() {
+			result = append(result, Field{Key: field.Key + "." + field2.Key, Value: field2.Value})
+		}
+	}
+	return result
+}
+
+// loggerGroup is a group of the Logger, which holds the fields starting from the index.
+type loggerGroup struct {
+	name  string
+	start int
+}
+
+// groupFields nests the given fields under the given groups of the Logger.
+func groupFields(fields Fields, groups []loggerGroup) Fields {
+	var inner Fields
+	end := len(fields)
+	for i := len(groups) - 1; i >= 0; i-- {
+		members := make(Fields, 0, end-groups[i].start+1)
+		members = append(members, fields[groups[i].start:end]...)
+		if len(inner) > 0 {
+			members = append(members, Field{Key: groups[i+1].name, Value: GroupValue(inner)})
+		}
+		inner = members
+		end = groups[i].start
+	}
+	result := make(Fields, 0, end+1)
+	result = append(result, fields[:end]...)
+	if len(inner) > 0 {
+		result = append(result, Field{Key: groups[0].name, Value: GroupValue(inner)})
+	}
+	return result
+}
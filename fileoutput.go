@@ -0,0 +1,466 @@
+package logng
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileOutputBackupTimeLayout is the time layout in the names of backup files.
+const fileOutputBackupTimeLayout = "2006-01-02T15-04-05.000"
+
+// FileOutput is an implementation of Output by writing texts to a file with rotation.
+// The file is rotated when its size exceeds the maximum size. Rotated files are renamed as backups
+// by adding the rotation time to their names, e.g. app-2009-01-23T01-23-23.123.log;
+// and they are removed by the maximum age and the maximum backup count. If a backup with the same rotation time exists,
+// a counter is added to the name, e.g. app-2009-01-23T01-23-23.123-1.log.
+//
+// FileOutput is also an io.Writer with rotation. So it can be used as the writer of other outputs such as JSONOutput.
+// In this case, the other output should be used as Logger's output instead of FileOutput.
+type FileOutput struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	size       int64
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	symlink    string
+	closed     bool
+	text       *TextOutput
+	millMu     sync.Mutex
+	millWg     sync.WaitGroup
+	sigCh      chan os.Signal
+	sigStopCh  chan struct{}
+	sigWg      sync.WaitGroup
+	errs       []error
+	health
+}
+
+// NewFileOutput creates a new FileOutput by the given file path and flags.
+// It creates the file if it doesn't exist, otherwise it appends to the file.
+func NewFileOutput(path string, flags TextOutputFlag) (*FileOutput, error) {
+	o := &FileOutput{
+		path: path,
+	}
+	o.text = NewTextOutput(o, flags).SetOnError(o.handleError)
+	o.mu.Lock()
+	defer o.unlock()
+	if err := o.open(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Log is the implementation of Output.
+func (o *FileOutput) Log(log *Log) {
+	o.text.Log(log)
+}
+
+// Write is the implementation of io.Writer.
+// It rotates the file before writing, if the size of the file would exceed the maximum size.
+// If the rotation fails, the error is passed to the OnError function and p is written to the current file.
+// If the file couldn't be reopened by a previous rotation or Reopen, it tries to open the file again.
+// It returns ErrClosed if the underlying FileOutput has been closed.
+func (o *FileOutput) Write(p []byte) (n int, err error) {
+	o.mu.Lock()
+	defer o.unlock()
+	if o.closed {
+		return 0, ErrClosed
+	}
+	if o.file == nil {
+		if err = o.open(); err != nil {
+			return 0, err
+		}
+	}
+	if o.maxSize > 0 && o.size > 0 && o.size+int64(len(p)) > o.maxSize {
+		o.addError(o.rotate())
+		if o.file == nil {
+			return 0, fmt.Errorf("unable to rotate file: %w", ErrClosed)
+		}
+	}
+	n, err = o.file.Write(p)
+	o.size += int64(n)
+	if err == nil {
+		o.setHealthy()
+	}
+	return n, err
+}
+
+// Close closes the file, stops reopening on signals and waits for the pending backup operations.
+func (o *FileOutput) Close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	err := o.closeFile()
+	o.stopSignal()
+	o.mu.Unlock()
+	o.sigWg.Wait()
+	o.millWg.Wait()
+	return err
+}
+
+// Reopen closes and reopens the file by its path.
+// It is useful when the file has been moved by an external tool such as logrotate.
+// If the file can't be reopened, the next write tries to open it again.
+func (o *FileOutput) Reopen() error {
+	o.mu.Lock()
+	defer o.unlock()
+	if o.closed {
+		return ErrClosed
+	}
+	err := o.closeFile()
+	if e := o.open(); e != nil {
+		return e
+	}
+	if err != nil {
+		return fmt.Errorf("unable to close file: %w", err)
+	}
+	return nil
+}
+
+// ReopenOnSignal starts reopening the file when any of the given signals is received, e.g. syscall.SIGHUP.
+// If no signal is given, it stops reopening on signals. Errors while reopening are passed to the OnError function.
+// It returns the underlying FileOutput.
+func (o *FileOutput) ReopenOnSignal(sigs ...os.Signal) *FileOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stopSignal()
+	if len(sigs) == 0 || o.closed {
+		return o
+	}
+	sigCh, sigStopCh := make(chan os.Signal, 1), make(chan struct{})
+	o.sigCh, o.sigStopCh = sigCh, sigStopCh
+	signal.Notify(sigCh, sigs...)
+	o.sigWg.Add(1)
+	go func() {
+		defer o.sigWg.Done()
+		for {
+			select {
+			case <-sigStopCh:
+				return
+			case <-sigCh:
+				if err := o.Reopen(); err != nil && err != ErrClosed {
+					o.handleError(err)
+				}
+			}
+		}
+	}()
+	return o
+}
+
+// Rotate rotates the file immediately.
+func (o *FileOutput) Rotate() error {
+	o.mu.Lock()
+	defer o.unlock()
+	if o.closed {
+		return ErrClosed
+	}
+	return o.rotate()
+}
+
+// SetFlags sets the flags of the text format.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetFlags(flags TextOutputFlag) *FileOutput {
+	o.text.SetFlags(flags)
+	return o
+}
+
+// SetMaxSize sets the maximum size of the file in bytes before it gets rotated.
+// If maxSize is less or equal than 0, the file is never rotated by its size.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetMaxSize(maxSize int64) *FileOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxSize = maxSize
+	return o
+}
+
+// SetMaxAge sets the maximum age of backups to retain, by their rotation time.
+// If maxAge is less or equal than 0, backups are never removed by their ages.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetMaxAge(maxAge time.Duration) *FileOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxAge = maxAge
+	return o
+}
+
+// SetMaxBackups sets the maximum number of backups to retain.
+// If maxBackups is less or equal than 0, backups are never removed by their count.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetMaxBackups(maxBackups int) *FileOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxBackups = maxBackups
+	return o
+}
+
+// SetCompress sets whether backups are compressed by gzip.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetCompress(compress bool) *FileOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.compress = compress
+	return o
+}
+
+// SetSymlink sets the path of a symbolic link which is pointed to the current file.
+// The symbolic link is created or replaced whenever the file is opened. If symlink is empty, no link is created.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetSymlink(symlink string) *FileOutput {
+	o.mu.Lock()
+	defer o.unlock()
+	o.symlink = symlink
+	if symlink != "" && !o.closed {
+		o.addError(o.link())
+	}
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying FileOutput.
+func (o *FileOutput) SetOnError(f func(error)) *FileOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, rotating or reopening, or nil if no error has occurred
+// yet.
+func (o *FileOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last write has succeeded, and no error has occurred after it.
+// It returns true if nothing has been written yet.
+func (o *FileOutput) Healthy() bool {
+	return o.healthy()
+}
+
+func (o *FileOutput) stopSignal() {
+	if o.sigCh == nil {
+		return
+	}
+	signal.Stop(o.sigCh)
+	close(o.sigStopCh)
+	o.sigCh, o.sigStopCh = nil, nil
+}
+
+// closeFile closes the current file, if any. The file is released even if closing fails.
+func (o *FileOutput) closeFile() error {
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+func (o *FileOutput) open() error {
+	file, err := os.OpenFile(o.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %w", err)
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("unable to stat file: %w", err)
+	}
+	o.file = file
+	o.size = fi.Size()
+	if o.symlink != "" {
+		o.addError(o.link())
+	}
+	return nil
+}
+
+func (o *FileOutput) link() error {
+	target, err := filepath.Abs(o.path)
+	if err != nil {
+		return fmt.Errorf("unable to get absolute path: %w", err)
+	}
+	tmp := o.symlink + ".tmp"
+	_ = os.Remove(tmp)
+	if err = os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("unable to create symlink: %w", err)
+	}
+	if err = os.Rename(tmp, o.symlink); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("unable to rename symlink: %w", err)
+	}
+	return nil
+}
+
+// rotate renames the current file as a backup and opens a new file. If renaming fails, it reopens the current file.
+// So, the later writes don't fail because of the closed file. If opening fails, the next write tries again.
+func (o *FileOutput) rotate() error {
+	if err := o.closeFile(); err != nil {
+		o.addError(o.open())
+		return fmt.Errorf("unable to close file: %w", err)
+	}
+	if err := os.Rename(o.path, o.backupName(time.Now())); err != nil {
+		o.addError(o.open())
+		return fmt.Errorf("unable to rename file: %w", err)
+	}
+	if err := o.open(); err != nil {
+		return err
+	}
+	maxAge, maxBackups, compress := o.maxAge, o.maxBackups, o.compress
+	o.millWg.Add(1)
+	go func() {
+		defer o.millWg.Done()
+		o.millMu.Lock()
+		defer o.millMu.Unlock()
+		if err := o.mill(maxAge, maxBackups, compress); err != nil {
+			o.handleError(err)
+		}
+	}()
+	return nil
+}
+
+// backupName returns the name of a new backup by the given rotation time. If a backup with the same rotation time
+// exists, even compressed, a counter is added to the name.
+func (o *FileOutput) backupName(tm time.Time) string {
+	ext := filepath.Ext(o.path)
+	prefix := fmt.Sprintf("%s-%s", strings.TrimSuffix(o.path, ext), tm.Format(fileOutputBackupTimeLayout))
+	exists := func(name string) bool {
+		_, err := os.Lstat(name)
+		return err == nil
+	}
+	name := prefix + ext
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%d%s", prefix, i, ext)
+	}
+	return name
+}
+
+// mill compresses and removes backups.
+func (o *FileOutput) mill(maxAge time.Duration, maxBackups int, compress bool) error {
+	type backup struct {
+		name    string
+		tm      time.Time
+		counter int
+	}
+	dir := filepath.Dir(o.path)
+	base := filepath.Base(o.path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read directory: %w", err)
+	}
+	backups := make([]backup, 0, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		s := strings.TrimPrefix(name, prefix)
+		s = strings.TrimSuffix(s, ".gz")
+		if !strings.HasSuffix(s, ext) {
+			continue
+		}
+		s = strings.TrimSuffix(s, ext)
+		var counter int
+		if n := len(fileOutputBackupTimeLayout); len(s) > n+1 && s[n] == '-' {
+			c, e := strconv.Atoi(s[n+1:])
+			if e != nil {
+				continue
+			}
+			s, counter = s[:n], c
+		}
+		tm, e := time.ParseInLocation(fileOutputBackupTimeLayout, s, time.Local)
+		if e != nil {
+			continue
+		}
+		backups = append(backups, backup{name: filepath.Join(dir, name), tm: tm, counter: counter})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].tm.Equal(backups[j].tm) {
+			return backups[i].counter > backups[j].counter
+		}
+		return backups[i].tm.After(backups[j].tm)
+	})
+
+	now := time.Now()
+	for i, b := range backups {
+		if (maxBackups > 0 && i >= maxBackups) || (maxAge > 0 && now.Sub(b.tm) > maxAge) {
+			if e := os.Remove(b.name); e != nil && err == nil {
+				err = fmt.Errorf("unable to remove backup: %w", e)
+			}
+			continue
+		}
+		if compress && !strings.HasSuffix(b.name, ".gz") {
+			if e := compressFile(b.name); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// addError adds the given error to be passed to the OnError function by unlock, if it is not nil.
+// It must be called while holding the lock.
+func (o *FileOutput) addError(err error) {
+	if err != nil {
+		o.errs = append(o.errs, err)
+	}
+}
+
+// unlock unlocks the lock, and then passes the errors added while holding the lock to the OnError function.
+// So, the OnError function can log through the FileOutput.
+func (o *FileOutput) unlock() {
+	errs := o.errs
+	o.errs = nil
+	o.mu.Unlock()
+	for _, err := range errs {
+		o.handleError(err)
+	}
+}
+
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("unable to open backup: %w", err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to create compressed backup: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return fmt.Errorf("unable to compress backup: %w", err)
+	}
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("unable to compress backup: %w", err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("unable to close compressed backup: %w", err)
+	}
+	_ = src.Close()
+	if err = os.Remove(name); err != nil {
+		return fmt.Errorf("unable to remove backup: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package logng
+
+// FilterOutput is an implementation of Output by passing only the logs which the predicate function
+// returns true for, to the given output. So, each destination can have its own filter,
+// e.g. by field, message pattern or verbosity, without building a custom output.
+type FilterOutput struct {
+	output Output
+	fn     func(log *Log) bool
+}
+
+// NewFilterOutput creates a new FilterOutput by the given output and predicate function.
+// The function must not modify the log. If fn is nil, all logs are passed.
+func NewFilterOutput(output Output, fn func(log *Log) bool) *FilterOutput {
+	return &FilterOutput{
+		output: output,
+		fn:     fn,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *FilterOutput) Log(log *Log) {
+	if o.fn != nil && !o.fn(log) {
+		return
+	}
+	o.output.Log(log)
+}
+
+// Flush is the implementation of Flusher. It flushes the output.
+func (o *FilterOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+// Close is the implementation of io.Closer. It closes the output.
+func (o *FilterOutput) Close() error {
+	return closeOutput(o.output)
+}
@@ -0,0 +1,106 @@
+package logng
+
+import (
+	"flag"
+	"strings"
+)
+
+// RegisterFlags registers the flags -log-level, -v and -vmodule into the given flag set, which set the default Logger's
+// severity, verbose and per-module verbose. If fs is nil, flag.CommandLine is used.
+// It panics if any of the flags has been already registered, e.g. by another logging package.
+func RegisterFlags(fs *flag.FlagSet) {
+	defaultLogger.RegisterFlags(fs)
+}
+
+// RegisterFlags registers the flags -log-level, -v and -vmodule into the given flag set, which set the underlying Logger's
+// severity, verbose and per-module verbose. If fs is nil, flag.CommandLine is used.
+// It panics if any of the flags has been already registered, e.g. by another logging package.
+func (l *Logger) RegisterFlags(fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Var(&loggerSeverityFlag{l}, "log-level", severityFlagUsage())
+	fs.Var(&loggerVerboseFlag{l}, "v", "log verbose level")
+	fs.Var(&loggerVModuleFlag{l}, "vmodule", "comma-separated list of pattern=level for per-module verbose")
+}
+
+// severityFlagUsage returns the usage of the severity flag, which lists the severity names with their aliases.
+func severityFlagUsage() string {
+	var sb strings.Builder
+	sb.WriteString("log severity: ")
+	for i, x := range severityNames {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(x.names[0])
+		if len(x.names) > 1 {
+			sb.WriteString(" (" + strings.Join(x.names[1:], ", ") + ")")
+		}
+	}
+	sb.WriteString(", or its numeric value")
+	return sb.String()
+}
+
+// loggerSeverityFlag is an implementation of flag.Value which sets the severity of the Logger.
+type loggerSeverityFlag struct {
+	l *Logger
+}
+
+func (f *loggerSeverityFlag) String() string {
+	if f.l == nil {
+		return ""
+	}
+	f.l.mu.RLock()
+	defer f.l.mu.RUnlock()
+	return f.l.severity.String()
+}
+
+func (f *loggerSeverityFlag) Set(value string) error {
+	var severity Severity
+	if err := severity.Set(value); err != nil {
+		return err
+	}
+	f.l.SetSeverity(severity)
+	return nil
+}
+
+// loggerVerboseFlag is an implementation of flag.Value which sets the verbose of the Logger.
+type loggerVerboseFlag struct {
+	l *Logger
+}
+
+func (f *loggerVerboseFlag) String() string {
+	if f.l == nil {
+		return ""
+	}
+	f.l.mu.RLock()
+	defer f.l.mu.RUnlock()
+	return f.l.verbose.String()
+}
+
+func (f *loggerVerboseFlag) Set(value string) error {
+	var verbose Verbose
+	if err := verbose.Set(value); err != nil {
+		return err
+	}
+	f.l.SetVerbose(verbose)
+	return nil
+}
+
+// loggerVModuleFlag is an implementation of flag.Value which sets the per-module verbose of the Logger.
+type loggerVModuleFlag struct {
+	l *Logger
+}
+
+func (f *loggerVModuleFlag) String() string {
+	if f.l == nil {
+		return ""
+	}
+	f.l.mu.RLock()
+	defer f.l.mu.RUnlock()
+	return f.l.vmodule.String()
+}
+
+func (f *loggerVModuleFlag) Set(value string) error {
+	return f.l.SetVModule(value)
+}
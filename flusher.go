@@ -0,0 +1,97 @@
+package logng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Flusher is an optional interface for Output and io.Writer implementations which buffer data.
+// Flush writes any buffered data to the underlying destination.
+type Flusher interface {
+	Flush() error
+}
+
+// flushOutput flushes the given output. QueuedOutput is flushed by QueuedOutput.Flush without timeout,
+// and the other outputs are flushed if they implement Flusher.
+func flushOutput(output Output) error {
+	switch o := output.(type) {
+	case nil:
+		return nil
+	case *QueuedOutput:
+		return o.Flush(context.Background())
+	case Flusher:
+		return o.Flush()
+	}
+	return nil
+}
+
+// flushOutputs flushes the given outputs by flushOutput, and returns the first error.
+func flushOutputs(outputs []Output) (err error) {
+	for _, output := range outputs {
+		if e := flushOutput(output); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// flushWriter flushes the given writer if it implements Flusher.
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// syncTimeout calls the given sync function, and waits for it up to the given timeout.
+// If the timeout elapses, it returns an error wrapping ErrOutputTimeout, and sync keeps running in background.
+// If timeout is less or equal than 0, it waits until sync returns.
+func syncTimeout(sync func() error, timeout time.Duration) error {
+	if timeout <= 0 {
+		return sync()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- sync()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("unable to flush in %v: %w", timeout, ErrOutputTimeout)
+	}
+}
+
+// fatalSync flushes the default Logger's output tree and the registered outputs by Sync before exiting by
+// the Fatal functions. It gives up waiting after the fatal flush timeout of the default Logger.
+func fatalSync() error {
+	defaultLogger.mu.RLock()
+	timeout := defaultLogger.fatalFlushTimeout
+	defaultLogger.mu.RUnlock()
+	return syncTimeout(Sync, timeout)
+}
+
+// Sync flushes the default Logger's output tree and the outputs registered by RegisterOutput, and returns
+// the first error. See Logger.Sync.
+func Sync() error {
+	defaultLogger.mu.RLock()
+	output := defaultLogger.output
+	defaultLogger.mu.RUnlock()
+	return flushOutputs(registeredOutputs(output))
+}
+
+// Sync flushes the underlying Logger's output tree, including the outputs of MultiOutput and QueuedOutput.
+// It should be called before the program exits, e.g. in shutdown paths and crash handlers.
+func (l *Logger) Sync() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	output := l.output
+	l.mu.RUnlock()
+	return flushOutput(output)
+}
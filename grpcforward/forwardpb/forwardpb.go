@@ -0,0 +1,4 @@
+// Package forwardpb contains the protobuf messages and the gRPC service of log forwarding.
+package forwardpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative forward.proto
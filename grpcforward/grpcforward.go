@@ -0,0 +1,331 @@
+// Package grpcforward provides log forwarding between logng-based processes over gRPC.
+//
+// Output streams logs to a collector which serves the LogService defined in forwardpb/forward.proto,
+// and Server is a reference implementation of the LogService which re-emits received logs into a logng.Output.
+package grpcforward
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/grpcforward/forwardpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Output is an implementation of logng.Output by streaming logs to a gRPC collector.
+//
+// Logs are queued and sent over a single client stream by a background goroutine.
+// If sending fails, the stream is reopened after the retry interval and the log is sent again.
+// When the queue is full, logs are dropped with logng.ErrQueueFull.
+type Output struct {
+	mu            sync.RWMutex
+	client        forwardpb.LogServiceClient
+	retryInterval time.Duration
+	queue         chan *forwardpb.Log
+	flushMu       sync.Mutex
+	flushCond     *sync.Cond
+	pending       int
+	sendErr       error
+	stopped       bool
+	stopCh        chan struct{}
+	wg            sync.WaitGroup
+	onError       *func(error)
+	lastErr       *error
+	unhealthy     uint32
+}
+
+// NewOutput creates a new Output by the given client connection and the queue size.
+// By default, the retry interval is 1 second.
+func NewOutput(conn grpc.ClientConnInterface, queueSize int) *Output {
+	o := &Output{
+		client:        forwardpb.NewLogServiceClient(conn),
+		retryInterval: time.Second,
+		queue:         make(chan *forwardpb.Log, queueSize),
+		stopCh:        make(chan struct{}),
+	}
+	o.flushCond = sync.NewCond(&o.flushMu)
+	o.wg.Add(1)
+	go o.worker()
+	return o
+}
+
+// Log is the implementation of logng.Output.
+func (o *Output) Log(log *logng.Log) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.stopped {
+		o.handleError(logng.ErrClosed)
+		return
+	}
+
+	o.flushMu.Lock()
+	o.pending++
+	o.flushMu.Unlock()
+	select {
+	case o.queue <- LogToProto(log):
+	default:
+		o.done(nil)
+		o.handleError(fmt.Errorf("unable to send log: %w", logng.ErrQueueFull))
+	}
+}
+
+// Flush is the implementation of logng.Flusher. It waits until the queued logs have been sent.
+// If sending fails, it returns the error without waiting for the retries.
+func (o *Output) Flush() error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+	for o.pending > 0 {
+		if o.sendErr != nil {
+			return o.sendErr
+		}
+		o.flushCond.Wait()
+	}
+	return nil
+}
+
+// Close sends the queued logs, closes the stream and stops the background goroutine.
+// The queued logs which can't be sent are dropped without retrying.
+// Logs after closing are dropped with logng.ErrClosed.
+func (o *Output) Close() error {
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		return nil
+	}
+	o.stopped = true
+	close(o.queue)
+	close(o.stopCh)
+	o.mu.Unlock()
+	o.wg.Wait()
+	return nil
+}
+
+func (o *Output) worker() {
+	defer o.wg.Done()
+
+	var stream forwardpb.LogService_StreamClient
+	defer func() {
+		if stream != nil {
+			if _, err := stream.CloseAndRecv(); err != nil {
+				o.handleError(fmt.Errorf("unable to close stream: %w", err))
+			}
+		}
+	}()
+
+	for msg := range o.queue {
+		for {
+			var err error
+			if stream == nil {
+				stream, err = o.client.Stream(context.Background())
+				if err != nil {
+					stream = nil
+					err = fmt.Errorf("unable to open stream: %w", err)
+				}
+			}
+			if stream != nil {
+				if err = stream.Send(msg); err != nil {
+					if errors.Is(err, io.EOF) {
+						_, err = stream.CloseAndRecv()
+					}
+					stream = nil
+					err = fmt.Errorf("unable to send log: %w", err)
+				}
+			}
+			if err == nil {
+				atomic.StoreUint32(&o.unhealthy, 0)
+				break
+			}
+			o.handleError(err)
+			o.flushMu.Lock()
+			o.sendErr = err
+			o.flushCond.Broadcast()
+			o.flushMu.Unlock()
+			o.mu.RLock()
+			retryInterval := o.retryInterval
+			o.mu.RUnlock()
+			timer := time.NewTimer(retryInterval)
+			select {
+			case <-o.stopCh:
+				timer.Stop()
+			case <-timer.C:
+				continue
+			}
+			break
+		}
+		o.done(msg)
+	}
+}
+
+// done marks the given queued log as sent or dropped, and wakes up the waiters of Flush.
+// msg is nil for the logs which haven't been queued.
+func (o *Output) done(msg *forwardpb.Log) {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+	o.pending--
+	if msg != nil && atomic.LoadUint32(&o.unhealthy) == 0 {
+		o.sendErr = nil
+	}
+	o.flushCond.Broadcast()
+}
+
+func (o *Output) handleError(err error) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.lastErr)), unsafe.Pointer(&err))
+	atomic.StoreUint32(&o.unhealthy, 1)
+	onError := o.onError
+	if onError == nil || *onError == nil {
+		return
+	}
+	(*onError)(err)
+}
+
+// SetRetryInterval sets the duration to wait before reopening the stream after an error.
+// It returns the underlying Output.
+func (o *Output) SetRetryInterval(retryInterval time.Duration) *Output {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retryInterval = retryInterval
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying Output.
+func (o *Output) SetOnError(f func(error)) *Output {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onError)), unsafe.Pointer(&f))
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *Output) Err() error {
+	p := (*error)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&o.lastErr))))
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// Healthy reports whether the last log has been sent successfully.
+// It returns true if no log has been sent yet.
+func (o *Output) Healthy() bool {
+	return atomic.LoadUint32(&o.unhealthy) == 0
+}
+
+// Server is a reference implementation of forwardpb.LogServiceServer which re-emits received logs into an output.
+type Server struct {
+	forwardpb.UnimplementedLogServiceServer
+	output logng.Output
+}
+
+// NewServer creates a new Server by the given output.
+func NewServer(output logng.Output) *Server {
+	return &Server{
+		output: output,
+	}
+}
+
+// Register registers the underlying Server to the given gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	forwardpb.RegisterLogServiceServer(registrar, s)
+}
+
+// Stream is the implementation of forwardpb.LogServiceServer.
+func (s *Server) Stream(stream forwardpb.LogService_StreamServer) error {
+	var received uint64
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&forwardpb.StreamResponse{Received: received})
+		}
+		if err != nil {
+			return err
+		}
+		s.output.Log(LogFromProto(msg))
+		received++
+	}
+}
+
+// LogToProto converts the given logng.Log to forwardpb.Log.
+// Field values are encoded in json, and the stack trace is formatted as text.
+func LogToProto(log *logng.Log) *forwardpb.Log {
+	msg := &forwardpb.Log{
+		Message:   string(log.Message),
+		Severity:  int32(log.Severity),
+		Verbosity: int32(log.Verbosity),
+		Time:      timestamppb.New(log.Time),
+		Fields:    make([]*forwardpb.Field, 0, len(log.Fields)),
+	}
+	if log.Error != nil {
+		msg.Error = log.Error.Error()
+	}
+	for _, field := range log.Fields {
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprintf("%v", field.Value))
+		}
+		msg.Fields = append(msg.Fields, &forwardpb.Field{
+			Key:       field.Key,
+			ValueJson: value,
+		})
+	}
+	if log.StackCaller.Function != "" {
+		msg.StackCaller = &forwardpb.StackCaller{
+			Function: log.StackCaller.Function,
+			File:     log.StackCaller.File,
+			Line:     int32(log.StackCaller.Line),
+		}
+	}
+	if log.StackTrace != nil {
+		msg.StackTrace = fmt.Sprintf("%+.1s", log.StackTrace)
+	}
+	return msg
+}
+
+// LogFromProto converts the given forwardpb.Log to logng.Log.
+// The error is restored as a plain error by its text, and field values are decoded from json.
+// Because logng.StackTrace can't be restored in another process, the stack trace is added as the field "stack_trace".
+func LogFromProto(msg *forwardpb.Log) *logng.Log {
+	log := &logng.Log{
+		Message:   []byte(msg.GetMessage()),
+		Severity:  logng.Severity(msg.GetSeverity()),
+		Verbosity: logng.Verbose(msg.GetVerbosity()),
+		Time:      msg.GetTime().AsTime(),
+		Fields:    make(logng.Fields, 0, len(msg.GetFields())+1),
+	}
+	if msg.GetError() != "" {
+		log.Error = errors.New(msg.GetError())
+	}
+	for _, field := range msg.GetFields() {
+		var value interface{}
+		dec := json.NewDecoder(bytes.NewReader(field.GetValueJson()))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			value = string(field.GetValueJson())
+		}
+		log.Fields = append(log.Fields, logng.Field{
+			Key:   field.GetKey(),
+			Value: value,
+		})
+	}
+	if caller := msg.GetStackCaller(); caller != nil {
+		log.StackCaller.Function = caller.GetFunction()
+		log.StackCaller.File = caller.GetFile()
+		log.StackCaller.Line = int(caller.GetLine())
+	}
+	if msg.GetStackTrace() != "" {
+		log.Fields = append(log.Fields, logng.Field{
+			Key:   "stack_trace",
+			Value: msg.GetStackTrace(),
+		})
+	}
+	return log
+}
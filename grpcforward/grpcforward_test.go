@@ -0,0 +1,94 @@
+package grpcforward_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"text/template"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/grpcforward"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func ExampleServer() {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	output := logng.NewTemplateOutput(os.Stdout, template.Must(template.New("log").Parse(
+		`{{.Severity}} - {{.StackCaller.Function}} - {{.Message}}{{range .Fields}} {{.Key}}={{.Value}}{{end}}`)))
+	grpcforward.NewServer(output).Register(server)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	forward := grpcforward.NewOutput(conn, 100)
+	logger := logng.NewLogger(forward, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", "john", "attempt", 3).Warning("login failed")
+	logger.Info("this is info log.")
+	_ = forward.Close()
+
+	// Output:
+	// WARNING - github.com/goinsane/logng/v2/grpcforward_test.ExampleServer - login failed user=john attempt=3
+	// INFO - github.com/goinsane/logng/v2/grpcforward_test.ExampleServer - this is info log.
+}
+
+func ExampleOutput() {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcforward.NewServer(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity)).Register(server)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	forward := grpcforward.NewOutput(conn, 100).SetOnError(func(err error) {
+		fmt.Println("error:", err)
+	})
+	logger := logng.NewLogger(forward, logng.SeverityInfo, 0)
+	logger.Info("first log.")
+	logger.Info("second log.")
+	// Flush waits until the queued logs have been sent to the stream.
+	flushErr := logger.Sync()
+	// Close waits until the collector has received the logs.
+	_ = forward.Close()
+	fmt.Println("flushed:", flushErr, forward.Healthy())
+	logger.Info("it won't be sent.")
+
+	// Output:
+	// INFO - first log.
+	// INFO - second log.
+	// flushed: <nil> true
+	// error: closed
+}
+
+func ExampleLogFromProto() {
+	msg := grpcforward.LogToProto(&logng.Log{Message: []byte("disk almost full"), Severity: logng.SeverityCritical})
+	fmt.Println(msg.GetSeverity(), grpcforward.LogFromProto(msg).Severity)
+
+	// Output:
+	// 8 CRITICAL
+}
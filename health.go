@@ -0,0 +1,60 @@
+package logng
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// health keeps the last error and the health of an output, and calls the function set by setOnError when error occurs.
+// It is embedded into the outputs which implement SetOnError, Err and Healthy, e.g. JSONOutput.
+// Its methods are safe for concurrent use.
+type health struct {
+	onError   *func(error)
+	lastErr   *error
+	unhealthy uint32
+}
+
+// setOnError sets a function to call when error occurs.
+func (h *health) setOnError(f func(error)) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&h.onError)), unsafe.Pointer(&f))
+}
+
+// err returns the most recent error, or nil if no error has occurred yet.
+func (h *health) err() error {
+	p := (*error)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&h.lastErr))))
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// healthy reports whether the last operation has succeeded. It returns true if no operation has been done yet.
+func (h *health) healthy() bool {
+	return atomic.LoadUint32(&h.unhealthy) == 0
+}
+
+// setHealthy marks the output healthy after a successful operation.
+func (h *health) setHealthy() {
+	atomic.StoreUint32(&h.unhealthy, 0)
+}
+
+// handleError marks the output unhealthy by the given error, and calls the function set by setOnError.
+// It must not be called while holding the locks which the function may need, e.g. when it logs through the output.
+func (h *health) handleError(err error) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&h.lastErr)), unsafe.Pointer(&err))
+	atomic.StoreUint32(&h.unhealthy, 1)
+	onError := (*func(error))(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&h.onError))))
+	if onError == nil || *onError == nil {
+		return
+	}
+	(*onError)(err)
+}
+
+// report marks the output healthy if the given error is nil, otherwise it handles the error by handleError.
+func (h *health) report(err error) {
+	if err == nil {
+		h.setHealthy()
+		return
+	}
+	h.handleError(err)
+}
@@ -0,0 +1,73 @@
+package logng
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Hook is a function which is called with the log before the output. It may modify the log, e.g. by adding fields.
+// If it returns ErrDropLog or an error wrapping ErrDropLog, the log is dropped.
+// The other errors are passed to the error handler, and the log is still passed to the output.
+type Hook func(log *Log) error
+
+// loggerHook is a Hook of the Logger with its severity filter.
+type loggerHook struct {
+	hook       Hook
+	severities []Severity
+}
+
+// AddHook adds the given hook to the default Logger. See Logger.AddHook.
+func AddHook(hook Hook, severities ...Severity) *Logger {
+	return defaultLogger.AddHook(hook, severities...)
+}
+
+// AddHook adds the given hook to the underlying Logger. The hooks are called in the order of adding,
+// only for the logs which have one of the given severities. If no severity is given, the hook is called for all logs.
+// The loggers which have been cloned from the underlying Logger before aren't affected.
+// It returns the underlying Logger.
+func (l *Logger) AddHook(hook Hook, severities ...Severity) *Logger {
+	if l == nil {
+		return nil
+	}
+	h := loggerHook{
+		hook:       hook,
+		severities: make([]Severity, len(severities)),
+	}
+	copy(h.severities, severities)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	hooks := make([]loggerHook, 0, len(l.hooks)+1)
+	hooks = append(hooks, l.hooks...)
+	l.hooks = append(hooks, h)
+	return l
+}
+
+// runHooks calls the hooks with the given log, and reports whether the log is kept.
+// It must be called with l.mu held.
+func (l *Logger) runHooks(log *Log) bool {
+	for _, h := range l.hooks {
+		if !h.match(log.Severity) {
+			continue
+		}
+		if err := h.hook(log); err != nil {
+			if errors.Is(err, ErrDropLog) {
+				return false
+			}
+			handleError(fmt.Errorf("unable to run hook: %w", err))
+		}
+	}
+	return true
+}
+
+// match reports whether the hook is called for the given severity.
+func (h loggerHook) match(severity Severity) bool {
+	if len(h.severities) == 0 {
+		return true
+	}
+	for _, s := range h.severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
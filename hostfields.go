@@ -0,0 +1,32 @@
+package logng
+
+import (
+	"os"
+)
+
+// HostFields returns the fields "hostname" and "pid" of the current process, and "app" and "version" with
+// the given values. The empty app or version is omitted, so is the hostname if it can't be determined.
+func HostFields(app, version string) Fields {
+	fields := make(Fields, 0, 4)
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		fields = append(fields, Field{Key: "hostname", Value: hostname})
+	}
+	fields = append(fields, Field{Key: "pid", Value: os.Getpid()})
+	if app != "" {
+		fields = append(fields, Field{Key: "app", Value: app})
+	}
+	if version != "" {
+		fields = append(fields, Field{Key: "version", Value: version})
+	}
+	return fields
+}
+
+// WithHostFields clones the default Logger with the host fields. See HostFields.
+func WithHostFields(app, version string) *Logger {
+	return defaultLogger.WithHostFields(app, version)
+}
+
+// WithHostFields clones the underlying Logger with the host fields. See HostFields.
+func (l *Logger) WithHostFields(app, version string) *Logger {
+	return l.WithFields(HostFields(app, version)...)
+}
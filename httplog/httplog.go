@@ -0,0 +1,179 @@
+// Package httplog provides net/http request logging by logng.
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// Handler is an implementation of http.Handler which logs every request served by the next handler.
+// The request logs have the fields method, path, proto, remote address, referer, user agent, status, bytes and duration
+// by the keys logng.FieldKeyHTTP*. So, they can be written by logng.AccessLogOutput.
+// If the connection is hijacked, e.g. for WebSocket, the status is 101 unless the next handler writes another status
+// before hijacking; and the bytes aren't logged, because they are written to the connection.
+type Handler struct {
+	mu         sync.RWMutex
+	next       http.Handler
+	logger     *logng.Logger
+	severities [6]logng.Severity
+}
+
+// NewHandler creates a new Handler by the given next handler and logger.
+// By default, the requests are logged by logng.SeverityInfo; except the status classes 4xx by logng.SeverityWarning
+// and 5xx by logng.SeverityError.
+func NewHandler(next http.Handler, logger *logng.Logger) *Handler {
+	return &Handler{
+		next:   next,
+		logger: logger,
+		severities: [6]logng.Severity{
+			logng.SeverityInfo,
+			logng.SeverityInfo,
+			logng.SeverityInfo,
+			logng.SeverityInfo,
+			logng.SeverityWarning,
+			logng.SeverityError,
+		},
+	}
+}
+
+// Middleware returns a middleware which wraps the next handler by Handler with the given logger.
+func Middleware(logger *logng.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return NewHandler(next, logger)
+	}
+}
+
+// ServeHTTP is the implementation of http.Handler.
+// The request context carries the per-request logger, which can be got by FromContext.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	logger := h.logger.WithFieldKeyVals(
+		logng.FieldKeyHTTPMethod, r.Method,
+		logng.FieldKeyHTTPPath, r.URL.RequestURI(),
+		logng.FieldKeyHTTPProto, r.Proto,
+		logng.FieldKeyHTTPRemoteAddr, r.RemoteAddr,
+		logng.FieldKeyHTTPReferer, r.Referer(),
+		logng.FieldKeyHTTPUserAgent, r.UserAgent(),
+	)
+	rw := &responseWriter{
+		ResponseWriter: w,
+	}
+	h.next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), logger)))
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+		if rw.hijacked {
+			rw.status = http.StatusSwitchingProtocols
+		}
+	}
+	keyvals := []interface{}{logng.FieldKeyHTTPStatus, rw.status}
+	if !rw.hijacked {
+		keyvals = append(keyvals, logng.FieldKeyHTTPBytes, rw.bytes)
+	}
+	keyvals = append(keyvals, logng.FieldKeyHTTPDuration, time.Since(start))
+	logger = logger.WithFieldKeyVals(keyvals...)
+	logf(logger, h.severity(rw.status), "%s %s %d", r.Method, r.URL.RequestURI(), rw.status)
+}
+
+// SetSeverity sets the severity to log the requests by the given status class, e.g. 4 for 4xx.
+// It returns the underlying Handler.
+func (h *Handler) SetSeverity(statusClass int, severity logng.Severity) *Handler {
+	if statusClass < 1 || statusClass > 5 {
+		return h
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.severities[statusClass] = severity
+	return h
+}
+
+func (h *Handler) severity(status int) logng.Severity {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	statusClass := status / 100
+	if statusClass < 1 || statusClass > 5 {
+		return h.severities[0]
+	}
+	return h.severities[statusClass]
+}
+
+// NewContext returns a new context by the given parent context which carries the given logger.
+// It is synonym with logng.NewContext.
+func NewContext(ctx context.Context, logger *logng.Logger) context.Context {
+	return logng.NewContext(ctx, logger)
+}
+
+// FromContext returns the logger carried by the given context.
+// If the context doesn't carry a logger, it returns the default Logger.
+// It is synonym with logng.FromContext.
+func FromContext(ctx context.Context) *logng.Logger {
+	return logng.FromContext(ctx)
+}
+
+func logf(logger *logng.Logger, severity logng.Severity, format string, args ...interface{}) {
+	switch severity {
+	case logng.SeverityEmergency, logng.SeverityAlert, logng.SeverityFatal, logng.SeverityPanic, logng.SeverityCritical:
+		logger.Criticalf(format, args...)
+	case logng.SeverityError:
+		logger.Errorf(format, args...)
+	case logng.SeverityWarning:
+		logger.Warningf(format, args...)
+	case logng.SeverityNotice:
+		logger.Noticef(format, args...)
+	case logng.SeverityInfo:
+		logger.Infof(format, args...)
+	case logng.SeverityDebug:
+		logger.Debugf(format, args...)
+	}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	bytes    int
+	hijacked bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.hijacked = true
+	return conn, rw, nil
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,85 @@
+package httplog_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/httplog"
+	"github.com/goinsane/logng/v2/logngtest"
+)
+
+func ExampleMiddleware() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+
+	handler := httplog.Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httplog.FromContext(r.Context()).Info("handling the request.")
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	// Output:
+	// INFO - handling the request.
+	// INFO - GET / 200
+	// INFO - handling the request.
+	// WARNING - GET /unknown 404
+}
+
+func TestHandler_hijack(t *testing.T) {
+	observer := logngtest.NewObserverOutput()
+	logger := logng.NewLogger(observer, logng.SeverityInfo, 0)
+	handler := httplog.Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+	}))
+	served := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(served)
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = ioutil.ReadAll(bufio.NewReader(resp.Body))
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status code %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	<-served
+
+	logs := observer.All()
+	if len(logs) != 1 {
+		t.Fatalf("got %d logs, want 1", len(logs))
+	}
+	if got, want := string(logs[0].Message), "GET / 101"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if n := observer.FilterFieldKey(logng.FieldKeyHTTPBytes).Len(); n != 0 {
+		t.Errorf("got %d logs with the bytes of the hijacked connection, want 0", n)
+	}
+}
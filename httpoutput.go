@@ -0,0 +1,287 @@
+package logng
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// HTTPOutputFormat is the type of body format of HTTPOutput.
+type HTTPOutputFormat int
+
+const (
+	// HTTPOutputFormatNDJSON posts logs as new line delimited json objects.
+	HTTPOutputFormatNDJSON HTTPOutputFormat = iota
+
+	// HTTPOutputFormatJSONArray posts logs as a json array.
+	HTTPOutputFormatJSONArray
+)
+
+// HTTPOutput is an implementation of Output by posting json encoded logs in batches to an HTTP endpoint.
+//
+// Logs are posted by a background goroutine, when the batch is full or the flush interval elapses.
+// A failed post is retried with exponential backoff on network errors, 429 and 5xx status codes.
+// The backoff is aborted on closing, so the failed batch is dropped and the pending logs are posted without retrying.
+// A batch is dropped when all retries fail, and a log is dropped when the number of pending logs exceeds the maximum.
+// Dropped logs are passed to the OnDrop function.
+type HTTPOutput struct {
+	mu         sync.Mutex
+	url        string
+	format     HTTPOutputFormat
+	json       *JSONOutput
+	client     *http.Client
+	headers    http.Header
+	gzip       bool
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	batcher    batcher
+	onDrop     *func(logs [][]byte, err error)
+}
+
+// NewHTTPOutput creates a new HTTPOutput by the given endpoint url, body format and json flags.
+// By default, the batch size is 100, the flush interval is 1 second, the maximum pending count is 10000,
+// failed posts are retried 3 times with a backoff from 100 milliseconds up to 5 seconds,
+// and the HTTP client has the timeout of 10 seconds.
+func NewHTTPOutput(url string, format HTTPOutputFormat, flags JSONOutputFlag) *HTTPOutput {
+	o := &HTTPOutput{
+		url:        url,
+		format:     format,
+		json:       NewJSONOutput(nil, flags),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		headers:    make(http.Header),
+		maxRetries: 3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	o.batcher.start(100, time.Second, o.send, o.handleDrop)
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *HTTPOutput) Log(log *Log) {
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.json.mu.RLock()
+	err := o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		o.batcher.handleError(err)
+		return
+	}
+	o.batcher.add(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// Flush posts the pending logs immediately, and returns the error if the batch has been dropped.
+func (o *HTTPOutput) Flush() error {
+	return o.batcher.flush()
+}
+
+// Close stops the background goroutine and posts the pending logs.
+// Logs after closing are dropped with ErrClosed.
+func (o *HTTPOutput) Close() error {
+	return o.batcher.close()
+}
+
+// SetHeader sets a header which is sent with every request, e.g. for authentication.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetHeader(key, value string) *HTTPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.headers.Set(key, value)
+	return o
+}
+
+// SetGzip sets whether request bodies are compressed by gzip.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetGzip(gzip bool) *HTTPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.gzip = gzip
+	return o
+}
+
+// SetBatchSize sets the number of logs to post at once. If batchSize is less than 1, it is set to 1.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetBatchSize(batchSize int) *HTTPOutput {
+	o.batcher.setBatchSize(batchSize)
+	return o
+}
+
+// SetFlushInterval sets the maximum duration to wait before posting a batch which is not full.
+// It takes effect after the next flush. If flushInterval is less or equal than 0, the batch is only posted when full.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetFlushInterval(flushInterval time.Duration) *HTTPOutput {
+	o.batcher.setFlushInterval(flushInterval)
+	return o
+}
+
+// SetMaxPending sets the maximum number of logs waiting to be posted. If maxPending is less or equal than 0,
+// there is no limit.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetMaxPending(maxPending int) *HTTPOutput {
+	o.batcher.setMaxPending(maxPending)
+	return o
+}
+
+// SetRetry sets the maximum number of retries of a failed post, and the backoff range.
+// The backoff starts from minBackoff and doubles on each retry, up to maxBackoff.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetRetry(maxRetries int, minBackoff, maxBackoff time.Duration) *HTTPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxRetries = maxRetries
+	o.minBackoff = minBackoff
+	o.maxBackoff = maxBackoff
+	return o
+}
+
+// SetClient sets the HTTP client. By default, a client with the timeout of 10 seconds.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetClient(client *http.Client) *HTTPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client = client
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetOnError(f func(error)) *HTTPOutput {
+	o.batcher.setOnError(f)
+	return o
+}
+
+// SetOnDrop sets a function to call with the json encoded logs when they are dropped.
+// The OnError function is also called with the error.
+// It returns the underlying HTTPOutput.
+func (o *HTTPOutput) SetOnDrop(f func(logs [][]byte, err error)) *HTTPOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onDrop)), unsafe.Pointer(&f))
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *HTTPOutput) Err() error {
+	return o.batcher.err()
+}
+
+// Healthy reports whether the last batch has been posted successfully.
+// It returns true if no batch has been posted yet.
+func (o *HTTPOutput) Healthy() bool {
+	return o.batcher.healthy()
+}
+
+// send posts the given batch of json encoded logs.
+func (o *HTTPOutput) send(items []interface{}) error {
+	return o.post(httpOutputLogs(items))
+}
+
+// post posts the given logs, retrying with exponential backoff.
+func (o *HTTPOutput) post(logs [][]byte) error {
+	o.mu.Lock()
+	format, client, useGzip := o.format, o.client, o.gzip
+	maxRetries, backoff, maxBackoff := o.maxRetries, o.minBackoff, o.maxBackoff
+	headers := make(http.Header, len(o.headers))
+	for key, values := range o.headers {
+		headers[key] = values
+	}
+	o.mu.Unlock()
+
+	body := bytes.NewBuffer(make([]byte, 0, 4096))
+	var w io.Writer = body
+	var zw *gzip.Writer
+	if useGzip {
+		zw = gzip.NewWriter(body)
+		w = zw
+	}
+	sep := []byte("\n")
+	if format == HTTPOutputFormatJSONArray {
+		sep = []byte(",")
+		_, _ = w.Write([]byte("["))
+	}
+	for i, data := range logs {
+		if i > 0 {
+			_, _ = w.Write(sep)
+		}
+		_, _ = w.Write(data)
+	}
+	if format == HTTPOutputFormatJSONArray {
+		_, _ = w.Write([]byte("]"))
+	} else {
+		_, _ = w.Write(sep)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("unable to compress body: %w", err)
+		}
+	}
+
+	var err error
+	for i := 0; ; i++ {
+		var retry bool
+		if retry, err = o.sendBody(client, headers, useGzip, format, body.Bytes()); err == nil {
+			return nil
+		}
+		if !retry || i >= maxRetries {
+			return err
+		}
+		o.batcher.handleError(fmt.Errorf("retrying after %v: %w", backoff, err))
+		if !o.batcher.sleep(backoff) {
+			return err
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// sendBody sends the given body once, and reports whether the request should be retried on error.
+func (o *HTTPOutput) sendBody(client *http.Client, headers http.Header, useGzip bool, format HTTPOutputFormat, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, o.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header = headers.Clone()
+	if format == HTTPOutputFormatJSONArray {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if useGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5,
+			fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+func (o *HTTPOutput) handleDrop(items []interface{}, err error) {
+	onDrop := o.onDrop
+	if onDrop == nil || *onDrop == nil {
+		return
+	}
+	(*onDrop)(httpOutputLogs(items), err)
+}
+
+// httpOutputLogs converts the given items of batcher to the json encoded logs.
+func httpOutputLogs(items []interface{}) [][]byte {
+	logs := make([][]byte, 0, len(items))
+	for _, item := range items {
+		logs = append(logs, item.([]byte))
+	}
+	return logs
+}
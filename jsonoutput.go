@@ -6,9 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
-	"sync/atomic"
 	"time"
-	"unsafe"
 )
 
 // JSONOutput is an implementation of Output by writing json to io.Writer w.
@@ -16,8 +14,9 @@ type JSONOutput struct {
 	mu         sync.RWMutex
 	w          io.Writer
 	flags      JSONOutputFlag
-	onError    *func(error)
 	timeLayout string
+	preset     *jsonPreset
+	health
 }
 
 // NewJSONOutput creates a new JSONOutput.
@@ -31,28 +30,61 @@ func NewJSONOutput(w io.Writer, flags JSONOutputFlag) *JSONOutput {
 
 // Log is the implementation of Output.
 func (o *JSONOutput) Log(log *Log) {
+	o.write([]*Log{log})
+}
+
+// LogBatch is the implementation of BatchOutput. It writes the logs by a single write.
+// The logs which can't be encoded are skipped, and the last encoding error is reported.
+func (o *JSONOutput) LogBatch(logs []*Log) {
+	o.write(logs)
+}
+
+// write encodes the given logs and writes them to the writer by a single write.
+func (o *JSONOutput) write(logs []*Log) {
 	var err error
 	defer func() {
-		onError := o.onError
-		if err == nil || onError == nil || *onError == nil {
-			return
-		}
-		(*onError)(err)
+		o.report(err)
 	}()
 
 	o.mu.RLock()
 	defer o.mu.RUnlock()
 
+	buf := bytes.NewBuffer(make([]byte, 0, 4096*len(logs)))
+	for _, log := range logs {
+		n := buf.Len()
+		if e := o.format(buf, log); e != nil {
+			buf.Truncate(n)
+			err = e
+		}
+	}
+	if buf.Len() <= 0 {
+		return
+	}
+
+	if _, e := io.Copy(o.w, buf); e != nil {
+		err = fmt.Errorf("unable to write to writer: %w", e)
+		return
+	}
+}
+
+// format encodes the given log as a json object with a trailing new line into buf.
+func (o *JSONOutput) format(buf *bytes.Buffer, log *Log) (err error) {
+	if o.preset != nil {
+		return o.preset.format(buf, log)
+	}
+
 	var data struct {
-		Severity      *string `json:"severity,omitempty"`
-		Message       string  `json:"message"`
-		Time          *string `json:"time,omitempty"`
-		Timestamp     *int64  `json:"timestamp,omitempty"`
-		SeverityLevel *int    `json:"severity_level,omitempty"`
-		Verbosity     *int    `json:"verbosity,omitempty"`
-		Func          *string `json:"func,omitempty"`
-		File          *string `json:"file,omitempty"`
-		StackTrace    *string `json:"stack_trace,omitempty"`
+		Severity      *string    `json:"severity,omitempty"`
+		Message       string     `json:"message"`
+		Time          *string    `json:"time,omitempty"`
+		Timestamp     *int64     `json:"timestamp,omitempty"`
+		Uptime        *float64   `json:"uptime,omitempty"`
+		SeverityLevel *int       `json:"severity_level,omitempty"`
+		Verbosity     *int       `json:"verbosity,omitempty"`
+		Func          *string    `json:"func,omitempty"`
+		File          *string    `json:"file,omitempty"`
+		StackTrace    *string    `json:"stack_trace,omitempty"`
+		Error         *jsonError `json:"error,omitempty"`
 	}
 	data.Message = string(log.Message)
 
@@ -84,6 +116,11 @@ func (o *JSONOutput) Log(log *Log) {
 		data.Timestamp = &x
 	}
 
+	if o.flags&JSONOutputFlagUptime != 0 {
+		x := logUptime(log).Seconds()
+		data.Uptime = &x
+	}
+
 	if o.flags&JSONOutputFlagSeverityLevel != 0 {
 		x := int(log.Severity)
 		data.SeverityLevel = &x
@@ -129,44 +166,121 @@ func (o *JSONOutput) Log(log *Log) {
 		data.StackTrace = &x
 	}
 
+	if o.flags&JSONOutputFlagError != 0 && log.Error != nil {
+		data.Error = newJSONError(log.Error)
+	}
+
 	var b []byte
 
 	b, err = json.Marshal(&data)
 	if err != nil {
-		err = fmt.Errorf("unable to marshal data: %w", err)
-		return
+		return fmt.Errorf("unable to marshal data: %w", err)
 	}
-	buf := bytes.NewBuffer(bytes.TrimRight(b, "}"))
+	buf.Write(b[:len(b)-1])
 
-	if o.flags&JSONOutputFlagFields != 0 {
+	if o.flags&(JSONOutputFlagFields|JSONOutputFlagNestedFields) != 0 && len(log.Fields) > 0 {
+		nested := o.flags&JSONOutputFlagNestedFields != 0
+		if nested {
+			buf.WriteString(`,"fields":{`)
+		}
 		uniqueKeys := make(map[string]struct{}, len(log.Fields))
 		for idx, field := range log.Fields {
 			var key string
 			if _, ok := uniqueKeys[field.Key]; !ok {
 				uniqueKeys[field.Key] = struct{}{}
-				key = fmt.Sprintf("_%s", field.Key)
+				key = field.Key
+				if !nested {
+					key = fmt.Sprintf("_%s", field.Key)
+				}
 			} else {
 				key = fmt.Sprintf("%d_%s", idx, field.Key)
 			}
-			buf.WriteRune(',')
-			b, err = json.Marshal(map[string]interface{}{key: field.Value})
+			if !nested || idx > 0 {
+				buf.WriteRune(',')
+			}
+			b, err = json.Marshal(map[string]interface{}{key: fieldValue(field.Value)})
 			if err != nil {
-				err = fmt.Errorf("unable to marshal field: %w", err)
-				return
+				return fmt.Errorf("unable to marshal field: %w", err)
 			}
-			b = bytes.TrimLeft(b, "{")
-			b = bytes.TrimRight(b, "}")
-			buf.Write(b)
+			buf.Write(b[1 : len(b)-1])
+		}
+		if nested {
+			buf.WriteRune('}')
 		}
 	}
 
 	buf.WriteString("}\n")
+	return nil
+}
 
-	_, err = io.Copy(o.w, buf)
-	if err != nil {
-		err = fmt.Errorf("unable to write to writer: %w", err)
-		return
+// jsonError is the structured representation of an error in JSONOutput.
+type jsonError struct {
+	Message string       `json:"message"`
+	Type    string       `json:"type"`
+	Stack   string       `json:"stack,omitempty"`
+	Causes  []*jsonError `json:"causes,omitempty"`
+}
+
+// maxJSONErrorCauses is the maximum number of the causes of jsonError.
+const maxJSONErrorCauses = 32
+
+// newJSONError creates a new jsonError by the given error and its wrapped causes.
+func newJSONError(err error) *jsonError {
+	e := newJSONErrorCause(err)
+	queue := unwrapErrors(err)
+	for len(queue) > 0 && len(e.Causes) < maxJSONErrorCauses {
+		cause := queue[0]
+		queue = append(queue[1:], unwrapErrors(cause)...)
+		e.Causes = append(e.Causes, newJSONErrorCause(cause))
+	}
+	return e
+}
+
+// newJSONErrorCause creates a new jsonError by the given error without its causes.
+// The stack is taken from the StackTrace method, or from the "%+v" formatting if it differs from the error message,
+// e.g. for the errors of github.com/pkg/errors.
+func newJSONErrorCause(err error) *jsonError {
+	e := &jsonError{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	switch x := err.(type) {
+	case interface{ StackTrace() *StackTrace }:
+		if st := x.StackTrace(); st != nil {
+			e.Stack = fmt.Sprintf("%+.1s", st)
+		}
+	case fmt.Formatter:
+		if s := fmt.Sprintf("%+v", err); s != e.Message {
+			e.Stack = s
+		}
+	}
+	return e
+}
+
+// unwrapErrors returns the errors wrapped by the given error, by Unwrap() error or Unwrap() []error.
+func unwrapErrors(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			return []error{e}
+		}
+	case interface{ Unwrap() []error }:
+		errs := make([]error, 0, len(x.Unwrap()))
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return errs
 	}
+	return nil
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *JSONOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
 }
 
 // SetWriter sets writer.
@@ -190,10 +304,21 @@ func (o *JSONOutput) SetFlags(flags JSONOutputFlag) *JSONOutput {
 // SetOnError sets a function to call when error occurs.
 // It returns the underlying JSONOutput.
 func (o *JSONOutput) SetOnError(f func(error)) *JSONOutput {
-	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onError)), unsafe.Pointer(&f))
+	o.setOnError(f)
 	return o
 }
 
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *JSONOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *JSONOutput) Healthy() bool {
+	return o.healthy()
+}
+
 // SetTimeLayout sets a time layout to format time field.
 // It returns the underlying JSONOutput.
 func (o *JSONOutput) SetTimeLayout(timeLayout string) *JSONOutput {
@@ -203,6 +328,15 @@ func (o *JSONOutput) SetTimeLayout(timeLayout string) *JSONOutput {
 	return o
 }
 
+// setPreset sets the preset which overrides the flags and the time layout. If preset is nil, the flags are used.
+// It returns the underlying JSONOutput.
+func (o *JSONOutput) setPreset(preset *jsonPreset) *JSONOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.preset = preset
+	return o
+}
+
 // JSONOutputFlag holds single or multiple flags of JSONOutput.
 // A JSONOutput instance uses these flags which are stored by JSONOutputFlag type.
 type JSONOutputFlag int
@@ -228,6 +362,7 @@ const (
 	JSONOutputFlagTimestampMicro
 
 	// JSONOutputFlagSeverityLevel prints the numeric value of severity into severity_level field.
+	// The value isn't the rank of severity, e.g. 2 for error and 7 for alert. See Severity.Rank.
 	JSONOutputFlagSeverityLevel
 
 	// JSONOutputFlagVerbosity prints verbosity field.
@@ -257,7 +392,147 @@ const (
 	// JSONOutputFlagFields prints additional fields if given.
 	JSONOutputFlagFields
 
+	// JSONOutputFlagNestedFields prints additional fields under the fields object without "_" prefix,
+	// instead of the top level. So, they never collide with the reserved keys.
+	// assumes JSONOutputFlagFields.
+	JSONOutputFlagNestedFields
+
+	// JSONOutputFlagError prints the error of the log into error field as an object, which has message, type,
+	// stack when available and causes which are wrapped by the error.
+	JSONOutputFlagError
+
+	// JSONOutputFlagUptime prints the elapsed seconds since the process start into uptime field.
+	JSONOutputFlagUptime
+
 	// JSONOutputFlagDefault holds predefined default flags.
 	JSONOutputFlagDefault = JSONOutputFlagSeverity | JSONOutputFlagTime | JSONOutputFlagLocalTZ |
 		JSONOutputFlagLongFunc | JSONOutputFlagShortFile | JSONOutputFlagStackTraceShortFile | JSONOutputFlagFields
 )
+
+// jsonObject builds a json object by keeping the order of keys.
+// A key which has been already added is prefixed with the index of the value, as JSONOutput does for fields.
+type jsonObject struct {
+	buf  *bytes.Buffer
+	keys map[string]struct{}
+}
+
+func newJSONObject(buf *bytes.Buffer) *jsonObject {
+	return &jsonObject{
+		buf:  buf,
+		keys: make(map[string]struct{}),
+	}
+}
+
+// add adds the given key and value into the object.
+func (j *jsonObject) add(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to marshal value of %q: %w", key, err)
+	}
+	if _, ok := j.keys[key]; ok {
+		key = fmt.Sprintf("%d_%s", len(j.keys), key)
+	}
+	j.keys[key] = struct{}{}
+	k, _ := json.Marshal(key)
+	if len(j.keys) == 1 {
+		j.buf.WriteRune('{')
+	} else {
+		j.buf.WriteRune(',')
+	}
+	j.buf.Write(k)
+	j.buf.WriteRune(':')
+	j.buf.Write(b)
+	return nil
+}
+
+// close closes the object with a trailing new line.
+func (j *jsonObject) close() {
+	if len(j.keys) == 0 {
+		j.buf.WriteRune('{')
+	}
+	j.buf.WriteString("}\n")
+}
+
+// jsonPreset maps the attributes of logs to the keys and the values which the log collectors expect,
+// e.g. Google Cloud Logging. JSONOutput formats logs by the preset instead of its flags if the preset is set.
+type jsonPreset struct {
+	// head returns the attributes which are written before the fields in order.
+	head func(log *Log) []jsonAttr
+
+	// fieldKeys maps the keys of the fields to the attributes, e.g. FieldKeyTraceID.
+	// The other fields are written with their keys.
+	fieldKeys map[string]jsonFieldKey
+
+	// fieldsKey is the key of the object which holds the other fields, e.g. labels.
+	// If it is empty, the other fields are written at the top level.
+	fieldsKey string
+
+	// tail returns the attributes which are written after the fields in order.
+	tail func(log *Log) []jsonAttr
+}
+
+// jsonAttr is a key and value pair of jsonPreset.
+type jsonAttr struct {
+	key   string
+	value interface{}
+}
+
+// jsonFieldKey is the key of the attribute which a field is mapped to, and the optional function to convert its value.
+type jsonFieldKey struct {
+	key   string
+	value func(v interface{}) interface{}
+}
+
+// format encodes the given log as a json object with a trailing new line into buf.
+func (p *jsonPreset) format(buf *bytes.Buffer, log *Log) error {
+	obj := newJSONObject(buf)
+	add := func(f func(log *Log) []jsonAttr) error {
+		if f == nil {
+			return nil
+		}
+		for _, attr := range f(log) {
+			if err := obj.add(attr.key, attr.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(p.head); err != nil {
+		return err
+	}
+	var fields *jsonObject
+	fieldsBuf := bytes.NewBuffer(nil)
+	for _, field := range log.Fields {
+		key, value := field.Key, fieldValue(field.Value)
+		fk, ok := p.fieldKeys[field.Key]
+		if !ok && p.fieldsKey != "" {
+			if fields == nil {
+				fields = newJSONObject(fieldsBuf)
+			}
+			if err := fields.add(key, value); err != nil {
+				return err
+			}
+			continue
+		}
+		if ok {
+			key = fk.key
+			if fk.value != nil {
+				value = fk.value(value)
+			}
+		}
+		if err := obj.add(key, value); err != nil {
+			return err
+		}
+	}
+	if fields != nil {
+		fields.close()
+		if err := obj.add(p.fieldsKey, json.RawMessage(bytes.TrimSuffix(fieldsBuf.Bytes(), []byte("\n")))); err != nil {
+			return err
+		}
+	}
+	if err := add(p.tail); err != nil {
+		return err
+	}
+	obj.close()
+	return nil
+}
@@ -0,0 +1,175 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// KafkaMessage is a message to be published to Kafka.
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// KafkaProducer is the interface that wraps the Produce method.
+//
+// Produce publishes the given messages to Kafka, and returns after they have been delivered or failed.
+// KafkaProducer is implemented by adapters of Kafka clients, e.g. a wrapper of kafka.Writer of segmentio/kafka-go.
+type KafkaProducer interface {
+	Produce(msgs []KafkaMessage) error
+}
+
+// KafkaOutput is an implementation of Output by publishing json encoded logs to a Kafka topic.
+// Logs are published in batches by a background goroutine, when the batch is full or the flush interval elapses.
+type KafkaOutput struct {
+	mu                sync.Mutex
+	producer          KafkaProducer
+	topic             string
+	keyField          string
+	json              *JSONOutput
+	batcher           batcher
+	onDeliveryFailure *func(msgs []KafkaMessage, err error)
+}
+
+// NewKafkaOutput creates a new KafkaOutput by the given producer, topic and json flags.
+// By default, the batch size is 100, the flush interval is 1 second and the maximum pending count is 10000.
+func NewKafkaOutput(producer KafkaProducer, topic string, flags JSONOutputFlag) *KafkaOutput {
+	o := &KafkaOutput{
+		producer: producer,
+		topic:    topic,
+		json:     NewJSONOutput(nil, flags),
+	}
+	o.batcher.start(100, time.Second, o.produce, o.handleDeliveryFailure)
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *KafkaOutput) Log(log *Log) {
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.json.mu.RLock()
+	err := o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		o.batcher.handleError(err)
+		return
+	}
+
+	o.mu.Lock()
+	msg := KafkaMessage{
+		Topic: o.topic,
+		Value: bytes.TrimSuffix(buf.Bytes(), []byte("\n")),
+		Time:  log.Time,
+	}
+	if o.keyField != "" {
+		for _, field := range log.Fields {
+			if field.Key == o.keyField {
+				msg.Key = []byte(fmt.Sprintf("%v", field.Value))
+				break
+			}
+		}
+	}
+	o.mu.Unlock()
+	o.batcher.add(msg)
+}
+
+// Flush publishes the pending logs immediately, and returns the delivery error if any.
+func (o *KafkaOutput) Flush() error {
+	return o.batcher.flush()
+}
+
+// Close stops the background goroutine and publishes the pending logs.
+// Logs after closing are dropped with ErrClosed.
+func (o *KafkaOutput) Close() error {
+	return o.batcher.close()
+}
+
+// SetKeyField sets the key of the field whose value is used as the message key.
+// If keyField is empty or the log doesn't have the field, the message is published without key.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetKeyField(keyField string) *KafkaOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.keyField = keyField
+	return o
+}
+
+// SetBatchSize sets the number of logs to publish at once. If batchSize is less than 1, it is set to 1.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetBatchSize(batchSize int) *KafkaOutput {
+	o.batcher.setBatchSize(batchSize)
+	return o
+}
+
+// SetFlushInterval sets the maximum duration to wait before publishing a batch which is not full.
+// It takes effect after the next flush. If flushInterval is less or equal than 0, the batch is only published when full.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetFlushInterval(flushInterval time.Duration) *KafkaOutput {
+	o.batcher.setFlushInterval(flushInterval)
+	return o
+}
+
+// SetMaxPending sets the maximum number of logs waiting to be published. The logs beyond it are dropped with
+// ErrQueueFull. If maxPending is less or equal than 0, there is no limit.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetMaxPending(maxPending int) *KafkaOutput {
+	o.batcher.setMaxPending(maxPending)
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetOnError(f func(error)) *KafkaOutput {
+	o.batcher.setOnError(f)
+	return o
+}
+
+// SetOnDeliveryFailure sets a function to call with the failed messages when publishing a batch fails,
+// or with the dropped message when the pending logs have reached the maximum.
+// The OnError function is also called with the error.
+// It returns the underlying KafkaOutput.
+func (o *KafkaOutput) SetOnDeliveryFailure(f func(msgs []KafkaMessage, err error)) *KafkaOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onDeliveryFailure)), unsafe.Pointer(&f))
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *KafkaOutput) Err() error {
+	return o.batcher.err()
+}
+
+// Healthy reports whether the last batch has been published successfully.
+// It returns true if no batch has been published yet.
+func (o *KafkaOutput) Healthy() bool {
+	return o.batcher.healthy()
+}
+
+// produce publishes the given batch of messages.
+func (o *KafkaOutput) produce(items []interface{}) error {
+	if err := o.producer.Produce(kafkaMessages(items)); err != nil {
+		return fmt.Errorf("unable to produce messages: %w", err)
+	}
+	return nil
+}
+
+func (o *KafkaOutput) handleDeliveryFailure(items []interface{}, err error) {
+	onDeliveryFailure := o.onDeliveryFailure
+	if onDeliveryFailure == nil || *onDeliveryFailure == nil {
+		return
+	}
+	(*onDeliveryFailure)(kafkaMessages(items), err)
+}
+
+// kafkaMessages converts the given items of batcher to the messages.
+func kafkaMessages(items []interface{}) []KafkaMessage {
+	msgs := make([]KafkaMessage, 0, len(items))
+	for _, item := range items {
+		msgs = append(msgs, item.(KafkaMessage))
+	}
+	return msgs
+}
@@ -0,0 +1,143 @@
+package logng
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// defaultLineWriterMaxLineLen is the default maximum line length of LineWriter.
+const defaultLineWriterMaxLineLen = 64 * 1024
+
+// LineWriter is an io.WriteCloser that splits incoming bytes on new lines and logs each line by the given Logger.
+// It is designed for wrapping third-party writers and pipes, and it is safe for concurrency.
+type LineWriter struct {
+	mu         sync.Mutex
+	logger     *Logger
+	severity   Severity
+	patterns   []severityPattern
+	maxLineLen int
+	buf        []byte
+	closed     bool
+}
+
+type severityPattern struct {
+	re       *regexp.Regexp
+	severity Severity
+}
+
+// NewLineWriter creates a new LineWriter by the given logger and severity.
+// If severity is invalid, it sets SeverityInfo.
+func NewLineWriter(logger *Logger, severity Severity) *LineWriter {
+	if !severity.IsValid() {
+		severity = SeverityInfo
+	}
+	return &LineWriter{
+		logger:     logger,
+		severity:   severity,
+		maxLineLen: defaultLineWriterMaxLineLen,
+	}
+}
+
+// Writer creates a new LineWriter which logs every written line by the given severity to the underlying Logger.
+// It is useful for external code expecting an io.Writer, such as exec.Cmd stdout and stderr.
+// If severity is invalid, it uses SeverityInfo.
+func (l *Logger) Writer(severity Severity) *LineWriter {
+	return NewLineWriter(l, severity)
+}
+
+// Write is the implementation of io.Writer.
+// Write logs every completed line, and buffers the last partial line until a new line or Close.
+// The lines longer than the maximum line length are split, and logged in parts. See SetMaxLineLen.
+// It returns ErrClosed if the underlying LineWriter has been closed.
+func (w *LineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if max := w.maxLineLen; max > 0 && (idx > max || idx < 0 && len(w.buf) > max) {
+			n := max
+			for n > 0 && !utf8.RuneStart(w.buf[n]) {
+				n--
+			}
+			if n <= 0 {
+				n = max
+			}
+			w.logLine(w.buf[:n])
+			w.buf = w.buf[n:]
+			continue
+		}
+		if idx < 0 {
+			break
+		}
+		w.logLine(w.buf[:idx])
+		w.buf = w.buf[idx+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close logs the remaining partial line if any.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.logLine(w.buf)
+	w.buf = nil
+	return nil
+}
+
+// SetMaxLineLen sets the maximum length of the lines in bytes. The longer lines are split at the maximum length,
+// or before the UTF-8 character at the maximum length, and the parts are logged as separate lines. So, the buffer of
+// the partial line doesn't grow without bound when the input has no new lines.
+// If maxLineLen is less than or equal to 0, the lines aren't split.
+// It returns the underlying LineWriter.
+// By default, 64 KiB.
+func (w *LineWriter) SetMaxLineLen(maxLineLen int) *LineWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.maxLineLen = maxLineLen
+	return w
+}
+
+// AddSeverityPattern adds a pattern to detect the severity of lines.
+// Lines matching the pattern are logged by the given severity instead of the underlying LineWriter's severity.
+// Patterns are checked in the order of addition. If severity is invalid, it sets SeverityInfo.
+// It returns the underlying LineWriter.
+func (w *LineWriter) AddSeverityPattern(re *regexp.Regexp, severity Severity) *LineWriter {
+	if !severity.IsValid() {
+		severity = SeverityInfo
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.patterns = append(w.patterns, severityPattern{
+		re:       re,
+		severity: severity,
+	})
+	return w
+}
+
+func (w *LineWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return
+	}
+	severity := w.severity
+	for _, p := range w.patterns {
+		if p.re.Match(line) {
+			severity = p.severity
+			break
+		}
+	}
+	w.logger.out(severity, string(line), nil, nil)
+}
@@ -0,0 +1,111 @@
+package logng
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// LoadBalanceStrategy is the type of strategy of LoadBalanceOutput to choose an output.
+type LoadBalanceStrategy int
+
+const (
+	// LoadBalanceRoundRobin passes the logs to the outputs in turn.
+	LoadBalanceRoundRobin LoadBalanceStrategy = iota
+
+	// LoadBalanceLeastBusy passes the log to the output which has the least number of logs in progress.
+	LoadBalanceLeastBusy
+)
+
+// LoadBalanceOutput is an implementation of Output by distributing the logs across the given equivalent outputs,
+// e.g. the outputs of multiple collector endpoints, to spread the write load.
+//
+// The logs aren't ordered across the outputs. If the sticky key is set, the logs which have the same value of
+// the field with the sticky key are always passed to the same output, so their order is preserved,
+// e.g. per request or per goroutine by a field such as "request_id".
+type LoadBalanceOutput struct {
+	mu        sync.RWMutex
+	outputs   []Output
+	busy      []int64
+	next      uint64
+	strategy  LoadBalanceStrategy
+	stickyKey string
+}
+
+// NewLoadBalanceOutput creates a new LoadBalanceOutput by the given strategy and outputs.
+func NewLoadBalanceOutput(strategy LoadBalanceStrategy, outputs ...Output) *LoadBalanceOutput {
+	o := &LoadBalanceOutput{
+		outputs:  make([]Output, len(outputs)),
+		busy:     make([]int64, len(outputs)),
+		strategy: strategy,
+	}
+	copy(o.outputs, outputs)
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *LoadBalanceOutput) Log(log *Log) {
+	o.mu.RLock()
+	n := len(o.outputs)
+	if n == 0 {
+		o.mu.RUnlock()
+		return
+	}
+	idx := -1
+	if o.stickyKey != "" {
+		for i := len(log.Fields) - 1; i >= 0; i-- {
+			if field := log.Fields[i]; field.Key == o.stickyKey {
+				h := fnv.New32a()
+				_, _ = fmt.Fprintf(h, "%v", field.Value)
+				idx = int(h.Sum32() % uint32(n))
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		switch o.strategy {
+		case LoadBalanceLeastBusy:
+			idx = 0
+			least := atomic.LoadInt64(&o.busy[0])
+			for i := 1; i < n && least > 0; i++ {
+				if busy := atomic.LoadInt64(&o.busy[i]); busy < least {
+					idx, least = i, busy
+				}
+			}
+		default:
+			idx = int((atomic.AddUint64(&o.next, 1) - 1) % uint64(n))
+		}
+	}
+	output, busy := o.outputs[idx], &o.busy[idx]
+	o.mu.RUnlock()
+
+	atomic.AddInt64(busy, 1)
+	defer atomic.AddInt64(busy, -1)
+	output.Log(log)
+}
+
+// Flush is the implementation of Flusher. It flushes all the outputs.
+func (o *LoadBalanceOutput) Flush() error {
+	o.mu.RLock()
+	outputs := o.outputs
+	o.mu.RUnlock()
+	return flushOutputs(outputs)
+}
+
+// Close is the implementation of io.Closer. It closes all the outputs.
+func (o *LoadBalanceOutput) Close() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return closeOutputs(o.outputs)
+}
+
+// SetStickyKey sets the field key to pass the logs which have the same value of the field to the same output.
+// If key is empty, the logs are distributed by the strategy only.
+// It returns the underlying LoadBalanceOutput.
+func (o *LoadBalanceOutput) SetStickyKey(key string) *LoadBalanceOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stickyKey = key
+	return o
+}
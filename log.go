@@ -1,6 +1,8 @@
 package logng
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 )
 
@@ -37,3 +39,48 @@ func (l *Log) Clone() *Log {
 	}
 	return l2
 }
+
+// String is the implementation of fmt.Stringer.
+// It is synonym with fmt.Sprintf("%s", l).
+func (l *Log) String() string {
+	return fmt.Sprintf("%s", l)
+}
+
+// Format is the implementation of fmt.Formatter.
+// Format renders the underlying Log like TextOutput without the trailing new line.
+//
+// For '%s' (also '%v'):
+//
+//	%s       show date, time, severity and message.
+//	%+s      same with '%s', also show file path, line, fields and stack trace if given.
+//	%#s      same with '%s', also show file name and line.
+//	%+#s     same with '%+s', use file name as file path.
+//
+// Other verbs are reported like the bad verbs of fmt, e.g. %!d(*logng.Log=...).
+func (l *Log) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's', 'v':
+		if l == nil {
+			_, _ = f.Write([]byte("<nil>"))
+			return
+		}
+		flags := TextOutputFlagDate | TextOutputFlagTime | TextOutputFlagSeverity | TextOutputFlagPadding
+		if f.Flag('+') {
+			flags |= TextOutputFlagLongFile | TextOutputFlagFields | TextOutputFlagStackTrace
+		}
+		if f.Flag('#') {
+			flags |= TextOutputFlagShortFile
+			if f.Flag('+') {
+				flags |= TextOutputFlagStackTraceShortFile
+			}
+		}
+		o := &TextOutput{flags: flags}
+		buf := bytes.NewBuffer(make([]byte, 0, 4096))
+		o.format(buf, l)
+		b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		b = bytes.TrimSuffix(b, []byte("\n\t"))
+		_, _ = f.Write(b)
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(*logng.Log=%s)", verb, l)
+	}
+}
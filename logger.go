@@ -15,6 +15,8 @@ type Logger struct {
 	output             Output
 	severity           Severity
 	verbose            Verbose
+	vmodule            *vmodule
+	named              *string
 	printSeverity      Severity
 	stackTraceSeverity Severity
 	stackTraceSize     int
@@ -24,6 +26,17 @@ type Logger struct {
 	suffix             string
 	fields             Fields
 	ctxErrVerbosity    Verbose
+	errVerbosityFunc   func(error) (Verbose, bool)
+	onLog              func(*Log)
+	callerSkip         int
+	sampler            *Sampler
+	callSiteLimit      *callSiteLimit
+	hooks              []loggerHook
+	groups             []loggerGroup
+	sequence           *loggerSequence
+	development        bool
+	fatalExitCode      int
+	fatalFlushTimeout  time.Duration
 }
 
 // NewLogger creates a new Logger. If severity is invalid, it sets SeverityInfo.
@@ -38,6 +51,8 @@ func NewLogger(output Output, severity Severity, verbose Verbose) *Logger {
 		printSeverity:      SeverityInfo,
 		stackTraceSeverity: SeverityNone,
 		stackTraceSize:     64,
+		fatalExitCode:      1,
+		fatalFlushTimeout:  5 * time.Second,
 	}
 }
 
@@ -52,6 +67,8 @@ func (l *Logger) Clone() *Logger {
 		output:             l.output,
 		severity:           l.severity,
 		verbose:            l.verbose,
+		vmodule:            l.vmodule,
+		named:              l.named,
 		printSeverity:      l.printSeverity,
 		stackTraceSeverity: l.stackTraceSeverity,
 		stackTraceSize:     l.stackTraceSize,
@@ -61,6 +78,17 @@ func (l *Logger) Clone() *Logger {
 		suffix:             l.suffix,
 		fields:             l.fields.Clone(),
 		ctxErrVerbosity:    l.ctxErrVerbosity,
+		errVerbosityFunc:   l.errVerbosityFunc,
+		onLog:              l.onLog,
+		callerSkip:         l.callerSkip,
+		sampler:            l.sampler,
+		callSiteLimit:      l.callSiteLimit,
+		hooks:              l.hooks,
+		groups:             l.groups,
+		sequence:           l.sequence,
+		development:        l.development,
+		fatalExitCode:      l.fatalExitCode,
+		fatalFlushTimeout:  l.fatalFlushTimeout,
 	}
 	if l.time != nil {
 		tm := *l.time
@@ -69,7 +97,14 @@ func (l *Logger) Clone() *Logger {
 	return l2
 }
 
-func (l *Logger) out(severity Severity, message string, err error) {
+// logOptions holds the optional values of a single log.
+type logOptions struct {
+	fields Fields
+	time   time.Time
+	pc     uintptr
+}
+
+func (l *Logger) out(severity Severity, message string, err error, opts *logOptions) {
 	if l == nil {
 		return
 	}
@@ -82,18 +117,38 @@ func (l *Logger) out(severity Severity, message string, err error) {
 		severity = l.printSeverity
 	}
 
-	if l.output == nil {
+	if l.output == nil && l.onLog == nil {
+		return
+	}
+	pc := l.callerPC(4)
+	if opts != nil && opts.pc != 0 {
+		pc = opts.pc
+	}
+	lSeverity, verbose := l.levels(pc)
+	if lSeverity.Rank() < severity.Rank() {
 		return
 	}
-	if l.severity < severity {
+	if verbose < l.verbosity {
 		return
 	}
-	if l.verbose < l.verbosity {
+	if (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && verbose < l.ctxErrVerbosity {
 		return
 	}
-	if (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && l.verbose < l.ctxErrVerbosity {
+	if err != nil && l.errVerbosityFunc != nil {
+		if verbosity, ok := l.errVerbosityFunc(err); ok && verbose < verbosity {
+			return
+		}
+	}
+	if l.sampler != nil && !l.sampler.Sample(severity, message) {
 		return
 	}
+	var skipped int
+	if l.callSiteLimit != nil {
+		var ok bool
+		if ok, skipped = l.callSiteLimit.allow(pc, time.Now()); !ok {
+			return
+		}
+	}
 
 	messageLen := len(l.prefix) + len(message) + len(l.suffix)
 
@@ -104,6 +159,16 @@ func (l *Logger) out(severity Severity, message string, err error) {
 		Verbosity: l.verbosity,
 		Fields:    l.fields.Clone(),
 	}
+	if opts != nil && len(opts.fields) > 0 {
+		log.Fields = append(log.Fields, opts.fields...)
+	}
+	if len(l.groups) > 0 {
+		log.Fields = groupFields(log.Fields, l.groups)
+	}
+	if skipped > 0 {
+		log.Fields = append(log.Fields, Field{Key: "skipped", Value: skipped})
+	}
+	log.Fields.resolveValues()
 
 	log.Message = append(log.Message, l.prefix...)
 	log.Message = append(log.Message, message...)
@@ -112,19 +177,26 @@ func (l *Logger) out(severity Severity, message string, err error) {
 		log.Message = log.Message[:messageLen-1]
 	}
 
-	if l.time != nil {
+	if opts != nil && !opts.time.IsZero() {
+		log.Time = opts.time
+	} else if l.time != nil {
 		log.Time = *l.time
 	} else {
 		log.Time = time.Now()
 	}
 
-	includeStackTrace := l.stackTraceSeverity >= severity
+	includeStackTrace := l.stackTraceSeverity.Rank() >= severity.Rank()
 
-	stSize := 1
-	if includeStackTrace {
-		stSize = l.stackTraceSize
+	var st *StackTrace
+	if opts != nil && opts.pc != 0 {
+		st = stackTraceFromPC(opts.pc, includeStackTrace, l.stackTraceSize)
+	} else {
+		stSize := 1
+		if includeStackTrace {
+			stSize = l.stackTraceSize
+		}
+		st = CurrentStackTrace(stSize, 5+l.callerSkip)
 	}
-	st := CurrentStackTrace(stSize, 5)
 
 	if st.SizeOfCallers() > 0 {
 		log.StackCaller = st.Caller(0)
@@ -134,7 +206,37 @@ func (l *Logger) out(severity Severity, message string, err error) {
 		log.StackTrace = st
 	}
 
-	l.output.Log(log)
+	if !l.runHooks(log) {
+		return
+	}
+
+	if l.sequence != nil {
+		log.Fields = append(log.Fields, l.sequence.next())
+	}
+
+	if l.onLog != nil {
+		l.onLog(log)
+	}
+
+	if l.output != nil {
+		l.output.Log(log)
+	}
+}
+
+// levels returns the severity and verbose of the underlying Logger for the caller at the given program counter.
+// They are resolved from the registry for the named loggers, and the verbose is overridden by vmodule.
+// It must be called with l.mu held.
+func (l *Logger) levels(pc uintptr) (Severity, Verbose) {
+	severity, verbose := l.severity, l.verbose
+	if l.named != nil {
+		severity, verbose = registry.resolve(*l.named)
+	}
+	if l.vmodule != nil && pc != 0 {
+		if v, ok := l.vmodule.verbose(pc); ok {
+			verbose = v
+		}
+	}
+	return severity, verbose
 }
 
 func (l *Logger) log(severity Severity, args ...interface{}) {
@@ -145,16 +247,45 @@ func (l *Logger) log(severity Severity, args ...interface{}) {
 			break
 		}
 	}
-	l.out(severity, fmt.Sprint(args...), err)
+	l.out(severity, fmt.Sprint(args...), err, nil)
 }
 
-func (l *Logger) logf(severity Severity, format string, args ...interface{}) {
+func (l *Logger) logf(severity Severity, format string, args ...interface{}) (message string) {
 	var err error
 	wErr := fmt.Errorf(format, args...)
 	if e, ok := wErr.(wrappedError); ok {
 		err = e.Unwrap()
 	}
-	l.out(severity, wErr.Error(), err)
+	message = wErr.Error()
+	l.out(severity, message, err, nil)
+	return message
+}
+
+func (l *Logger) loge(severity Severity, err error, args ...interface{}) {
+	var message string
+	if len(args) > 0 {
+		message = fmt.Sprint(args...)
+	} else if err != nil {
+		message = err.Error()
+	}
+	l.out(severity, message, err, nil)
+}
+
+func (l *Logger) logs(severity Severity, err error, msg string, kvs ...interface{}) {
+	var opts *logOptions
+	if n := len(kvs) / 2; n > 0 {
+		fields := make(Fields, 0, n)
+		for i := 0; i < n; i++ {
+			j := i * 2
+			k, ok := kvs[j].(string)
+			if !ok {
+				k = fmt.Sprintf("%v", kvs[j])
+			}
+			fields = append(fields, Field{Key: k, Value: kvs[j+1]})
+		}
+		opts = &logOptions{fields: fields}
+	}
+	l.out(severity, msg, err, opts)
 }
 
 func (l *Logger) logln(severity Severity, args ...interface{}) {
@@ -165,25 +296,170 @@ func (l *Logger) logln(severity Severity, args ...interface{}) {
 			break
 		}
 	}
-	l.out(severity, fmt.Sprintln(args...), err)
+	l.out(severity, fmt.Sprintln(args...), err, nil)
+}
+
+// Emergency logs to the EMERGENCY severity logs.
+func (l *Logger) Emergency(args ...interface{}) {
+	l.log(SeverityEmergency, args...)
+}
+
+// Emergencyf logs to the EMERGENCY severity logs.
+func (l *Logger) Emergencyf(format string, args ...interface{}) {
+	l.logf(SeverityEmergency, format, args...)
+}
+
+// Emergencyln logs to the EMERGENCY severity logs.
+func (l *Logger) Emergencyln(args ...interface{}) {
+	l.logln(SeverityEmergency, args...)
+}
+
+// Alert logs to the ALERT severity logs.
+func (l *Logger) Alert(args ...interface{}) {
+	l.log(SeverityAlert, args...)
+}
+
+// Alertf logs to the ALERT severity logs.
+func (l *Logger) Alertf(format string, args ...interface{}) {
+	l.logf(SeverityAlert, format, args...)
+}
+
+// Alertln logs to the ALERT severity logs.
+func (l *Logger) Alertln(args ...interface{}) {
+	l.logln(SeverityAlert, args...)
 }
 
-// Fatal logs to the FATAL severity logs, then calls os.Exit(1).
+// Fatal logs to the FATAL severity logs, then calls os.Exit with the underlying Logger's fatal exit code.
 func (l *Logger) Fatal(args ...interface{}) {
 	l.log(SeverityFatal, args...)
-	os.Exit(1)
+	_ = l.fatalSync()
+	os.Exit(l.getFatalExitCode())
 }
 
-// Fatalf logs to the FATAL severity logs, then calls os.Exit(1).
+// Fatalf logs to the FATAL severity logs, then calls os.Exit with the underlying Logger's fatal exit code.
 func (l *Logger) Fatalf(format string, args ...interface{}) {
 	l.logf(SeverityFatal, format, args...)
-	os.Exit(1)
+	_ = l.fatalSync()
+	os.Exit(l.getFatalExitCode())
 }
 
-// Fatalln logs to the FATAL severity logs, then calls os.Exit(1).
+// Fatalln logs to the FATAL severity logs, then calls os.Exit with the underlying Logger's fatal exit code.
 func (l *Logger) Fatalln(args ...interface{}) {
 	l.logln(SeverityFatal, args...)
-	os.Exit(1)
+	_ = l.fatalSync()
+	os.Exit(l.getFatalExitCode())
+}
+
+// FatalCode logs to the FATAL severity logs, then calls os.Exit with the given code.
+func (l *Logger) FatalCode(code int, args ...interface{}) {
+	l.log(SeverityFatal, args...)
+	_ = l.fatalSync()
+	os.Exit(code)
+}
+
+// FatalCodef logs to the FATAL severity logs, then calls os.Exit with the given code.
+func (l *Logger) FatalCodef(code int, format string, args ...interface{}) {
+	l.logf(SeverityFatal, format, args...)
+	_ = l.fatalSync()
+	os.Exit(code)
+}
+
+// FatalCodeln logs to the FATAL severity logs, then calls os.Exit with the given code.
+func (l *Logger) FatalCodeln(code int, args ...interface{}) {
+	l.logln(SeverityFatal, args...)
+	_ = l.fatalSync()
+	os.Exit(code)
+}
+
+// getFatalExitCode returns the underlying Logger's fatal exit code.
+func (l *Logger) getFatalExitCode() int {
+	if l == nil {
+		return 1
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.fatalExitCode
+}
+
+// fatalSync flushes the underlying Logger's output tree by Logger.Sync before exiting by the Fatal methods.
+// It gives up waiting after the fatal flush timeout, so a stuck output can't prevent exiting.
+func (l *Logger) fatalSync() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	timeout := l.fatalFlushTimeout
+	l.mu.RUnlock()
+	return syncTimeout(l.Sync, timeout)
+}
+
+// Panic logs to the PANIC severity logs, then panics with the message.
+func (l *Logger) Panic(args ...interface{}) {
+	l.log(SeverityPanic, args...)
+	panic(fmt.Sprint(args...))
+}
+
+// Panicf logs to the PANIC severity logs, then panics with the message.
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	panic(l.logf(SeverityPanic, format, args...))
+}
+
+// Panicln logs to the PANIC severity logs, then panics with the message.
+func (l *Logger) Panicln(args ...interface{}) {
+	l.logln(SeverityPanic, args...)
+	panic(fmt.Sprintln(args...))
+}
+
+// DPanic logs to the PANIC severity logs, then panics with the message if the underlying Logger is
+// in development mode.
+func (l *Logger) DPanic(args ...interface{}) {
+	l.log(SeverityPanic, args...)
+	if l.isDevelopment() {
+		panic(fmt.Sprint(args...))
+	}
+}
+
+// DPanicf logs to the PANIC severity logs, then panics with the message if the underlying Logger is
+// in development mode.
+func (l *Logger) DPanicf(format string, args ...interface{}) {
+	message := l.logf(SeverityPanic, format, args...)
+	if l.isDevelopment() {
+		panic(message)
+	}
+}
+
+// DPanicln logs to the PANIC severity logs, then panics with the message if the underlying Logger is
+// in development mode.
+func (l *Logger) DPanicln(args ...interface{}) {
+	l.logln(SeverityPanic, args...)
+	if l.isDevelopment() {
+		panic(fmt.Sprintln(args...))
+	}
+}
+
+// isDevelopment reports whether the underlying Logger is in development mode.
+func (l *Logger) isDevelopment() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.development
+}
+
+// Critical logs to the CRITICAL severity logs.
+func (l *Logger) Critical(args ...interface{}) {
+	l.log(SeverityCritical, args...)
+}
+
+// Criticalf logs to the CRITICAL severity logs.
+func (l *Logger) Criticalf(format string, args ...interface{}) {
+	l.logf(SeverityCritical, format, args...)
+}
+
+// Criticalln logs to the CRITICAL severity logs.
+func (l *Logger) Criticalln(args ...interface{}) {
+	l.logln(SeverityCritical, args...)
 }
 
 // Error logs to the ERROR severity logs.
@@ -201,6 +477,18 @@ func (l *Logger) Errorln(args ...interface{}) {
 	l.logln(SeverityError, args...)
 }
 
+// Errore logs to the ERROR severity logs with the given error. If no argument is given, the message is
+// the error message.
+func (l *Logger) Errore(err error, args ...interface{}) {
+	l.loge(SeverityError, err, args...)
+}
+
+// ErrorS logs to the ERROR severity logs with the given error, constant message and keys and values of Field.
+// It doesn't format the arguments, unlike Error.
+func (l *Logger) ErrorS(err error, msg string, kvs ...interface{}) {
+	l.logs(SeverityError, err, msg, kvs...)
+}
+
 // Warning logs to the WARNING severity logs.
 func (l *Logger) Warning(args ...interface{}) {
 	l.log(SeverityWarning, args...)
@@ -216,6 +504,33 @@ func (l *Logger) Warningln(args ...interface{}) {
 	l.logln(SeverityWarning, args...)
 }
 
+// Warninge logs to the WARNING severity logs with the given error. If no argument is given, the message is
+// the error message.
+func (l *Logger) Warninge(err error, args ...interface{}) {
+	l.loge(SeverityWarning, err, args...)
+}
+
+// WarningS logs to the WARNING severity logs with the given constant message and keys and values of Field.
+// It doesn't format the arguments, unlike Warning.
+func (l *Logger) WarningS(msg string, kvs ...interface{}) {
+	l.logs(SeverityWarning, nil, msg, kvs...)
+}
+
+// Notice logs to the NOTICE severity logs.
+func (l *Logger) Notice(args ...interface{}) {
+	l.log(SeverityNotice, args...)
+}
+
+// Noticef logs to the NOTICE severity logs.
+func (l *Logger) Noticef(format string, args ...interface{}) {
+	l.logf(SeverityNotice, format, args...)
+}
+
+// Noticeln logs to the NOTICE severity logs.
+func (l *Logger) Noticeln(args ...interface{}) {
+	l.logln(SeverityNotice, args...)
+}
+
 // Info logs to the INFO severity logs.
 func (l *Logger) Info(args ...interface{}) {
 	l.log(SeverityInfo, args...)
@@ -231,6 +546,12 @@ func (l *Logger) Infoln(args ...interface{}) {
 	l.logln(SeverityInfo, args...)
 }
 
+// InfoS logs to the INFO severity logs with the given constant message and keys and values of Field.
+// It doesn't format the arguments, unlike Info.
+func (l *Logger) InfoS(msg string, kvs ...interface{}) {
+	l.logs(SeverityInfo, nil, msg, kvs...)
+}
+
 // Debug logs to the DEBUG severity logs.
 func (l *Logger) Debug(args ...interface{}) {
 	l.log(SeverityDebug, args...)
@@ -246,6 +567,12 @@ func (l *Logger) Debugln(args ...interface{}) {
 	l.logln(SeverityDebug, args...)
 }
 
+// DebugS logs to the DEBUG severity logs with the given constant message and keys and values of Field.
+// It doesn't format the arguments, unlike Debug.
+func (l *Logger) DebugS(msg string, kvs ...interface{}) {
+	l.logs(SeverityDebug, nil, msg, kvs...)
+}
+
 // Print logs a log which has the underlying Logger's print severity.
 func (l *Logger) Print(args ...interface{}) {
 	l.log(severityPrint, args...)
@@ -261,6 +588,21 @@ func (l *Logger) Println(args ...interface{}) {
 	l.logln(severityPrint, args...)
 }
 
+// Enabled reports whether the underlying Logger logs with the given severity.
+func (l *Logger) Enabled(severity Severity) bool {
+	return l.enabled(severity, 1)
+}
+
+func (l *Logger) enabled(severity Severity, skip int) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	lSeverity, verbose := l.levels(l.callerPC(skip + 2))
+	return (l.output != nil || l.onLog != nil) && lSeverity.Rank() >= severity.Rank() && verbose >= l.verbosity
+}
+
 // SetOutput sets the underlying Logger's output.
 // It returns the underlying Logger.
 func (l *Logger) SetOutput(output Output) *Logger {
@@ -289,6 +631,46 @@ func (l *Logger) SetSeverity(severity Severity) *Logger {
 	return l
 }
 
+// SetFatalExitCode sets the exit code which is used by the Fatal methods of the underlying Logger.
+// It returns the underlying Logger.
+// By default, 1.
+func (l *Logger) SetFatalExitCode(code int) *Logger {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fatalExitCode = code
+	return l
+}
+
+// SetFatalFlushTimeout sets the maximum duration to wait for flushing the output tree by the Fatal methods
+// of the underlying Logger before exiting. If timeout is less or equal than 0, they wait until flushing ends.
+// It returns the underlying Logger.
+// By default, 5 seconds.
+func (l *Logger) SetFatalFlushTimeout(timeout time.Duration) *Logger {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fatalFlushTimeout = timeout
+	return l
+}
+
+// SetDevelopment sets whether the underlying Logger is in development mode. In development mode, the DPanic methods
+// panic after logging.
+// It returns the underlying Logger.
+func (l *Logger) SetDevelopment(development bool) *Logger {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.development = development
+	return l
+}
+
 // SetVerbose sets the underlying Logger's verbose.
 // It returns the underlying Logger.
 func (l *Logger) SetVerbose(verbose Verbose) *Logger {
@@ -309,7 +691,7 @@ func (l *Logger) SetPrintSeverity(printSeverity Severity) *Logger {
 	if l == nil {
 		return nil
 	}
-	if !printSeverity.IsValid() || printSeverity <= SeverityFatal {
+	if !printSeverity.IsValid() || printSeverity.Rank() <= SeverityFatal.Rank() {
 		printSeverity = SeverityInfo
 	}
 	l.mu.Lock()
@@ -352,13 +734,30 @@ func (l *Logger) SetStackTraceSize(stackTraceSize int) *Logger {
 	return l
 }
 
+// SetOnLog sets a function to call for every log which passes the severity and verbosity filters, before the output.
+// The function is called independently of the output, and it must not modify the log.
+// It returns the underlying Logger.
+func (l *Logger) SetOnLog(f func(log *Log)) *Logger {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onLog = f
+	return l
+}
+
 // V clones the underlying Logger with the given verbosity if the underlying Logger's verbose is greater or equal to the given verbosity, otherwise returns nil.
 func (l *Logger) V(verbosity Verbose) *Logger {
+	return l.v(verbosity, 1)
+}
+
+func (l *Logger) v(verbosity Verbose, skip int) *Logger {
 	if l == nil {
 		return nil
 	}
 	l.mu.RLock()
-	if l.verbose < verbosity {
+	if _, verbose := l.levels(l.callerPC(skip + 2)); verbose < verbosity {
 		l.mu.RUnlock()
 		return nil
 	}
@@ -446,6 +845,23 @@ func (l *Logger) WithFields(fields ...Field) *Logger {
 	return l2
 }
 
+// WithGroup clones the underlying Logger by starting a group with the given name. The fields which are added
+// after it, including the fields of the logs, are nested under the group. If the group has no field,
+// it is omitted. If name is empty, it returns a clone without a group.
+func (l *Logger) WithGroup(name string) *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	if name == "" {
+		return l2
+	}
+	groups := make([]loggerGroup, 0, len(l2.groups)+1)
+	groups = append(groups, l2.groups...)
+	l2.groups = append(groups, loggerGroup{name: name, start: len(l2.fields)})
+	return l2
+}
+
 // WithFieldKeyVals clones the underlying Logger with given keys and values of Field.
 func (l *Logger) WithFieldKeyVals(kvs ...interface{}) *Logger {
 	if l == nil {
@@ -483,3 +899,38 @@ func (l *Logger) WithCtxErrVerbosity(verbosity Verbose) *Logger {
 	l2.ctxErrVerbosity = verbosity
 	return l2
 }
+
+// WithErrVerbosityFunc clones the underlying Logger with error verbosity function.
+// If the log has an error and the function returns true for the error, the returned value is used as verbosity.
+// It allows demoting arbitrary error classes such as io.EOF to higher verbosity.
+func (l *Logger) WithErrVerbosityFunc(f func(err error) (verbosity Verbose, ok bool)) *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	l2.errVerbosityFunc = f
+	return l2
+}
+
+// WithCallerSkip clones the underlying Logger by adding the given number of stack frames to skip while detecting the caller.
+// It is useful for wrapping Logger by other logging APIs.
+func (l *Logger) WithCallerSkip(skip int) *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	l2.callerSkip += skip
+	return l2
+}
+
+// WithSampling clones the underlying Logger with the given Sampler.
+// The logs which aren't kept by the Sampler are dropped before they are built.
+// If sampler is nil, the clone has no sampling.
+func (l *Logger) WithSampling(sampler *Sampler) *Logger {
+	if l == nil {
+		return nil
+	}
+	l2 := l.Clone()
+	l2.sampler = sampler
+	return l2
+}
@@ -0,0 +1,143 @@
+package logng
+
+import (
+	"strings"
+	"sync"
+)
+
+// loggerRegistry holds the named loggers and their severity and verbose overrides.
+type loggerRegistry struct {
+	mu         sync.RWMutex
+	loggers    map[string]*Logger
+	severities map[string]Severity
+	verboses   map[string]Verbose
+}
+
+var registry = &loggerRegistry{
+	loggers:    make(map[string]*Logger),
+	severities: make(map[string]Severity),
+	verboses:   make(map[string]Verbose),
+}
+
+// GetLogger returns the named Logger by the given dot-separated hierarchical name, e.g. "db.postgres".
+// The Logger is created at the first call and cached for the later calls. An empty name returns the default Logger.
+//
+// The named Logger writes to the default Logger's current output, and has the field "logger" with its name.
+// Its severity and verbose are resolved at logging time from the overrides set by SetLoggerSeverity and
+// SetLoggerVerbose: "a.b.c" falls back to "a.b", then "a", then the default Logger's.
+// Changing the severity or verbose of the named Logger by its own setters has no effect.
+func GetLogger(name string) *Logger {
+	if name == "" {
+		return defaultLogger
+	}
+	registry.mu.RLock()
+	l, ok := registry.loggers[name]
+	registry.mu.RUnlock()
+	if ok {
+		return l
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if l, ok = registry.loggers[name]; ok {
+		return l
+	}
+	l = defaultLogger.Clone()
+	l.output = defaultLoggerOutput{}
+	l.onLog = nil
+	l.named = &name
+	l.fields = append(l.fields, Field{Key: "logger", Value: name})
+	registry.loggers[name] = l
+	return l
+}
+
+// SetLoggerSeverity overrides the severity of the named Logger and its descendants at runtime.
+// An empty name overrides the root of the hierarchy instead of the default Logger's severity.
+func SetLoggerSeverity(name string, severity Severity) {
+	if !severity.IsValid() {
+		severity = SeverityInfo
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.severities[name] = severity
+}
+
+// SetLoggerVerbose overrides the verbose of the named Logger and its descendants at runtime.
+// An empty name overrides the root of the hierarchy instead of the default Logger's verbose.
+func SetLoggerVerbose(name string, verbose Verbose) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.verboses[name] = verbose
+}
+
+// UnsetLoggerLevels removes the severity and verbose overrides of the named Logger.
+// So, the named Logger falls back to its ancestors' again.
+func UnsetLoggerLevels(name string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.severities, name)
+	delete(registry.verboses, name)
+}
+
+// resolve returns the severity and verbose of the given name by walking up the hierarchy.
+func (r *loggerRegistry) resolve(name string) (severity Severity, verbose Verbose) {
+	r.mu.RLock()
+	severityOK, verboseOK := false, false
+	for {
+		if !severityOK {
+			severity, severityOK = r.severities[name]
+		}
+		if !verboseOK {
+			verbose, verboseOK = r.verboses[name]
+		}
+		if (severityOK && verboseOK) || name == "" {
+			break
+		}
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			name = name[:idx]
+		} else {
+			name = ""
+		}
+	}
+	r.mu.RUnlock()
+	if severityOK && verboseOK {
+		return severity, verbose
+	}
+	defaultLogger.mu.RLock()
+	if !severityOK {
+		severity = defaultLogger.severity
+	}
+	if !verboseOK {
+		verbose = defaultLogger.verbose
+	}
+	defaultLogger.mu.RUnlock()
+	return severity, verbose
+}
+
+// defaultLoggerOutput is an implementation of Output which writes to the default Logger's current output.
+type defaultLoggerOutput struct{}
+
+// Log is the implementation of Output.
+func (defaultLoggerOutput) Log(log *Log) {
+	defaultLogger.mu.RLock()
+	output := defaultLogger.output
+	defaultLogger.mu.RUnlock()
+	if output != nil {
+		output.Log(log)
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the default Logger's current output.
+func (defaultLoggerOutput) Flush() error {
+	defaultLogger.mu.RLock()
+	output := defaultLogger.output
+	defaultLogger.mu.RUnlock()
+	return flushOutput(output)
+}
+
+// Close is the implementation of io.Closer. It closes the default Logger's current output.
+func (defaultLoggerOutput) Close() error {
+	defaultLogger.mu.RLock()
+	output := defaultLogger.output
+	defaultLogger.mu.RUnlock()
+	return closeOutput(output)
+}
@@ -2,7 +2,9 @@
 package logng
 
 import (
+	"fmt"
 	"io"
+	"log"
 	"os"
 	"time"
 )
@@ -12,9 +14,14 @@ func Reset() {
 	SetOutput(defaultTextOutput)
 	SetSeverity(SeverityInfo)
 	SetVerbose(0)
+	_ = SetVModule("")
 	SetPrintSeverity(SeverityInfo)
 	SetStackTraceSeverity(SeverityNone)
 	SetStackTraceSize(64)
+	SetFatalExitCode(1)
+	SetFatalFlushTimeout(5 * time.Second)
+	SetOnLog(nil)
+	defaultTextOutput.reset()
 	SetTextOutputWriter(defaultTextOutputWriter)
 	SetTextOutputFlags(TextOutputFlagDefault)
 }
@@ -33,22 +40,138 @@ func Clone() *Logger {
 	return defaultLogger.Clone()
 }
 
-// Fatal logs to the FATAL severity logs to the default Logger, then calls os.Exit(1).
+// Emergency logs to the EMERGENCY severity logs to the default Logger.
+func Emergency(args ...interface{}) {
+	defaultLogger.log(SeverityEmergency, args...)
+}
+
+// Emergencyf logs to the EMERGENCY severity logs to the default Logger.
+func Emergencyf(format string, args ...interface{}) {
+	defaultLogger.logf(SeverityEmergency, format, args...)
+}
+
+// Emergencyln logs to the EMERGENCY severity logs to the default Logger.
+func Emergencyln(args ...interface{}) {
+	defaultLogger.logln(SeverityEmergency, args...)
+}
+
+// Alert logs to the ALERT severity logs to the default Logger.
+func Alert(args ...interface{}) {
+	defaultLogger.log(SeverityAlert, args...)
+}
+
+// Alertf logs to the ALERT severity logs to the default Logger.
+func Alertf(format string, args ...interface{}) {
+	defaultLogger.logf(SeverityAlert, format, args...)
+}
+
+// Alertln logs to the ALERT severity logs to the default Logger.
+func Alertln(args ...interface{}) {
+	defaultLogger.logln(SeverityAlert, args...)
+}
+
+// Fatal logs to the FATAL severity logs to the default Logger, then calls os.Exit with the default Logger's
+// fatal exit code.
 func Fatal(args ...interface{}) {
 	defaultLogger.log(SeverityFatal, args...)
-	os.Exit(1)
+	_ = fatalSync()
+	os.Exit(defaultLogger.getFatalExitCode())
 }
 
-// Fatalf logs to the FATAL severity logs to the default Logger, then calls os.Exit(1).
+// Fatalf logs to the FATAL severity logs to the default Logger, then calls os.Exit with the default Logger's
+// fatal exit code.
 func Fatalf(format string, args ...interface{}) {
 	defaultLogger.logf(SeverityFatal, format, args...)
-	os.Exit(1)
+	_ = fatalSync()
+	os.Exit(defaultLogger.getFatalExitCode())
 }
 
-// Fatalln logs to the FATAL severity logs to the default Logger, then calls os.Exit(1).
+// Fatalln logs to the FATAL severity logs to the default Logger, then calls os.Exit with the default Logger's
+// fatal exit code.
 func Fatalln(args ...interface{}) {
 	defaultLogger.logln(SeverityFatal, args...)
-	os.Exit(1)
+	_ = fatalSync()
+	os.Exit(defaultLogger.getFatalExitCode())
+}
+
+// FatalCode logs to the FATAL severity logs to the default Logger, then calls os.Exit with the given code.
+func FatalCode(code int, args ...interface{}) {
+	defaultLogger.log(SeverityFatal, args...)
+	_ = fatalSync()
+	os.Exit(code)
+}
+
+// FatalCodef logs to the FATAL severity logs to the default Logger, then calls os.Exit with the given code.
+func FatalCodef(code int, format string, args ...interface{}) {
+	defaultLogger.logf(SeverityFatal, format, args...)
+	_ = fatalSync()
+	os.Exit(code)
+}
+
+// FatalCodeln logs to the FATAL severity logs to the default Logger, then calls os.Exit with the given code.
+func FatalCodeln(code int, args ...interface{}) {
+	defaultLogger.logln(SeverityFatal, args...)
+	_ = fatalSync()
+	os.Exit(code)
+}
+
+// Panic logs to the PANIC severity logs to the default Logger, then panics with the message.
+func Panic(args ...interface{}) {
+	defaultLogger.log(SeverityPanic, args...)
+	panic(fmt.Sprint(args...))
+}
+
+// Panicf logs to the PANIC severity logs to the default Logger, then panics with the message.
+func Panicf(format string, args ...interface{}) {
+	panic(defaultLogger.logf(SeverityPanic, format, args...))
+}
+
+// Panicln logs to the PANIC severity logs to the default Logger, then panics with the message.
+func Panicln(args ...interface{}) {
+	defaultLogger.logln(SeverityPanic, args...)
+	panic(fmt.Sprintln(args...))
+}
+
+// DPanic logs to the PANIC severity logs to the default Logger, then panics with the message if the default Logger
+// is in development mode.
+func DPanic(args ...interface{}) {
+	defaultLogger.log(SeverityPanic, args...)
+	if defaultLogger.isDevelopment() {
+		panic(fmt.Sprint(args...))
+	}
+}
+
+// DPanicf logs to the PANIC severity logs to the default Logger, then panics with the message if the default Logger
+// is in development mode.
+func DPanicf(format string, args ...interface{}) {
+	message := defaultLogger.logf(SeverityPanic, format, args...)
+	if defaultLogger.isDevelopment() {
+		panic(message)
+	}
+}
+
+// DPanicln logs to the PANIC severity logs to the default Logger, then panics with the message if the default Logger
+// is in development mode.
+func DPanicln(args ...interface{}) {
+	defaultLogger.logln(SeverityPanic, args...)
+	if defaultLogger.isDevelopment() {
+		panic(fmt.Sprintln(args...))
+	}
+}
+
+// Critical logs to the CRITICAL severity logs to the default Logger.
+func Critical(args ...interface{}) {
+	defaultLogger.log(SeverityCritical, args...)
+}
+
+// Criticalf logs to the CRITICAL severity logs to the default Logger.
+func Criticalf(format string, args ...interface{}) {
+	defaultLogger.logf(SeverityCritical, format, args...)
+}
+
+// Criticalln logs to the CRITICAL severity logs to the default Logger.
+func Criticalln(args ...interface{}) {
+	defaultLogger.logln(SeverityCritical, args...)
 }
 
 // Error logs to the ERROR severity logs to the default Logger.
@@ -66,6 +189,18 @@ func Errorln(args ...interface{}) {
 	defaultLogger.logln(SeverityError, args...)
 }
 
+// Errore logs to the ERROR severity logs with the given error to the default Logger.
+// If no argument is given, the message is the error message.
+func Errore(err error, args ...interface{}) {
+	defaultLogger.loge(SeverityError, err, args...)
+}
+
+// ErrorS logs to the ERROR severity logs with the given error, constant message and keys and values of Field
+// to the default Logger.
+func ErrorS(err error, msg string, kvs ...interface{}) {
+	defaultLogger.logs(SeverityError, err, msg, kvs...)
+}
+
 // Warning logs to the WARNING severity logs to the default Logger.
 func Warning(args ...interface{}) {
 	defaultLogger.log(SeverityWarning, args...)
@@ -81,6 +216,33 @@ func Warningln(args ...interface{}) {
 	defaultLogger.logln(SeverityWarning, args...)
 }
 
+// Warninge logs to the WARNING severity logs with the given error to the default Logger.
+// If no argument is given, the message is the error message.
+func Warninge(err error, args ...interface{}) {
+	defaultLogger.loge(SeverityWarning, err, args...)
+}
+
+// WarningS logs to the WARNING severity logs with the given constant message and keys and values of Field
+// to the default Logger.
+func WarningS(msg string, kvs ...interface{}) {
+	defaultLogger.logs(SeverityWarning, nil, msg, kvs...)
+}
+
+// Notice logs to the NOTICE severity logs to the default Logger.
+func Notice(args ...interface{}) {
+	defaultLogger.log(SeverityNotice, args...)
+}
+
+// Noticef logs to the NOTICE severity logs to the default Logger.
+func Noticef(format string, args ...interface{}) {
+	defaultLogger.logf(SeverityNotice, format, args...)
+}
+
+// Noticeln logs to the NOTICE severity logs to the default Logger.
+func Noticeln(args ...interface{}) {
+	defaultLogger.logln(SeverityNotice, args...)
+}
+
 // Info logs to the INFO severity logs to the default Logger.
 func Info(args ...interface{}) {
 	defaultLogger.log(SeverityInfo, args...)
@@ -96,6 +258,12 @@ func Infoln(args ...interface{}) {
 	defaultLogger.logln(SeverityInfo, args...)
 }
 
+// InfoS logs to the INFO severity logs with the given constant message and keys and values of Field
+// to the default Logger.
+func InfoS(msg string, kvs ...interface{}) {
+	defaultLogger.logs(SeverityInfo, nil, msg, kvs...)
+}
+
 // Debug logs to the DEBUG severity logs to the default Logger.
 func Debug(args ...interface{}) {
 	defaultLogger.log(SeverityDebug, args...)
@@ -111,6 +279,12 @@ func Debugln(args ...interface{}) {
 	defaultLogger.logln(SeverityDebug, args...)
 }
 
+// DebugS logs to the DEBUG severity logs with the given constant message and keys and values of Field
+// to the default Logger.
+func DebugS(msg string, kvs ...interface{}) {
+	defaultLogger.logs(SeverityDebug, nil, msg, kvs...)
+}
+
 // Print logs a log which has the default Logger's print severity to the default Logger.
 func Print(args ...interface{}) {
 	defaultLogger.log(severityPrint, args...)
@@ -126,6 +300,11 @@ func Println(args ...interface{}) {
 	defaultLogger.logln(severityPrint, args...)
 }
 
+// Enabled reports whether the default Logger logs with the given severity.
+func Enabled(severity Severity) bool {
+	return defaultLogger.enabled(severity, 1)
+}
+
 // SetOutput sets the default Logger's output.
 // It returns the default Logger.
 // By default, the default TextOutput.
@@ -141,6 +320,26 @@ func SetSeverity(severity Severity) *Logger {
 	return defaultLogger.SetSeverity(severity)
 }
 
+// SetFatalFlushTimeout sets the maximum duration to wait for flushing by the Fatal methods of the default Logger
+// before exiting. See Logger.SetFatalFlushTimeout.
+// It returns the default Logger.
+func SetFatalFlushTimeout(timeout time.Duration) *Logger {
+	return defaultLogger.SetFatalFlushTimeout(timeout)
+}
+
+// SetFatalExitCode sets the exit code which is used by the Fatal methods of the default Logger.
+// It returns the default Logger.
+// By default, 1.
+func SetFatalExitCode(code int) *Logger {
+	return defaultLogger.SetFatalExitCode(code)
+}
+
+// SetDevelopment sets whether the default Logger is in development mode. See Logger.SetDevelopment.
+// It returns the default Logger.
+func SetDevelopment(development bool) *Logger {
+	return defaultLogger.SetDevelopment(development)
+}
+
 // SetVerbose sets the default Logger's verbose.
 // It returns the default Logger.
 // By default, 0.
@@ -172,9 +371,17 @@ func SetStackTraceSize(stackTraceSize int) *Logger {
 	return defaultLogger.SetStackTraceSize(stackTraceSize)
 }
 
+// SetOnLog sets a function to call for every log which passes the severity and verbosity filters of the default Logger.
+// The function is called independently of the output, and it must not modify the log.
+// It returns the default Logger.
+// By default, nil.
+func SetOnLog(f func(log *Log)) *Logger {
+	return defaultLogger.SetOnLog(f)
+}
+
 // V clones the default Logger with the given verbosity if the default Logger's verbose is greater or equal to the given verbosity, otherwise returns nil.
 func V(verbosity Verbose) *Logger {
-	return defaultLogger.V(verbosity)
+	return defaultLogger.v(verbosity, 1)
 }
 
 // WithVerbosity clones the default Logger with the given verbosity.
@@ -217,6 +424,12 @@ func WithFields(fields ...Field) *Logger {
 	return defaultLogger.WithFields(fields...)
 }
 
+// WithGroup clones the default Logger by starting a group with the given name.
+// The fields which are added after it are nested under the group.
+func WithGroup(name string) *Logger {
+	return defaultLogger.WithGroup(name)
+}
+
 // WithFieldKeyVals clones the default Logger with given keys and values of Field.
 func WithFieldKeyVals(kvs ...interface{}) *Logger {
 	return defaultLogger.WithFieldKeyVals(kvs...)
@@ -233,6 +446,39 @@ func WithCtxErrVerbosity(verbosity Verbose) *Logger {
 	return defaultLogger.WithCtxErrVerbosity(verbosity)
 }
 
+// WithErrVerbosityFunc clones the default Logger with error verbosity function.
+// If the log has an error and the function returns true for the error, the returned value is used as verbosity.
+// It allows demoting arbitrary error classes such as io.EOF to higher verbosity.
+func WithErrVerbosityFunc(f func(err error) (verbosity Verbose, ok bool)) *Logger {
+	return defaultLogger.WithErrVerbosityFunc(f)
+}
+
+// WithCallerSkip clones the default Logger by adding the given number of stack frames to skip while detecting the caller.
+// It is useful for wrapping Logger by other logging APIs.
+func WithCallerSkip(skip int) *Logger {
+	return defaultLogger.WithCallerSkip(skip)
+}
+
+// WithSampling clones the default Logger with the given Sampler.
+// The logs which aren't kept by the Sampler are dropped before they are built.
+func WithSampling(sampler *Sampler) *Logger {
+	return defaultLogger.WithSampling(sampler)
+}
+
+// Writer creates a new LineWriter which logs every written line by the given severity to the default Logger.
+// It is useful for external code expecting an io.Writer, such as exec.Cmd stdout and stderr.
+// If severity is invalid, it uses SeverityInfo.
+func Writer(severity Severity) *LineWriter {
+	return defaultLogger.Writer(severity)
+}
+
+// StdLogger returns a standard library logger which logs every written line by the given severity to the default Logger.
+// It is useful for APIs which only accept *log.Logger such as http.Server.ErrorLog.
+// If severity is invalid, it uses SeverityInfo.
+func StdLogger(severity Severity) *log.Logger {
+	return defaultLogger.StdLogger(severity)
+}
+
 var (
 	defaultTextOutput       = NewTextOutput(defaultTextOutputWriter, TextOutputFlagDefault)
 	defaultTextOutputWriter = os.Stderr
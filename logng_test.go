@@ -1,9 +1,29 @@
 package logng_test
 
 import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/goinsane/logng/v2"
@@ -140,6 +160,20 @@ func ExampleSetSeverity() {
 	// ERROR - this is error log.
 }
 
+func ExampleSeverityFromString() {
+	for _, name := range []string{"warn", "ERR", "information", "crit", "verbose"} {
+		severity, err := logng.SeverityFromString(name)
+		fmt.Println(severity, err)
+	}
+
+	// Output:
+	// WARNING <nil>
+	// ERROR <nil>
+	// INFO <nil>
+	// CRITICAL <nil>
+	// NONE unknown severity
+}
+
 func ExampleSetVerbose() {
 	// set logng for this example.
 	logng.Reset()
@@ -153,57 +187,2087 @@ func ExampleSetVerbose() {
 	logng.V(3).Info("this is info log, verbosity 3. it won't be shown.")
 
 	// Output:
-	// INFO - this is info log, verbosity 0.
-	// INFO - this is info log, verbosity 1.
+	// INFO - this is info log, verbosity 0.
+	// INFO - this is info log, verbosity 1.
+}
+
+func ExampleSetPrintSeverity() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+
+	logng.SetPrintSeverity(logng.SeverityWarning)
+	logng.Print("this is the log.")
+
+	// Output:
+	// WARNING - this is the log.
+}
+
+func ExampleWithTime() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagDefault)
+
+	logng.WithTime(testTime).Info("this is info log with the given time.")
+
+	// Output:
+	// 2010/11/12 13:14:15 INFO - this is info log with the given time.
+}
+
+func ExampleTextOutputFlagRFC3339() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagRFC3339 | logng.TextOutputFlagUTC | logng.TextOutputFlagSeverity)
+
+	tm := time.Date(2010, 11, 12, 13, 14, 15, 123456789, time.UTC)
+	logng.WithTime(tm).Info("this is info log with RFC3339 time.")
+
+	logng.SetTextOutputFlags(logng.TextOutputFlagRFC3339Milli | logng.TextOutputFlagUTC | logng.TextOutputFlagSeverity)
+	logng.WithTime(tm).Info("this is info log with RFC3339 time in milliseconds.")
+
+	// Output:
+	// 2010-11-12T13:14:15Z INFO - this is info log with RFC3339 time.
+	// 2010-11-12T13:14:15.123Z INFO - this is info log with RFC3339 time in milliseconds.
+}
+
+func ExampleWithPrefix() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+
+	logng.WithPrefix("prefix1: ").WithPrefix("prefix2: ").Info("this is info log.")
+
+	// Output:
+	// INFO - prefix1: prefix2: this is info log.
+}
+
+func ExampleWithSuffix() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+
+	logng.WithSuffix(" :suffix1").WithSuffix(" :suffix2").Info("this is info log.")
+
+	// Output:
+	// INFO - this is info log. :suffix2 :suffix1
+}
+
+func ExampleLog_Format() {
+	log := &logng.Log{
+		Message:  []byte("this is warning log."),
+		Severity: logng.SeverityWarning,
+		Time:     testTime,
+	}
+	fmt.Printf("%s\n", log)
+	fmt.Printf("%d\n", log)
+
+	// Output:
+	// 2010/11/12 13:14:15 WARNING - this is warning log.
+	// %!d(*logng.Log=2010/11/12 13:14:15 WARNING - this is warning log.)
+}
+
+func ExampleTextOutput_Healthy() {
+	output := logng.NewTextOutput(errWriter{}, logng.TextOutputFlagSeverity)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	logger.Info("this is info log. it can't be written.")
+	fmt.Println(output.Healthy(), output.Err())
+
+	output.SetWriter(io.Discard)
+	logger.Info("this is info log. it will be written.")
+	fmt.Println(output.Healthy(), output.Err())
+
+	// Output:
+	// false unable to write to writer: write error
+	// true unable to write to writer: write error
+}
+
+func ExampleBufferedWriter() {
+	w := logng.NewBufferedWriter(os.Stdout, 4096, time.Second)
+	logger := logng.NewLogger(logng.NewTextOutput(w, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+
+	logger.Info("this is info log. it will be shown after flushing.")
+	fmt.Println("before flushing.")
+	_ = w.Close()
+
+	// Output:
+	// before flushing.
+	// INFO - this is info log. it will be shown after flushing.
+}
+
+func ExampleMultiOutput() {
+	logng.SetErrorHandler(func(err error) {
+		fmt.Println("error:", err)
+	})
+	defer logng.SetErrorHandler(nil)
+
+	output := logng.MultiOutput(
+		panicOutput{},
+		logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity),
+	)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log. it will be shown despite the panic.")
+
+	// Output:
+	// error: output panic: unable to log
+	// INFO - this is info log. it will be shown despite the panic.
+}
+
+func ExampleTimeoutOutput() {
+	logng.SetErrorHandler(func(err error) {
+		fmt.Println("error:", err)
+	})
+	defer logng.SetErrorHandler(nil)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	output := logng.TimeoutOutput(blockingOutput(release), 10*time.Millisecond)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log. it will be dropped.")
+
+	// Output:
+	// error: output timeout: log dropped after 10ms
+}
+
+func ExampleLogger_SetOnLog() {
+	logger := logng.NewLogger(nil, logng.SeverityInfo, 0)
+	logger.SetOnLog(func(log *logng.Log) {
+		fmt.Printf("%s: %s\n", log.Severity, log.Message)
+	})
+
+	logger.Debug("this is debug log. it won't be observed.")
+	logger.Warning("this is warning log.")
+
+	// Output:
+	// WARNING: this is warning log.
+}
+
+func ExampleWithErrVerbosityFunc() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetTextOutputWriter(os.Stdout)
+	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+
+	logger := logng.WithErrVerbosityFunc(func(err error) (logng.Verbose, bool) {
+		if errors.Is(err, io.EOF) {
+			return 1, true
+		}
+		return 0, false
+	})
+	logger.Errorf("read failed: %w", io.EOF)
+	logger.Errorf("read failed: %w", io.ErrUnexpectedEOF)
+
+	// Output:
+	// ERROR - read failed: unexpected EOF
+}
+
+func ExampleTextOutput_SetSeverityLabels() {
+	output := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagDate|logng.TextOutputFlagUTC|logng.TextOutputFlagSeverity)
+	output.SetSeverityLabels(map[logng.Severity]string{
+		logng.SeverityWarning: "UYARI",
+	})
+	output.SetLocale("tr-TR")
+	output.SetTimeFormatter(func(tm time.Time, locale string) string {
+		return fmt.Sprintf("[%s] %02d.%02d.%04d", locale, tm.Day(), tm.Month(), tm.Year())
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	tm := time.Date(2010, 11, 12, 13, 14, 15, 0, time.UTC)
+	logger.WithTime(tm).Warning("this is warning log.")
+	logger.WithTime(tm).Info("this is info log.")
+
+	// Output:
+	// [tr-TR] 12.11.2010 UYARI - this is warning log.
+	// [tr-TR] 12.11.2010 INFO - this is info log.
+}
+
+func ExampleLineWriter() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	w := logng.NewLineWriter(logger, logng.SeverityInfo)
+	w.AddSeverityPattern(regexp.MustCompile(`^ERROR`), logng.SeverityError)
+
+	_, _ = io.WriteString(w, "starting\nERROR unable to ")
+	_, _ = io.WriteString(w, "connect\nretrying")
+	_ = w.Close()
+
+	// Output:
+	// INFO - starting
+	// ERROR - ERROR unable to connect
+	// INFO - retrying
+}
+
+func ExampleAccessLogOutput() {
+	logger := logng.NewLogger(logng.NewAccessLogOutput(os.Stdout), logng.SeverityInfo, 0)
+
+	tm := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	logger.WithTime(tm).WithFieldKeyVals(
+		logng.FieldKeyHTTPRemoteAddr, "127.0.0.1:54321",
+		logng.FieldKeyHTTPMethod, "GET",
+		logng.FieldKeyHTTPPath, "/index.html",
+		logng.FieldKeyHTTPProto, "HTTP/1.0",
+		logng.FieldKeyHTTPStatus, 200,
+		logng.FieldKeyHTTPBytes, 2326,
+		logng.FieldKeyHTTPUserAgent, "Mozilla/4.08",
+	).Info()
+
+	// Output:
+	// 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.0" 200 2326 "-" "Mozilla/4.08"
+}
+
+func ExampleLogger_StdLogger() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity|logng.TextOutputFlagShortFile),
+		logng.SeverityInfo, 0)
+
+	stdLogger := logger.StdLogger(logng.SeverityWarning)
+	stdLogger.Printf("this is warning log.\nit has %d lines.", 2)
+
+	// Output:
+	// WARNING - logng_test.go:428 - this is warning log.
+	// WARNING - logng_test.go:428 - it has 2 lines.
+}
+
+func ExampleKafkaOutput() {
+	output := logng.NewKafkaOutput(printProducer{}, "logs", logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields)
+	output.SetKeyField("user")
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", "alice").Info("login")
+	logger.Warning("disk is almost full")
+	_ = output.Close()
+
+	// Output:
+	// logs alice {"severity":"INFO","message":"login","_user":"alice"}
+	// logs  {"severity":"WARNING","message":"disk is almost full"}
+}
+
+func ExampleKafkaOutput_SetMaxPending() {
+	output := logng.NewKafkaOutput(printProducer{}, "logs", logng.JSONOutputFlagSeverity)
+	output.SetFlushInterval(0).SetMaxPending(2)
+	output.SetOnDeliveryFailure(func(msgs []logng.KafkaMessage, err error) {
+		fmt.Printf("%d message dropped: %v\n", len(msgs), err)
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// 1 message dropped: unable to queue log: queue full
+	// logs  {"severity":"INFO","message":"first"}
+	// logs  {"severity":"INFO","message":"second"}
+}
+
+func ExampleNATSOutput() {
+	output := logng.NewNATSOutput(printPublisher{}, "logs.{severity}", logng.JSONOutputFlagSeverity)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Error("connection refused")
+	output.SetEncoding(logng.NATSEncodingMsgPack)
+	logger.Info("ok")
+
+	// Output:
+	// logs.error {"severity":"ERROR","message":"connection refused"}
+	// logs.info 82a87365766572697479a4494e464fa76d657373616765a26f6b
+}
+
+type exampleOTLPExporter func(ctx context.Context, request []byte) error
+
+func (f exampleOTLPExporter) Export(ctx context.Context, request []byte) error {
+	return f(ctx, request)
+}
+
+func ExampleOTLPOutput() {
+	observed := regexp.MustCompile(`"observedTimeUnixNano":"[0-9]+",`)
+	output := logng.NewOTLPOutput(exampleOTLPExporter(func(ctx context.Context, request []byte) error {
+		fmt.Printf("%s\n", observed.ReplaceAll(request, nil))
+		return nil
+	}), "api")
+	output.Log(&logng.Log{
+		Message:  []byte("disk is almost full."),
+		Severity: logng.SeverityWarning,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Fields: logng.Fields{
+			{Key: logng.FieldKeyTraceID, Value: "4bf92f3577b34da6a3ce929d0e0e4736"},
+			{Key: "usage", Value: 91},
+		},
+	})
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// {"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scopeLogs":[{"scope":{"name":"github.com/goinsane/logng"},"logRecords":[{"timeUnixNano":"1704164645000000000","severityNumber":13,"severityText":"WARNING","body":{"stringValue":"disk is almost full."},"attributes":[{"key":"usage","value":{"intValue":"91"}}],"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}]}]}]}
+}
+
+func ExampleOTLPOutput_SetMaxPending() {
+	record := regexp.MustCompile(`"stringValue":"([a-z]+)"\}`)
+	output := logng.NewOTLPOutput(exampleOTLPExporter(func(ctx context.Context, request []byte) error {
+		for _, m := range record.FindAllSubmatch(request, -1) {
+			fmt.Printf("exported %s.\n", m[1])
+		}
+		return nil
+	}), "")
+	output.SetFlushInterval(0).SetMaxPending(2).SetOnError(func(err error) {
+		fmt.Printf("error: %v\n", err)
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Println("healthy:", output.Healthy())
+
+	// Output:
+	// error: unable to queue log: queue full
+	// exported first.
+	// exported second.
+	// healthy: true
+}
+
+func ExampleOTLPOutput_nanAttribute() {
+	attr := regexp.MustCompile(`\{"key":"ratio","value":\{[^}]*\}\}`)
+	output := logng.NewOTLPOutput(exampleOTLPExporter(func(ctx context.Context, request []byte) error {
+		fmt.Printf("%s\n", attr.Find(request))
+		return nil
+	}), "api")
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("ratio", float32(math.NaN())).Info("this is info log.")
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// {"key":"ratio","value":{"stringValue":"NaN"}}
+}
+
+// exampleFormatLog returns a log with a fixed time, error and trace fields, for the examples of the format outputs.
+func exampleFormatLog() *logng.Log {
+	return &logng.Log{
+		Severity: logng.SeverityError,
+		Message:  []byte("payment failed."),
+		Error:    errors.New("card declined"),
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Fields: logng.Fields{
+			{Key: logng.FieldKeyTraceID, Value: "4bf92f35"},
+			{Key: logng.FieldKeySpanID, Value: "00f067aa"},
+			{Key: "user", Value: "alice"},
+		},
+	}
+}
+
+func ExampleCloudLoggingOutput() {
+	output := logng.NewCloudLoggingOutput(os.Stdout, "my-project")
+	output.Log(exampleFormatLog())
+
+	// Output:
+	// {"severity":"ERROR","message":"payment failed.","time":"2024-01-02T03:04:05Z","logging.googleapis.com/trace":"projects/my-project/traces/4bf92f35","logging.googleapis.com/spanId":"00f067aa","user":"alice","error":"card declined"}
+}
+
+func ExampleSentryOutput() {
+	event := regexp.MustCompile(`(?m)^\{"event_id":"[0-9a-f]+",(.*)$`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("%s {%s\n", r.URL.Path, event.FindSubmatch(body)[1])
+	}))
+	defer srv.Close()
+
+	output, err := logng.NewSentryOutput("http://public@" + srv.Listener.Addr().String() + "/1")
+	if err != nil {
+		panic(err)
+	}
+	output.SetTagKeys("user").SetEnvironment("production").SetServerName("host1")
+	output.Log(exampleFormatLog())
+	output.Log(&logng.Log{Severity: logng.SeverityInfo, Message: []byte("below the minimum severity.")})
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// /api/1/envelope/ {"timestamp":"2024-01-02T03:04:05Z","level":"error","platform":"go","logger":"logng","server_name":"host1","environment":"production","message":{"formatted":"payment failed."},"exception":[{"type":"*errors.errorString","value":"card declined"}],"tags":{"user":"alice"},"extra":{"span_id":"00f067aa","trace_id":"4bf92f35"}}
+}
+
+func ExampleWebhookOutput() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("%s\n", body)
+	}))
+	defer srv.Close()
+
+	// the logs in the batch window are posted as a single message, and the lines over the maximum are summarized.
+	output := logng.NewWebhookOutput(srv.URL, logng.WebhookFormatSlack).
+		SetTemplate(template.Must(template.New("").Parse("{{.Severity}}: {{.Message}}"))).
+		SetBatchWindow(time.Hour).
+		SetMaxLines(2)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("it won't be posted.")
+	logger.Error("disk is full.")
+	logger.Error("database is down.")
+	logger.Error("payment failed.")
+	_ = output.Close()
+
+	// Output:
+	// {"text":"ERROR: disk is full.\nERROR: database is down.\n... and 1 more"}
+}
+
+func ExampleHTTPOutput_Close() {
+	requested := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		requested <- struct{}{}
+	}))
+	defer srv.Close()
+
+	output := logng.NewHTTPOutput(srv.URL, logng.HTTPOutputFormatNDJSON, logng.JSONOutputFlagSeverity).
+		SetBatchSize(1).
+		SetRetry(3, time.Hour, time.Hour).
+		SetOnDrop(func(logs [][]byte, err error) {
+			fmt.Printf("dropped %d logs: %v\n", len(logs), err)
+		})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first.")
+	<-requested
+
+	// closing aborts the backoff, instead of waiting an hour to retry.
+	start := time.Now()
+	_ = output.Close()
+	fmt.Println(time.Since(start) < time.Minute)
+
+	// Output:
+	// dropped 1 logs: unexpected status code 503
+	// true
+}
+
+func ExampleHTTPOutput() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("%s\n", body)
+	}))
+	defer server.Close()
+
+	output := logng.NewHTTPOutput(server.URL, logng.HTTPOutputFormatJSONArray, logng.JSONOutputFlagSeverity)
+	output.SetBatchSize(2).SetFlushInterval(0)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// [{"severity":"INFO","message":"first"},{"severity":"INFO","message":"second"}]
+	// [{"severity":"INFO","message":"third"}]
+}
+
+func ExampleSQLOutput() {
+	db, err := sql.Open("logng-example", "")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	output, err := logng.NewSQLOutput(db, "logs", logng.SQLColumns{Message: "message", Severity: "severity"},
+		logng.SQLPlaceholderQuestion)
+	if err != nil {
+		panic(err)
+	}
+	output.SetBatchSize(2).SetFlushInterval(0)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first")
+	logger.Warning("second")
+	if err := output.Flush(); err != nil {
+		panic(err)
+	}
+	logger.Error("failed")
+	if err := output.Close(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("healthy:", output.Healthy())
+
+	// Output:
+	// exec [first INFO]
+	// exec [second WARNING]
+	// commit
+	// exec [failed ERROR]
+	// rollback
+	// unable to insert 1 logs: exec failed
+	// healthy: false
+}
+
+func ExampleRingBufferOutput() {
+	ring := logng.NewRingBufferOutput(2)
+	logger := logng.NewLogger(ring, logng.SeverityDebug, 0)
+	logger.Debug("connecting")
+	logger.Debug("connected")
+	logger.Debug("sending request")
+	logger.Error("request failed")
+
+	ring.Dump(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity))
+
+	// Output:
+	// DEBUG - sending request
+	// ERROR - request failed
+}
+
+func ExampleTriggerOutput() {
+	output := logng.NewTriggerOutput(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityError, time.Minute)
+	logger := logng.NewLogger(output, logng.SeverityDebug, 0)
+	logger.Debug("opening file")
+	logger.Debug("reading file")
+	logger.Error("unable to read file")
+	logger.Debug("retrying")
+
+	// Output:
+	// DEBUG - opening file
+	// DEBUG - reading file
+	// ERROR - unable to read file
+}
+
+func ExampleECSOutput() {
+	output := logng.NewECSOutput(os.Stdout).SetServiceName("payments")
+	output.Log(exampleFormatLog())
+
+	// Output:
+	// {"@timestamp":"2024-01-02T03:04:05.000Z","log.level":"error","message":"payment failed.","ecs.version":"1.6.0","service.name":"payments","error":{"type":"*errors.errorString","message":"card declined"},"trace.id":"4bf92f35","span.id":"00f067aa","labels":{"user":"alice"}}
+}
+
+func ExampleDatadogOutput() {
+	// the hexadecimal trace and span IDs are converted to the decimal IDs of Datadog.
+	output := logng.NewDatadogOutput(os.Stdout, "payments").SetCorrelation("payments", "prod", "1.2.3")
+	output.Log(exampleFormatLog())
+
+	// Output:
+	// {"status":"error","message":"payment failed.","timestamp":"2024-01-02T03:04:05.000Z","logger.name":"payments","error.kind":"*errors.errorString","error.message":"card declined","dd.service":"payments","dd.env":"prod","dd.version":"1.2.3","dd.trace_id":"1274621749","dd.span_id":"15755178","user":"alice"}
+}
+
+func ExampleMsgPackOutput() {
+	output := logng.NewMsgPackOutput(hexWriter{})
+	output.Log(&logng.Log{
+		Severity: logng.SeverityInfo,
+		Message:  []byte("ok"),
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Fields:   logng.Fields{{Key: "n", Value: 1}},
+	})
+
+	// a map of 4: message "ok", severity "INFO", time as the timestamp extension, and fields {n: 1}.
+
+	// Output:
+	// 84a76d657373616765a26f6ba87365766572697479a4494e464fa474696d65c70cff000000000000000065937d25a66669656c647381a16e01
+}
+
+func ExampleCBOROutput() {
+	output := logng.NewCBOROutput(hexWriter{}, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("n", -2).Info("hi")
+
+	// Output:
+	// a368736576657269747964494e464f676d657373616765626869625f6e21
+}
+
+func ExampleCEFOutput() {
+	output := logng.NewCEFOutput(os.Stdout, "Acme", "Gateway", "1.0")
+	output.SetExtensionMapping(map[string]string{logng.FieldKeyHTTPRemoteAddr: "src"})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger = logger.WithTime(time.Unix(1289567655, 0))
+	logger.WithFieldKeyVals(logng.FieldKeyHTTPRemoteAddr, "10.0.0.1", "query", "a=b").Warning("login failed | bad password")
+	output.SetLEEF(true)
+	logger.WithFieldKeyVals(logng.FieldKeyHTTPRemoteAddr, "10.0.0.1").Warning("login failed")
+
+	// Output:
+	// CEF:0|Acme|Gateway|1.0|log|login failed \| bad password|5|rt=1289567655000 src=10.0.0.1 query=a\=b
+	// LEEF:1.0|Acme|Gateway|1.0|log|devTime=1289567655000	devTimeFormat=epoch	sev=5	msg=login failed	src=10.0.0.1
+}
+
+func ExampleTemplateOutput() {
+	tmpl := template.Must(template.New("log").Parse(
+		`{{.Time.UTC.Format "15:04:05"}} [{{.Severity}}] {{.Message}}{{range .Fields}} {{.Key}}={{.Value}}{{end}}`))
+	logger := logng.NewLogger(logng.NewTemplateOutput(os.Stdout, tmpl), logng.SeverityInfo, 0)
+	logger = logger.WithTime(time.Unix(1289567655, 0))
+	logger.WithFieldKeyVals("user", "john", "attempt", 3).Warning("login failed")
+
+	// Output:
+	// 13:14:15 [WARNING] login failed user=john attempt=3
+}
+
+func ExampleConsoleOutput() {
+	start := time.Unix(1289567655, 0)
+	output := logng.NewConsoleOutput(os.Stdout).SetStartTime(start).SetMessageWidth(30)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithTime(start.Add(1234*time.Millisecond)).WithFieldKeyVals("user", "john doe").
+		Warningf("login failed: %w", errors.New("bad password"))
+	logger.WithTime(start.Add(2 * time.Second)).Info("retrying\nin a second")
+
+	// Output:
+	//      1.234s WRN logng_test.go:806    login failed: bad password     user="john doe" error="bad password"
+	//      2.000s INF logng_test.go:807    retrying
+	//                                      in a second
+}
+
+func ExampleSSEOutput() {
+	output := logng.NewSSEOutput(logng.JSONOutputFlagSeverity | logng.JSONOutputFlagFields)
+	server := httptest.NewServer(output)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?severity=warning&field=user=john")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	_, _ = r.ReadString('\n')
+	_, _ = r.ReadString('\n')
+
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", "john").Info("login succeeded")
+	logger.WithFieldKeyVals("user", "jane").Warning("login failed")
+	logger.WithFieldKeyVals("user", "john").Warning("login failed")
+	line, _ := r.ReadString('\n')
+	fmt.Print(line)
+
+	// Output:
+	// data: {"severity":"WARNING","message":"login failed","_user":"john"}
+}
+
+func ExampleNewLoggerWithOptions() {
+	logger, err := logng.NewLoggerWithOptions(
+		logng.OptionSeverity(logng.SeverityDebug),
+		logng.OptionOutputConfig(logng.OutputConfig{
+			Writer:    os.Stdout,
+			Format:    "json",
+			JSONFlags: logng.JSONOutputFlagSeverity | logng.JSONOutputFlagFields,
+		}),
+		logng.OptionFields(map[string]interface{}{"service": "api", "env": "prod"}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	logger.Debug("this is debug log.")
+
+	// Output:
+	// {"severity":"DEBUG","message":"this is debug log.","_env":"prod","_service":"api"}
+}
+
+func ExampleParseConfig() {
+	logger, err := logng.ParseConfig([]byte(`{
+		"severity": "debug",
+		"fields": {"service": "api"},
+		"output": {"path": "stdout", "format": "json", "json_flags": "severity|fields"}
+	}`))
+	if err != nil {
+		panic(err)
+	}
+	logger.Debug("this is debug log.")
+
+	// Output:
+	// {"severity":"DEBUG","message":"this is debug log.","_service":"api"}
+}
+
+func ExampleLoadConfig() {
+	dir, err := ioutil.TempDir("", "logng-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logng.json")
+	err = ioutil.WriteFile(path, []byte(`{
+		"severity": "warning",
+		"output": {"path": "stdout", "format": "text", "text_flags": "severity"}
+	}`), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	logger, err := logng.LoadConfig(path)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("this is info log. it won't be shown.")
+	logger.Warning("this is warning log.")
+
+	_, err = logng.LoadConfig(filepath.Join(dir, "missing.json"))
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+
+	// Output:
+	// WARNING - this is warning log.
+	// true
+}
+
+func ExampleLogger_RegisterFlags() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	fs := flag.NewFlagSet("example", flag.ContinueOnError)
+	logger.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-log-level=debug", "-v=2"}); err != nil {
+		panic(err)
+	}
+	logger.V(2).Debug("this is debug log, verbosity 2.")
+	fmt.Println(fs.Lookup("log-level").Usage)
+
+	// Output:
+	// DEBUG - this is debug log, verbosity 2.
+	// log severity: none, emergency (emerg), alert, fatal, panic (dpanic), critical (crit), error (err), warning (warn), notice, info (information, informational), debug (dbg, trace), or its numeric value
+}
+
+func ExampleLogger_SetVModule() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	if err := logger.SetVModule("other=3,logng_test=2"); err != nil {
+		panic(err)
+	}
+	logger.V(1).Info("this is info log, verbosity 1.")
+	logger.V(2).Info("this is info log, verbosity 2.")
+	logger.WithVerbosity(3).Info("this is info log, verbosity 3. it won't be shown.")
+	logger.V(3).Info("this is info log, verbosity 3. it won't be shown.")
+
+	// Output:
+	// INFO - this is info log, verbosity 1.
+	// INFO - this is info log, verbosity 2.
+}
+
+func ExampleGetLogger() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields))
+
+	dbLogger := logng.GetLogger("db")
+	pgLogger := logng.GetLogger("db.postgres")
+	pgLogger.Debug("this is debug log. it won't be shown.")
+
+	logng.SetLoggerSeverity("db", logng.SeverityDebug)
+	defer logng.UnsetLoggerLevels("db")
+	dbLogger.Debug("this is debug log of db.")
+	pgLogger.Debug("this is debug log of db.postgres.")
+
+	logng.SetLoggerSeverity("db.postgres", logng.SeverityWarning)
+	defer logng.UnsetLoggerLevels("db.postgres")
+	pgLogger.Info("this is info log of db.postgres. it won't be shown.")
+
+	// Output:
+	// {"severity":"DEBUG","message":"this is debug log of db.","_logger":"db"}
+	// {"severity":"DEBUG","message":"this is debug log of db.postgres.","_logger":"db.postgres"}
+}
+
+func ExampleNewFilterOutput() {
+	output := logng.NewFilterOutput(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), func(log *logng.Log) bool {
+		return log.Verbosity == 0 && !regexp.MustCompile(`^health`).Match(log.Message)
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 1)
+	logger.Info("health check succeeded. it won't be shown.")
+	logger.V(1).Info("this is info log, verbosity 1. it won't be shown.")
+	logger.Info("this is info log.")
+
+	// Output:
+	// INFO - this is info log.
+}
+
+func ExampleRouterOutput() {
+	output := logng.NewRouterOutput("tenant", logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity))
+	output.SetRoute("acme", logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity))
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("tenant", "acme").Info("this is info log of acme.")
+	logger.WithFieldKeyVals("tenant", "other").Info("this is info log of other.")
+	logger.Info("this is info log without tenant.")
+
+	// Output:
+	// {"severity":"INFO","message":"this is info log of acme."}
+	// INFO - this is info log of other.
+	// INFO - this is info log without tenant.
+}
+
+func ExampleFailoverOutput() {
+	primary := logng.NewTextOutput(errWriter{}, logng.TextOutputFlagSeverity).SetOnError(func(error) {})
+	fallback := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity)
+	output := logng.NewFailoverOutput(primary, fallback).SetOnFailover(func(from, to logng.Output) {
+		fmt.Println("failed over to fallback:", to == fallback)
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log.")
+	logger.Info("this is another info log. the primary output is skipped.")
+
+	// Output:
+	// INFO - this is info log.
+	// failed over to fallback: true
+	// INFO - this is another info log. the primary output is skipped.
+}
+
+func ExampleFailoverOutput_Active() {
+	primary := logng.NewTextOutput(errWriter{}, logng.TextOutputFlagSeverity).SetOnError(func(error) {})
+	fallback := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity)
+	output := logng.NewFailoverOutput(primary, fallback)
+	// the outputs and the callbacks are called without holding the lock of FailoverOutput.
+	output.SetOnFailover(func(from, to logng.Output) {
+		fmt.Println("active is fallback:", output.Active() == fallback)
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log.")
+
+	// Output:
+	// INFO - this is info log.
+	// active is fallback: true
+}
+
+func ExampleLoadBalanceOutput() {
+	output := logng.NewLoadBalanceOutput(logng.LoadBalanceRoundRobin,
+		logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity),
+		logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity))
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is the first log.")
+	logger.Info("this is the second log.")
+	logger.Info("this is the third log.")
+
+	// Output:
+	// INFO - this is the first log.
+	// {"severity":"INFO","message":"this is the second log."}
+	// INFO - this is the third log.
+}
+
+func ExampleLogger_WithSampling() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityDebug, 0)
+	sampler := logng.NewSampler().SetEvery(logng.SeverityDebug, 3).SetProbability(logng.SeverityInfo, 0)
+	sampled := logger.WithSampling(sampler)
+	for i := 0; i < 5; i++ {
+		sampled.Debugf("this is debug log #%d.", i)
+		sampled.Infof("this is info log #%d. it won't be shown.", i)
+	}
+	sampled.Warning("this is warning log.")
+
+	// Output:
+	// DEBUG - this is debug log #0.
+	// DEBUG - this is debug log #3.
+	// WARNING - this is warning log.
+}
+
+func ExampleSampler_SetBurst() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	sampled := logger.WithSampling(logng.NewSampler().SetBurst(logng.SeverityInfo, time.Hour, 2, 3))
+	for i := 0; i < 8; i++ {
+		sampled.Info("request handled.")
+	}
+	sampled.Info("this is another message.")
+
+	// Output:
+	// INFO - request handled.
+	// INFO - request handled.
+	// INFO - request handled.
+	// INFO - request handled.
+	// INFO - this is another message.
+}
+
+func ExampleSamplerOutput() {
+	output := logng.NewSamplerOutput(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity),
+		logng.NewSampler().SetEvery(logng.SeverityInfo, 2))
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	for i := 0; i < 4; i++ {
+		logger.Infof("this is info log #%d.", i)
+	}
+
+	// Output:
+	// INFO - this is info log #0.
+	// INFO - this is info log #2.
+}
+
+func ExampleRateLimitOutput() {
+	output := logng.NewRateLimitOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), 2, time.Hour)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	for i := 0; i < 5; i++ {
+		logger.Warning("connection refused.")
+	}
+	logger.Info("this is info log.")
+	_ = output.Close()
+
+	// Output:
+	// {"severity":"WARNING","message":"connection refused."}
+	// {"severity":"WARNING","message":"connection refused."}
+	// {"severity":"INFO","message":"this is info log."}
+	// {"severity":"WARNING","message":"suppressed 3 similar messages","_rate_limit_key":"connection refused.","_suppressed":3}
+}
+
+func ExampleRateLimitOutput_SetKeyByCaller() {
+	output := logng.NewRateLimitOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity), 2, time.Hour).
+		SetKeyByCaller(true)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	for i := 0; i < 5; i++ {
+		logger.Warningf("connection to node %d refused.", i)
+	}
+	_ = output.Close()
+
+	// Output:
+	// {"severity":"WARNING","message":"connection to node 0 refused."}
+	// {"severity":"WARNING","message":"connection to node 1 refused."}
+	// {"severity":"WARNING","message":"suppressed 3 similar messages"}
+}
+
+func ExampleLogger_Once() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	for i := 0; i < 3; i++ {
+		logger.Once().Warningf("this is warning log #%d. it will be shown once.", i)
+	}
+
+	// Output:
+	// WARNING - this is warning log #0. it will be shown once.
+}
+
+func ExampleLogger_Every() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	for i := 0; i < 3; i++ {
+		logger.Every(time.Hour).Warning("queue is full.")
+	}
+	logger.Every(0).Warning("this is warning log.")
+
+	// Output:
+	// {"severity":"WARNING","message":"queue is full."}
+	// {"severity":"WARNING","message":"this is warning log."}
+}
+
+func ExampleLogger_AddHook() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger.AddHook(func(log *logng.Log) error {
+		log.Fields = append(log.Fields, logng.Field{Key: "alert", Value: true})
+		return nil
+	}, logng.SeverityError)
+	logger.AddHook(func(log *logng.Log) error {
+		if regexp.MustCompile(`^health`).Match(log.Message) {
+			return logng.ErrDropLog
+		}
+		return nil
+	})
+	logger.Info("health check succeeded. it won't be shown.")
+	logger.Info("this is info log.")
+	logger.Error("this is error log.")
+
+	// Output:
+	// {"severity":"INFO","message":"this is info log."}
+	// {"severity":"ERROR","message":"this is error log.","_alert":true}
+}
+
+func ExampleChainOutput() {
+	truncate := logng.ProcessorFunc(func(log *logng.Log) *logng.Log {
+		if len(log.Message) <= 20 {
+			return log
+		}
+		log = log.Clone()
+		log.Message = append(log.Message[:20], "..."...)
+		return log
+	})
+	enrich := logng.ProcessorFunc(func(log *logng.Log) *logng.Log {
+		log = log.Clone()
+		log.Fields = append(log.Fields, logng.Field{Key: "service", Value: "api"})
+		return log
+	})
+	output := logng.ChainOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), truncate, enrich)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is a long info log which will be truncated.")
+
+	// Output:
+	// {"severity":"INFO","message":"this is a long info ...","_service":"api"}
+}
+
+func ExampleRedactor() {
+	redactor := logng.NewRedactor().
+		AddKeyPatterns("password", "*_token").
+		AddValueRegexps(logng.RedactCreditCard, logng.RedactEmail)
+	output := logng.ChainOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), redactor)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", "john@example.com", "password", "secret", "access_token", 123).
+		Info("payment by card 4111 1111 1111 1111 succeeded.")
+
+	// Output:
+	// {"severity":"INFO","message":"payment by card *** succeeded.","_user":"***","_password":"***","_access_token":"***"}
+}
+
+func ExampleSecret() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	apiKey := logng.Secret("sk-1234")
+	logger.WithFieldKeyVals("api_key", apiKey).Info("this is info log.")
+	fmt.Printf("%v %+v %#v %q\n", apiKey, apiKey, apiKey, apiKey)
+	fmt.Println(apiKey.Reveal())
+
+	// Output:
+	// {"severity":"INFO","message":"this is info log.","_api_key":"***"}
+	// *** *** *** "***"
+	// sk-1234
+}
+
+func ExampleLazy() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	state := func() interface{} {
+		fmt.Println("building state")
+		return "ready"
+	}
+	logger.WithFields(logng.Lazy("state", state)).Debug("this is debug log. it won't be shown.")
+	logger.WithFields(logng.Lazy("state", state)).Info("this is info log.")
+
+	// Output:
+	// building state
+	// {"severity":"INFO","message":"this is info log.","_state":"ready"}
+}
+
+func ExampleFieldMarshaler() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", testUser{ID: 42, Name: "john", passwordHash: "5f4dcc3b"}).Info("user logged in.")
+
+	// Output:
+	// {"severity":"INFO","message":"user logged in.","_user":{"id":42,"name":"john"}}
+}
+
+func ExampleLogger_WithGroup() {
+	jsonLogger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	jsonLogger.WithFieldKeyVals("service", "api").
+		WithGroup("request").WithFieldKeyVals("method", "GET", "path", "/users").
+		WithFields(logng.Group("client", logng.Field{Key: "ip", Value: "10.0.0.1"})).
+		Info("request handled.")
+
+	// text outputs flatten the groups.
+	for _, field := range (logng.Fields{logng.Group("request", logng.Field{Key: "method", Value: "GET"})}).Flatten() {
+		fmt.Println(field.Key, field.Value)
+	}
+
+	// Output:
+	// {"severity":"INFO","message":"request handled.","_service":"api","_request":{"method":"GET","path":"/users","client":{"ip":"10.0.0.1"}}}
+	// request.method GET
+}
+
+func ExampleFieldsFromStruct() {
+	type request struct {
+		Method   string `logng:"method"`
+		Path     string `logng:"path"`
+		Query    string `logng:"query,omitempty"`
+		Token    string `logng:"token,secret"`
+		Body     []byte `logng:"-"`
+		Attempts int
+		internal bool
+	}
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger.WithStruct(&request{Method: "GET", Path: "/users", Token: "abc", Body: []byte("{}"), Attempts: 1}).Info("request handled.")
+
+	// Output:
+	// {"severity":"INFO","message":"request handled.","_method":"GET","_path":"/users","_token":"***","_Attempts":1}
+}
+
+func ExampleJSONOutputFlagNestedFields() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagNestedFields), logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("message", "hello", "user", "john").Info("this is info log.")
+	logger.Info("this is info log without fields.")
+
+	// Output:
+	// {"severity":"INFO","message":"this is info log.","fields":{"message":"hello","user":"john"}}
+	// {"severity":"INFO","message":"this is info log without fields."}
+}
+
+func ExampleJSONOutputFlagError() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagError), logng.SeverityInfo, 0)
+	logger.Errorf("unable to load config: %w", fmt.Errorf("unable to open file: %w", io.ErrUnexpectedEOF))
+
+	// Output:
+	// {"severity":"ERROR","message":"unable to load config: unable to open file: unexpected EOF","error":{"message":"unable to open file: unexpected EOF","type":"*fmt.wrapError","causes":[{"message":"unexpected EOF","type":"*errors.errorString"}]}}
+}
+
+func ExampleLogger_Errore() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagError), logng.SeverityInfo, 0)
+	err := errors.New("connection refused")
+	logger.Errore(err, "unable to connect to database.")
+	logger.Warninge(err)
+
+	// Output:
+	// {"severity":"ERROR","message":"unable to connect to database.","error":{"message":"connection refused","type":"*errors.errorString"}}
+	// {"severity":"WARNING","message":"connection refused","error":{"message":"connection refused","type":"*errors.errorString"}}
+}
+
+func ExampleLogger_InfoS() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger.InfoS("request handled.", "method", "GET", "status", 200)
+	logger.ErrorS(errors.New("timeout"), "request failed.", "method", "POST")
+
+	// Output:
+	// {"severity":"INFO","message":"request handled.","_method":"GET","_status":200}
+	// {"severity":"ERROR","message":"request failed.","_method":"POST"}
+}
+
+func ExampleFromContext() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	ctx := logng.NewContext(context.Background(), logger.WithFieldKeyVals("request_id", "abc123"))
+	handle := func(ctx context.Context) {
+		logng.FromContext(ctx).Info("request handled.")
+	}
+	handle(ctx)
+
+	// Output:
+	// {"severity":"INFO","message":"request handled.","_request_id":"abc123"}
+}
+
+type exampleTenantKey struct{}
+
+func ExampleRegisterContextExtractor() {
+	logng.RegisterContextExtractor(func(ctx context.Context) logng.Fields {
+		if tenant, ok := ctx.Value(exampleTenantKey{}).(string); ok {
+			return logng.Fields{{Key: "tenant", Value: tenant}}
+		}
+		return nil
+	})
+
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	ctx := context.WithValue(context.Background(), exampleTenantKey{}, "acme")
+	logger.WithContext(ctx).Info("tenant request handled.")
+	logger.WithContext(context.Background()).Info("anonymous request handled.")
+
+	// Output:
+	// {"severity":"INFO","message":"tenant request handled.","_tenant":"acme"}
+	// {"severity":"INFO","message":"anonymous request handled."}
+}
+
+func ExampleHostFields() {
+	for _, field := range logng.HostFields("billing", "1.4.2") {
+		if field.Key == "hostname" || field.Key == "pid" {
+			continue
+		}
+		fmt.Printf("%s=%v\n", field.Key, field.Value)
+	}
+
+	// Output:
+	// app=billing
+	// version=1.4.2
+}
+
+func ExampleBuildInfoFields() {
+	for _, field := range logng.BuildInfoFields() {
+		fmt.Println(field.Key)
+	}
+}
+
+func ExampleLogger_WithSequence() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger = logger.WithSequence("")
+	logger.Info("first.")
+	logger.Debug("not logged.")
+	logger.WithFieldKeyVals("component", "db").Info("second.")
+	logger.Info("third.")
+
+	// Output:
+	// {"severity":"INFO","message":"first.","_seq":1}
+	// {"severity":"INFO","message":"second.","_component":"db","_seq":2}
+	// {"severity":"INFO","message":"third.","_seq":3}
+}
+
+func ExampleTextOutputFlagUptime() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagUptime|logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	logger.Info("service started.")
+	logger.WithUptime("").Info("ready.")
+}
+
+func ExampleLogger_Critical() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity), logng.SeverityNotice, 0)
+	logger.Emergency("database is unreachable.")
+	logger.Critical("replica lag exceeded the limit.")
+	logger.Notice("configuration reloaded.")
+	logger.Info("not logged.")
+
+	// Output:
+	// {"severity":"EMERGENCY","message":"database is unreachable."}
+	// {"severity":"CRITICAL","message":"replica lag exceeded the limit."}
+	// {"severity":"NOTICE","message":"configuration reloaded."}
+}
+
+func ExampleParseSeverity() {
+	for _, s := range []string{"warn", " 2 ", "10", "11", "-1", "verbose"} {
+		severity, err := logng.ParseSeverity(s)
+		fmt.Println(severity, err)
+	}
+	verbose, err := logng.ParseVerbose("3")
+	fmt.Println(verbose, err)
+
+	// Output:
+	// WARNING <nil>
+	// ERROR <nil>
+	// PANIC <nil>
+	// NONE invalid severity
+	// NONE invalid severity
+	// NONE unknown severity
+	// 3 <nil>
+}
+
+func ExampleSeverity_Rank() {
+	output := logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagSeverityLevel)
+	logger := logng.NewLogger(output, logng.SeverityNotice, 0)
+	logger.Info("this is info log. it won't be shown.")
+	logger.Notice("this is notice log.")
+	logger.Warning("this is warning log.")
+	logger.Critical("this is critical log.")
+	fmt.Println(logng.SeverityCritical.Rank() < logng.SeverityError.Rank())
+
+	// Output:
+	// {"severity":"NOTICE","message":"this is notice log.","severity_level":9}
+	// {"severity":"WARNING","message":"this is warning log.","severity_level":3}
+	// {"severity":"CRITICAL","message":"this is critical log.","severity_level":8}
+	// true
+}
+
+func ExampleLogger_Panic() {
+	logger := logng.NewLogger(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity), logng.SeverityInfo, 0)
+	logger.DPanic("unexpected state.")
+
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	logger.SetDevelopment(true)
+	logger.DPanicf("unexpected state %d.", 2)
+
+	// Output:
+	// {"severity":"PANIC","message":"unexpected state."}
+	// {"severity":"PANIC","message":"unexpected state 2."}
+	// recovered: unexpected state 2.
+}
+
+func ExampleLogger_Sync() {
+	w := logng.NewBufferedWriter(os.Stdout, 4096, 0)
+	queue := logng.NewQueuedOutput(logng.NewJSONOutput(w, logng.JSONOutputFlagSeverity), 16)
+	defer queue.Close()
+	logger := logng.NewLogger(logng.MultiOutput(queue), logng.SeverityInfo, 0)
+	logger.Info("first.")
+	logger.Info("second.")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// {"severity":"INFO","message":"first."}
+	// {"severity":"INFO","message":"second."}
+	// synced.
+}
+
+func ExampleLogger_Sync_filterOutput() {
+	w := logng.NewBufferedWriter(os.Stdout, 4096, 0)
+	queue := logng.NewQueuedOutput(logng.NewJSONOutput(w, logng.JSONOutputFlagSeverity), 16)
+	defer queue.Close()
+	output := logng.ChainOutput(logng.NewFilterOutput(queue, nil))
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("first.")
+	logger.Info("second.")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// {"severity":"INFO","message":"first."}
+	// {"severity":"INFO","message":"second."}
+	// synced.
+}
+
+func ExampleLogger_Sync_getLogger() {
+	// set logng for this example.
+	logng.Reset()
+	w := logng.NewBufferedWriter(os.Stdout, 4096, 0)
+	logng.SetOutput(logng.NewJSONOutput(w, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields))
+
+	logger := logng.GetLogger("db")
+	logger.Info("first.")
+	logger.Info("second.")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// {"severity":"INFO","message":"first.","_logger":"db"}
+	// {"severity":"INFO","message":"second.","_logger":"db"}
+	// synced.
+}
+
+func ExampleWebhookOutput_Flush() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("%s\n", body)
+	}))
+	defer srv.Close()
+
+	output := logng.NewWebhookOutput(srv.URL, logng.WebhookFormatDiscord).
+		SetTemplate(template.Must(template.New("").Parse("{{.Severity}}: {{.Message}}"))).
+		SetBatchWindow(time.Hour)
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("it won't be posted.")
+	logger.Error("disk is full.")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// {"content":"ERROR: disk is full."}
+	// synced.
+}
+
+func ExampleSentryOutput_Flush() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("event received.")
+	}))
+	defer srv.Close()
+
+	output, err := logng.NewSentryOutput("http://public@" + srv.Listener.Addr().String() + "/1")
+	if err != nil {
+		panic(err)
+	}
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Error("payment failed.")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// event received.
+	// synced.
+}
+
+func ExampleSentryOutput_FlushTimeout() {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Println("event received.")
+	}))
+	defer srv.Close()
+
+	output, err := logng.NewSentryOutput("http://public@" + srv.Listener.Addr().String() + "/1")
+	if err != nil {
+		panic(err)
+	}
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Error("payment failed.")
+	fmt.Println(output.FlushTimeout(10 * time.Millisecond))
+	close(release)
+	fmt.Println(output.FlushTimeout(5 * time.Second))
+	fmt.Println(output.FlushTimeout(0))
+
+	// Output:
+	// false
+	// event received.
+	// true
+	// true
+}
+
+func ExampleTriggerOutput_Flush() {
+	w := logng.NewBufferedWriter(os.Stdout, 4096, 0)
+	output := logng.NewTriggerOutput(logng.NewTextOutput(w, logng.TextOutputFlagSeverity), logng.SeverityError, time.Minute)
+	logger := logng.NewLogger(output, logng.SeverityDebug, 0)
+	logger.Debug("opening file")
+	logger.Debug("shutting down")
+	if err := logger.Sync(); err != nil {
+		panic(err)
+	}
+	fmt.Println("synced.")
+
+	// Output:
+	// DEBUG - opening file
+	// DEBUG - shutting down
+	// synced.
+}
+
+type exampleCloser struct {
+	name string
+}
+
+func (o *exampleCloser) Log(log *logng.Log) {}
+
+func (o *exampleCloser) Close() error {
+	fmt.Println(o.name, "closed.")
+	return nil
+}
+
+func ExampleLogger_Close() {
+	// the queue closes its output, because it owns the output.
+	queue := logng.NewQueuedOutput(&exampleCloser{name: "file"}, 16).SetCloseOutput(true)
+	output := logng.MultiOutput(queue, logng.NewFilterOutput(&exampleCloser{name: "socket"}, nil))
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("shutting down.")
+	if err := logger.Close(); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// file closed.
+	// socket closed.
+}
+
+func ExampleQueuedOutput_SetCloseOutput() {
+	shared := &exampleCloser{name: "shared"}
+	queue := logng.NewQueuedOutput(shared, 16)
+	// by default, the output isn't closed, so it can still be used by others.
+	_ = queue.Close()
+	fmt.Println("queue closed.")
+
+	owned := &exampleCloser{name: "owned"}
+	queue = logng.NewQueuedOutput(owned, 16).SetCloseOutput(true)
+	_ = queue.Close()
+
+	// Output:
+	// queue closed.
+	// owned closed.
+}
+
+func ExampleQueuedOutput_Flush() {
+	queue := logng.NewQueuedOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity), 16)
+	defer queue.Close()
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	logger.Info("payment accepted.")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := queue.Flush(ctx); err != nil {
+		panic(err)
+	}
+	fmt.Println("checkpoint.")
+
+	// Output:
+	// {"severity":"INFO","message":"payment accepted."}
+	// checkpoint.
+}
+
+type exampleCountingWriter struct {
+	writes int
+}
+
+func (w *exampleCountingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return os.Stdout.Write(p)
+}
+
+func ExampleQueuedOutput_SetBatch() {
+	w := &exampleCountingWriter{}
+	queue := logng.NewQueuedOutput(logng.NewJSONOutput(w, logng.JSONOutputFlagSeverity), 16).SetBatch(3, time.Hour)
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	logger.Info("first.")
+	logger.Info("second.")
+	logger.Info("third.")
+	logger.Info("fourth.")
+	queue.Close()
+	fmt.Println("writes:", w.writes)
+
+	// Output:
+	// {"severity":"INFO","message":"first."}
+	// {"severity":"INFO","message":"second."}
+	// {"severity":"INFO","message":"third."}
+	// {"severity":"INFO","message":"fourth."}
+	// writes: 2
+}
+
+type exampleGateOutput struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (o *exampleGateOutput) Log(log *logng.Log) {
+	o.once.Do(func() {
+		close(o.started)
+		<-o.release
+	})
+	fmt.Println(log.Severity, string(log.Message))
+}
+
+func ExampleQueueOverflowPolicy() {
+	gate := &exampleGateOutput{started: make(chan struct{}), release: make(chan struct{})}
+	queue := logng.NewQueuedOutput(gate, 2).SetOverflowPolicy(logng.QueueOverflowDropOldest, 0)
+	queue.SetOnQueueFull(func() {
+		fmt.Println("queue is full.")
+	})
+	queue.SetOnQueueFullLog(func(dropped uint64, log *logng.Log) {
+		fmt.Printf("dropped %q, total %d.\n", log.Message, dropped)
+	})
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	logger.Info("log 1")
+	<-gate.started
+	for i := 2; i <= 5; i++ {
+		logger.Infof("log %d", i)
+	}
+	close(gate.release)
+	queue.Close()
+	fmt.Printf("%+v\n", queue.Stats())
+
+	// Output:
+	// queue is full.
+	// dropped "log 2", total 1.
+	// queue is full.
+	// dropped "log 3", total 2.
+	// INFO log 1
+	// INFO log 4
+	// INFO log 5
+	// {Enqueued:5 Delivered:3 Dropped:2 Spilled:0 SpillDropped:0 Depth:0}
+}
+
+func ExampleQueuedOutput_SetSpillFile() {
+	gate := &exampleGateOutput{started: make(chan struct{}), release: make(chan struct{})}
+	queue := logng.NewQueuedOutput(gate, 1)
+	if err := queue.SetSpillFile(os.TempDir() + string(os.PathSeparator) + "logng-example.spill"); err != nil {
+		panic(err)
+	}
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	logger.Info("log 1")
+	<-gate.started
+	for i := 2; i <= 4; i++ {
+		logger.Criticalf("log %d", i)
+	}
+	close(gate.release)
+	queue.Close()
+	fmt.Printf("%+v\n", queue.Stats())
+
+	// Output:
+	// INFO log 1
+	// CRITICAL log 2
+	// CRITICAL log 3
+	// CRITICAL log 4
+	// {Enqueued:4 Delivered:4 Dropped:0 Spilled:2 SpillDropped:0 Depth:0}
+}
+
+func ExampleQueuedOutput_SetSpillMaxSize() {
+	gate := &exampleGateOutput{started: make(chan struct{}), release: make(chan struct{})}
+	queue := logng.NewQueuedOutput(gate, 1).SetSpillMaxSize(200)
+	if err := queue.SetSpillFile(os.TempDir() + string(os.PathSeparator) + "logng-example-max.spill"); err != nil {
+		panic(err)
+	}
+	queue.SetOnQueueFullLog(func(dropped uint64, log *logng.Log) {
+		fmt.Printf("dropped %q, total %d.\n", log.Message, dropped)
+	})
+	queue.Log(&logng.Log{Message: []byte("log 1"), Severity: logng.SeverityInfo})
+	<-gate.started
+	// each log takes 86 bytes in the spill file, so only two of them fit in 200 bytes.
+	for i := 2; i <= 5; i++ {
+		queue.Log(&logng.Log{Message: []byte(fmt.Sprintf("log %d", i)), Severity: logng.SeverityCritical})
+	}
+	close(gate.release)
+	queue.Close()
+	fmt.Printf("%+v\n", queue.Stats())
+
+	// Output:
+	// dropped "log 5", total 1.
+	// INFO log 1
+	// CRITICAL log 2
+	// CRITICAL log 3
+	// CRITICAL log 4
+	// {Enqueued:4 Delivered:4 Dropped:1 Spilled:2 SpillDropped:1 Depth:0}
+}
+
+func ExampleQueuedOutput_CloseWithTimeout() {
+	gate := &exampleGateOutput{started: make(chan struct{}), release: make(chan struct{})}
+	queue := logng.NewQueuedOutput(gate, 16)
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	logger.Info("stuck log.")
+	<-gate.started
+	fmt.Println(queue.CloseWithTimeout(10 * time.Millisecond))
+
+	close(gate.release)
+	fmt.Println(queue.Close())
+
+	// Output:
+	// context deadline exceeded
+	// INFO stuck log.
+	// <nil>
+}
+
+type exampleRequestOutput struct {
+	mu       sync.Mutex
+	requests map[interface{}][]string
+}
+
+func (o *exampleRequestOutput) Log(log *logng.Log) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, field := range log.Fields {
+		if field.Key == "request_id" {
+			o.requests[field.Value] = append(o.requests[field.Value], string(log.Message))
+		}
+	}
+}
+
+func ExampleQueuedOutput_SetWorkers() {
+	output := &exampleRequestOutput{requests: make(map[interface{}][]string)}
+	queue := logng.NewQueuedOutput(output, 64).SetWorkers(4, "request_id")
+	logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+	for _, step := range []string{"received", "processed", "responded"} {
+		for _, id := range []string{"a", "b", "c"} {
+			logger.WithFieldKeyVals("request_id", id).Info(step)
+		}
+	}
+	queue.Close()
+	for _, id := range []string{"a", "b", "c"} {
+		fmt.Println(id, output.requests[id])
+	}
+
+	// Output:
+	// a [received processed responded]
+	// b [received processed responded]
+	// c [received processed responded]
+}
+
+func ExampleBufferedOutput() {
+	w := logng.NewBufferedWriter(os.Stdout, 4096, time.Minute)
+	output := logng.BufferedOutput(logng.NewJSONOutput(w, logng.JSONOutputFlagSeverity), w)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	logger.Info("first log.")
+	logger.Info("second log.")
+	fmt.Println("before closing.")
+	_ = logger.Close()
+
+	// Output:
+	// before closing.
+	// {"severity":"INFO","message":"first log."}
+	// {"severity":"INFO","message":"second log."}
+}
+
+type exampleFlakyOutput struct {
+	failures int
+	healthy  bool
+}
+
+func (o *exampleFlakyOutput) Log(log *logng.Log) {
+	if o.failures > 0 {
+		o.failures--
+		o.healthy = false
+		fmt.Printf("failed to log %q.\n", log.Message)
+		return
+	}
+	o.healthy = true
+	fmt.Printf("logged %q.\n", log.Message)
+}
+
+func (o *exampleFlakyOutput) Healthy() bool {
+	return o.healthy
+}
+
+func ExampleRetryOutput() {
+	flaky := &exampleFlakyOutput{failures: 2}
+	output := logng.NewRetryOutput(flaky, logng.RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+		Jitter:     0.2,
+	}).SetOnDrop(func(log *logng.Log, err error) {
+		fmt.Printf("dropped %q: %v\n", log.Message, err)
+	})
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	logger.Info("first log.")
+	flaky.failures = 5
+	logger.Info("second log.")
+
+	// Output:
+	// failed to log "first log.".
+	// failed to log "first log.".
+	// logged "first log.".
+	// failed to log "second log.".
+	// failed to log "second log.".
+	// failed to log "second log.".
+	// failed to log "second log.".
+	// dropped "second log.": unable to log after 3 retries: retries exhausted
+}
+
+type exampleSlowOutput struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (o *exampleSlowOutput) Log(log *logng.Log) {
+	if string(log.Message) == "slow log." {
+		close(o.started)
+		<-o.release
+	}
+	fmt.Printf("logged %q.\n", log.Message)
+}
+
+func ExampleRetryOutput_concurrent() {
+	slow := &exampleSlowOutput{started: make(chan struct{}), release: make(chan struct{})}
+	output := logng.NewRetryOutput(slow, logng.DefaultRetryPolicy())
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Info("slow log.")
+	}()
+	<-slow.started
+	// the slow log doesn't block the other logs.
+	logger.Info("fast log.")
+	close(slow.release)
+	<-done
+
+	// Output:
+	// logged "fast log.".
+	// logged "slow log.".
+}
+
+func ExampleCircuitBreakerOutput() {
+	flaky := &exampleFlakyOutput{failures: 2}
+	output := logng.NewCircuitBreakerOutput(flaky, 2, 20*time.Millisecond).
+		SetFallback(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity)).
+		SetOnStateChange(func(from, to logng.CircuitState) {
+			fmt.Printf("circuit: %v -> %v\n", from, to)
+		})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	logger.Info("log 1.")
+	logger.Info("log 2.")
+	logger.Info("log 3.")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info("log 4.")
+
+	// Output:
+	// failed to log "log 1.".
+	// INFO - log 1.
+	// failed to log "log 2.".
+	// circuit: closed -> open
+	// INFO - log 2.
+	// INFO - log 3.
+	// circuit: open -> half-open
+	// logged "log 4.".
+	// circuit: half-open -> closed
+}
+
+func ExampleRegisterOutput() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetOutput(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity))
+
+	audit := logng.MultiOutput(&exampleCloser{name: "audit file"}, &exampleCloser{name: "audit socket"})
+	logng.RegisterOutput(audit)
+	logng.RegisterOutput(audit)
+	fmt.Println("registered:", len(logng.Outputs()))
+
+	if err := logng.Close(); err != nil {
+		panic(err)
+	}
+	logng.UnregisterOutput(audit)
+	fmt.Println("registered:", len(logng.Outputs()))
+
+	// Output:
+	// registered: 1
+	// audit file closed.
+	// audit socket closed.
+	// registered: 0
+}
+
+func ExampleFileOutput() {
+	dir, err := ioutil.TempDir("", "logng-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output, err := logng.NewFileOutput(filepath.Join(dir, "app.log"), logng.TextOutputFlagSeverity)
+	if err != nil {
+		panic(err)
+	}
+	output.SetMaxSize(20).SetMaxBackups(2).SetCompress(true)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	for i := 1; i <= 4; i++ {
+		logger.Infof("log %d.", i)
+		// backups are named by their rotation time in milliseconds.
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := output.Close(); err != nil {
+		panic(err)
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		panic(err)
+	}
+	for _, info := range infos {
+		name := filepath.Join(dir, info.Name())
+		var data []byte
+		if filepath.Ext(name) == ".gz" {
+			f, err := os.Open(name)
+			if err != nil {
+				panic(err)
+			}
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				panic(err)
+			}
+			data, err = ioutil.ReadAll(zr)
+			_ = f.Close()
+			if err != nil {
+				panic(err)
+			}
+			fmt.Printf("backup: %s", data)
+			continue
+		}
+		if data, err = ioutil.ReadFile(name); err != nil {
+			panic(err)
+		}
+		fmt.Printf("current: %s", data)
+	}
+
+	// Output:
+	// backup: INFO - log 2.
+	// backup: INFO - log 3.
+	// current: INFO - log 4.
+}
+
+func ExampleOutputConfig() {
+	dir, err := ioutil.TempDir("", "logng-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	logger, err := logng.ParseConfig([]byte(fmt.Sprintf(`{
+		"output": {"path": %q, "format": "json", "json_flags": "severity"}
+	}`, path)))
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("this is info log.")
+	if err := logger.Close(); err != nil {
+		panic(err)
+	}
+	logger.Info("this is info log after closing. it won't be written, because the file has been closed.")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s", data)
+
+	// Output:
+	// {"severity":"INFO","message":"this is info log."}
+}
+
+func ExampleTimeoutOutputWithLimit() {
+	logng.SetErrorHandler(func(err error) {
+		fmt.Println("error:", err)
+	})
+	defer logng.SetErrorHandler(nil)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	output := logng.TimeoutOutputWithLimit(blockingOutput(release), 10*time.Millisecond, 1)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log. it will be dropped after the timeout.")
+	logger.Info("this is info log. it will be dropped immediately.")
+
+	// Output:
+	// error: output timeout: log dropped after 10ms
+	// error: output timeout: log dropped, because 1 deliveries are in flight
 }
 
-func ExampleSetPrintSeverity() {
-	// set logng for this example.
-	logng.Reset()
-	logng.SetTextOutputWriter(os.Stdout)
-	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+func ExampleWebSocketOutput() {
+	output := logng.NewWebSocketOutput(logng.JSONOutputFlagSeverity)
+	defer output.Close()
+	srv := httptest.NewServer(output)
+	defer srv.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	// cross-origin clients are rejected by default.
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "http://evil.example")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+	fmt.Println(resp.Status)
 
-	logng.SetPrintSeverity(logng.SeverityWarning)
-	logng.Print("this is the log.")
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /?severity=warning HTTP/1.1\r\nHost: %s\r\nOrigin: %s\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n", srv.Listener.Addr(), srv.URL)
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			panic(err)
+		}
+		if line == "\r\n" {
+			break
+		}
+		if strings.HasPrefix(line, "HTTP/") || strings.HasPrefix(line, "Sec-WebSocket-Accept:") {
+			fmt.Println(strings.TrimSpace(line))
+		}
+	}
+	for output.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	logger.Info("it won't be sent.")
+	logger.Warning("disk is almost full.")
+	readFrame := func() (byte, []byte) {
+		var header [2]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			panic(err)
+		}
+		payload := make([]byte, header[1]&0x7f)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			panic(err)
+		}
+		return header[0] & 0x0f, payload
+	}
+	opcode, payload := readFrame()
+	fmt.Printf("opcode %d: %s\n", opcode, payload)
+
+	// the client frames must be masked. an unmasked frame closes the connection with 1002.
+	if _, err := conn.Write([]byte{0x81, 0x05, 'e', 'r', 'r', 'o', 'r'}); err != nil {
+		panic(err)
+	}
+	opcode, payload = readFrame()
+	fmt.Printf("opcode %d: %d %s\n", opcode, int(payload[0])<<8|int(payload[1]), payload[2:])
 
 	// Output:
-	// WARNING - this is the log.
+	// 403 Forbidden
+	// HTTP/1.1 101 Switching Protocols
+	// Sec-WebSocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=
+	// opcode 1: {"severity":"WARNING","message":"disk is almost full."}
+	// opcode 8: 1002 protocol error
 }
 
-func ExampleWithTime() {
-	// set logng for this example.
-	logng.Reset()
-	logng.SetTextOutputWriter(os.Stdout)
-	logng.SetTextOutputFlags(logng.TextOutputFlagDefault)
-
-	logng.WithTime(testTime).Info("this is info log with the given time.")
+func ExampleSyslogOutput() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer ln.Close()
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	pid := fmt.Sprintf("%d", os.Getpid())
+
+	// RFC3164 messages are framed by new line, so the new lines in the messages are escaped.
+	output, err := logng.NewSyslogOutput("tcp", ln.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	output.SetTag("app").SetHostname("host1")
+	output.Log(&logng.Log{Severity: logng.SeverityError, Time: tm, Message: []byte("first line\nsecond line")})
+	_ = output.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		panic(err)
+	}
+	b, _ := ioutil.ReadAll(conn)
+	conn.Close()
+	fmt.Printf("%q\n", strings.Replace(string(b), pid, "PID", 1))
+
+	// RFC5424 messages are framed by octet counting.
+	output, err = logng.NewSyslogOutput("tcp", ln.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	output.SetFormat(logng.SyslogFormatRFC5424).SetTag("app").SetHostname("host1").
+		SetStructuredDataID("app@12345")
+	output.Log(&logng.Log{Severity: logng.SeverityWarning, Time: tm, Message: []byte("disk is almost full."),
+		Fields: logng.Fields{{Key: "usage", Value: 91}}})
+	_ = output.Close()
+	conn, err = ln.Accept()
+	if err != nil {
+		panic(err)
+	}
+	r := bufio.NewReader(conn)
+	var n int
+	if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+		panic(err)
+	}
+	b = make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		panic(err)
+	}
+	conn.Close()
+	fmt.Printf("%q\n", strings.Replace(string(b), pid, "PID", 1))
 
 	// Output:
-	// 2010/11/12 13:14:15 INFO - this is info log with the given time.
+	// "<11>Jan  2 03:04:05 host1 app[PID]: first line#012second line\n"
+	// "<12>1 2024-01-02T03:04:05.000000Z host1 app PID - [app@12345 usage=\"91\"] disk is almost full."
 }
 
-func ExampleWithPrefix() {
-	// set logng for this example.
-	logng.Reset()
-	logng.SetTextOutputWriter(os.Stdout)
-	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+func ExampleConfigWatcher() {
+	f, err := os.Create(os.TempDir() + string(os.PathSeparator) + "logng-example.json")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	writeConfig := func(severity string) {
+		if err := f.Truncate(0); err != nil {
+			panic(err)
+		}
+		if _, err := f.WriteAt([]byte(`{"severity": "`+severity+`", "output": {"path": "stdout", "text_flags": "severity"}}`), 0); err != nil {
+			panic(err)
+		}
+	}
 
-	logng.WithPrefix("prefix1: ").WithPrefix("prefix2: ").Info("this is info log.")
+	writeConfig("info")
+	logger := logng.NewLogger(logng.Discard, logng.SeverityInfo, 0)
+	watcher, err := logng.NewConfigWatcher(f.Name(), logger)
+	if err != nil {
+		panic(err)
+	}
+	defer watcher.Close()
+	logger.Debug("this is debug log. it won't be shown.")
+
+	writeConfig("debug")
+	if err := watcher.Reload(); err != nil {
+		panic(err)
+	}
+	logger.Debug("this is debug log.")
 
 	// Output:
-	// INFO - prefix1: prefix2: this is info log.
+	// DEBUG - this is debug log.
 }
 
-func ExampleWithSuffix() {
-	// set logng for this example.
-	logng.Reset()
-	logng.SetTextOutputWriter(os.Stdout)
-	logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+func ExampleConfigWatcher_SetOnReload() {
+	dir, err := ioutil.TempDir("", "logng-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logng.json")
+	writeConfig := func(severity string) {
+		data := `{"severity": "` + severity + `", "output": {"path": "stdout", "text_flags": "severity"}}`
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			panic(err)
+		}
+	}
 
-	logng.WithSuffix(" :suffix1").WithSuffix(" :suffix2").Info("this is info log.")
+	writeConfig("info")
+	logger := logng.NewLogger(logng.Discard, logng.SeverityInfo, 0)
+	watcher, err := logng.NewConfigWatcher(path, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer watcher.Close()
+	// the function is called without holding the lock of ConfigWatcher, so it can call its methods.
+	watcher.SetOnReload(func(config logng.Config) {
+		watcher.SetInterval(time.Hour)
+		fmt.Println("reloaded:", config.Severity)
+	})
+
+	writeConfig("debug")
+	if err := watcher.Reload(); err != nil {
+		panic(err)
+	}
+	logger.Debug("this is debug log.")
 
 	// Output:
-	// INFO - this is info log. :suffix2 :suffix1
+	// reloaded: DEBUG
+	// DEBUG - this is debug log.
 }
 
 func ExampleLogger() {
@@ -223,6 +2287,651 @@ func ExampleLogger() {
 	// WARNING - this is warning log, verbosity 2.
 }
 
+// exampleWaitingOutput waits for the release channel to be closed before logging.
+type exampleWaitingOutput struct {
+	release chan struct{}
+}
+
+func (o *exampleWaitingOutput) Log(log *logng.Log) {
+	<-o.release
+	fmt.Printf("logged %q.\n", log.Message)
+}
+
+func ExampleConcurrentMultiOutput() {
+	waiting := &exampleWaitingOutput{release: make(chan struct{})}
+	text := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity)
+	// the waiting output is released only after the text output has logged, so they must log concurrently.
+	output := logng.NewConcurrentMultiOutput(waiting, text).SetOnLogged(func(output logng.Output, elapsed time.Duration) {
+		if output == text {
+			close(waiting.release)
+		}
+	})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log.")
+
+	// Output:
+	// INFO - this is info log.
+	// logged "this is info log.".
+}
+
+func ExampleLogger_Writer() {
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	w := logger.Writer(logng.SeverityWarning)
+
+	_, _ = fmt.Fprint(w, "disk is almost full\nretrying ")
+	_, _ = fmt.Fprint(w, "in a second")
+	_ = w.Close()
+
+	// Output:
+	// WARNING - disk is almost full
+	// WARNING - retrying in a second
+}
+
+// lockedWriter is an io.Writer safe for concurrent use. It keeps every write as a separate string.
+type lockedWriter struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestCommandLogger(t *testing.T) {
+	if os.Getenv("LOGNG_TEST_COMMAND") != "" {
+		fmt.Println("line 1")
+		fmt.Print("partial line")
+		fmt.Fprintln(os.Stderr, "failure")
+		os.Exit(0)
+	}
+	w := &lockedWriter{}
+	logger := logng.NewLogger(logng.NewTextOutput(w, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCommandLogger$")
+	cmd.Env = append(os.Environ(), "LOGNG_TEST_COMMAND=1")
+	closer := logng.CommandLogger(cmd, logger)
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"INFO - line 1\n":       true,
+		"INFO - partial line\n": true,
+		"ERROR - failure\n":     true,
+	}
+	if len(w.writes) != len(want) {
+		t.Fatalf("got %d logs, want %d: %q", len(w.writes), len(want), w.writes)
+	}
+	for _, s := range w.writes {
+		if !want[s] {
+			t.Errorf("unexpected log %q", s)
+		}
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logng-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(os.PathSeparator) + "app.log"
+
+	output, err := logng.NewFileOutput(path, logng.TextOutputFlagSeverity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.SetMaxSize(20).SetMaxBackups(2).SetCompress(true)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	for i := 1; i <= 4; i++ {
+		// backups rotated in the same millisecond are named with counters.
+		logger.Infof("log %d.", i)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "INFO - log 4.\n"; got != want {
+		t.Errorf("got current file %q, want %q", got, want)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backups []string
+	for _, info := range infos {
+		if name := info.Name(); name != "app.log" {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got backups %q, want 2 backups", backups)
+	}
+	for _, name := range backups {
+		if !regexp.MustCompile(`^app-[0-9T.-]+\.log\.gz$`).MatchString(name) {
+			t.Errorf("got backup %q, want a compressed backup named by its rotation time", name)
+		}
+	}
+}
+
+func TestFileOutput_SetOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logng-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(os.PathSeparator) + "app.log"
+
+	output, err := logng.NewFileOutput(path, logng.TextOutputFlagSeverity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	// the errors are passed after unlocking, so the OnError function can log through the same FileOutput.
+	output.SetOnError(func(err error) {
+		logger.Error("symlink failed.")
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		output.SetSymlink(dir + string(os.PathSeparator) + "missing" + string(os.PathSeparator) + "app.log")
+		if err := output.Rotate(); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock in OnError function")
+	}
+	if output.Err() == nil {
+		t.Error("got nil Err after the symlink error")
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "ERROR - symlink failed.\n"; got != want {
+		t.Errorf("got current file %q, want %q", got, want)
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logng-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logng.json")
+	writeConfig := func(output, fields string) {
+		data := `{"output": {"path": "` + filepath.ToSlash(filepath.Join(dir, output)) + `", "format": "json",` +
+			` "json_flags": "fields"}, "fields": {` + fields + `}}`
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readOutput := func(output string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	writeConfig("1.log", `"env": "test"`)
+	logger := logng.NewLogger(logng.Discard, logng.SeverityInfo, 0).WithFieldKeyVals("app", "demo")
+	watcher, err := logng.NewConfigWatcher(path, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	clone := logger.WithGroup("request").WithFieldKeyVals("id", 1)
+	clone.Info("first.")
+
+	writeConfig("2.log", `"env": "prod", "region": "eu"`)
+	if err := watcher.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	// the clone writes to the new output, and the previous output has been closed.
+	clone.Info("second.")
+	logger.WithGroup("request").WithFieldKeyVals("id", 2).Info("third.")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"message":"first.","_env":"test","_app":"demo","_request":{"id":1}}` + "\n"
+	if got := readOutput("1.log"); got != want {
+		t.Errorf("got first output %q, want %q", got, want)
+	}
+	want = `{"message":"second.","_env":"test","_app":"demo","_request":{"id":1}}` + "\n" +
+		`{"message":"third.","_env":"prod","_region":"eu","_app":"demo","_request":{"id":2}}` + "\n"
+	if got := readOutput("2.log"); got != want {
+		t.Errorf("got second output %q, want %q", got, want)
+	}
+}
+
+type testFailingOutput struct {
+	mu    sync.Mutex
+	tries map[string]int
+}
+
+func (o *testFailingOutput) Log(log *logng.Log) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tries[string(log.Message)]++
+}
+
+func (o *testFailingOutput) Healthy() bool {
+	return false
+}
+
+func TestRetryOutput_buffered(t *testing.T) {
+	failing := &testFailingOutput{tries: make(map[string]int)}
+	dropped := make(chan string, 2)
+	output := logng.NewRetryOutput(failing, logng.RetryPolicy{
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+		BufferLen:  10,
+	}).SetOnDrop(func(log *logng.Log, err error) {
+		if !errors.Is(err, logng.ErrRetriesExhausted) {
+			t.Errorf("got error %v, want %v", err, logng.ErrRetriesExhausted)
+		}
+		dropped <- string(log.Message)
+	})
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	// the second log is buffered behind the first one without trying, and it is tried as many times as the first one.
+	logger.Info("first.")
+	logger.Info("second.")
+	for _, want := range []string{"first.", "second."} {
+		select {
+		case got := <-dropped:
+			if got != want {
+				t.Errorf("got dropped log %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("log %q isn't dropped", want)
+		}
+	}
+	failing.mu.Lock()
+	defer failing.mu.Unlock()
+	for _, msg := range []string{"first.", "second."} {
+		if got, want := failing.tries[msg], 3; got != want {
+			t.Errorf("got %d tries of log %q, want %d", got, msg, want)
+		}
+	}
+}
+
+func TestBuildInfoFields(t *testing.T) {
+	fields := logng.BuildInfoFields()
+	// the test binary is built in the main module, and the VCS fields depend on the build.
+	keys := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keys[field.Key] = true
+		switch field.Key {
+		case "module_path":
+			if got, want := field.Value, "github.com/goinsane/logng/v2"; got != want {
+				t.Errorf("got module path %v, want %v", got, want)
+			}
+		case "module_version", "vcs_revision":
+			if value, ok := field.Value.(string); !ok || value == "" {
+				t.Errorf("got %s %#v, want a non-empty string", field.Key, field.Value)
+			}
+		case "vcs_modified":
+			if _, ok := field.Value.(bool); !ok {
+				t.Errorf("got vcs_modified %#v, want a bool", field.Value)
+			}
+		default:
+			t.Errorf("got unexpected field %q", field.Key)
+		}
+	}
+	if !keys["module_path"] || !keys["module_version"] {
+		t.Errorf("got fields %v, want module_path and module_version", fields)
+	}
+
+	var sb strings.Builder
+	logger := logng.NewLogger(logng.NewJSONOutput(&sb, logng.JSONOutputFlagFields), logng.SeverityInfo, 0)
+	logger.WithBuildInfo().Info("started.")
+	if got, want := sb.String(), `"_module_path":"github.com/goinsane/logng/v2"`; !strings.Contains(got, want) {
+		t.Errorf("got %q, want it to contain %q", got, want)
+	}
+}
+
+func TestTextOutputFlagUptime(t *testing.T) {
+	var sb strings.Builder
+	logger := logng.NewLogger(logng.NewTextOutput(&sb, logng.TextOutputFlagUptime|logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	start := logng.StartTime()
+	logger.WithTime(start.Add(12*time.Second + 345678*time.Microsecond)).Info("ready.")
+	logger.WithTime(start.Add(3 * time.Millisecond)).Info("started.")
+	// the logs before the process start have zero uptime.
+	logger.WithTime(start.Add(-time.Second)).Info("old.")
+	want := "[12.345678] INFO - ready.\n" +
+		"[0.003000] INFO - started.\n" +
+		"[0.000000] INFO - old.\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTextOutput_SetColor(t *testing.T) {
+	var sb strings.Builder
+	output := logng.NewTextOutput(&sb, logng.TextOutputFlagSeverity|logng.TextOutputFlagPadding|logng.TextOutputFlagColor)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	// the escape sequences aren't counted in the padding.
+	for _, tt := range []struct {
+		color bool
+		want  string
+	}{
+		{true, "\x1b[32mINFO\x1b[0m - first\n       second\n"},
+		{false, "INFO - first\n       second\n"},
+	} {
+		sb.Reset()
+		output.SetColor(tt.color)
+		logger.Info("first\nsecond")
+		if got := sb.String(); got != tt.want {
+			t.Errorf("got %q with color %v, want %q", got, tt.color, tt.want)
+		}
+	}
+
+	// the override is kept when the writer is changed.
+	output.SetColor(true)
+	var sb2 strings.Builder
+	output.SetWriter(&sb2)
+	logger.Warning("log")
+	if got, want := sb2.String(), "\x1b[33mWARNING\x1b[0m - log\n"; got != want {
+		t.Errorf("got %q after SetWriter, want %q", got, want)
+	}
+}
+
+func TestLogger_Panicf(t *testing.T) {
+	var sb strings.Builder
+	logger := logng.NewLogger(logng.NewTextOutput(&sb, 0), logng.SeverityInfo, 0).SetDevelopment(true)
+	err := errors.New("boom")
+	for _, panicf := range []func(string, ...interface{}){logger.Panicf, logger.DPanicf} {
+		sb.Reset()
+		recovered := func() (x interface{}) {
+			defer func() {
+				x = recover()
+			}()
+			panicf("failed: %w", err)
+			return nil
+		}()
+		// the panic value is the logged message, which is formatted with %w.
+		if got, want := recovered, "failed: boom"; got != want {
+			t.Errorf("got recovered value %q, want %q", got, want)
+		}
+		if got, want := sb.String(), "failed: boom\n"; got != want {
+			t.Errorf("got log %q, want %q", got, want)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	if err := logng.SetVModule("logng_test=2"); err != nil {
+		t.Fatal(err)
+	}
+	output := logng.SetTextOutputFlags(logng.TextOutputFlagSeverity | logng.TextOutputFlagColor)
+	output.SetSeverityLabels(map[logng.Severity]string{logng.SeverityInfo: "BILGI"}).SetColor(true)
+	output.SetTimeFormatter(func(tm time.Time, locale string) string {
+		return locale
+	}).SetLocale("tr-TR")
+	logng.Reset()
+	defer logng.Reset()
+	var sb strings.Builder
+	logng.SetTextOutputWriter(&sb)
+	logng.SetTextOutputFlags(logng.TextOutputFlagDate | logng.TextOutputFlagUTC | logng.TextOutputFlagSeverity | logng.TextOutputFlagColor)
+	logng.V(2).Info("this is info log, verbosity 2. it won't be shown.")
+	logng.WithTime(time.Date(2010, 11, 12, 13, 14, 15, 0, time.UTC)).Info("this is info log.")
+	if got, want := sb.String(), "2010/11/12 INFO - this is info log.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLineWriter_SetMaxLineLen(t *testing.T) {
+	var sb strings.Builder
+	logger := logng.NewLogger(logng.NewTextOutput(&sb, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	w := logng.NewLineWriter(logger, logng.SeverityInfo).SetMaxLineLen(4)
+
+	// the partial line is logged when it exceeds the maximum length, and the long lines are split.
+	_, _ = io.WriteString(w, "abcdefghij")
+	if got, want := sb.String(), "INFO - abcd\nINFO - efgh\n"; got != want {
+		t.Errorf("got %q without new line, want %q", got, want)
+	}
+	_, _ = io.WriteString(w, "k\nlmnopq\nrs")
+	// the lines aren't split inside the UTF-8 characters.
+	_, _ = io.WriteString(w, "\nxyzçw\n")
+	_ = w.Close()
+	want := "INFO - abcd\nINFO - efgh\nINFO - ijk\nINFO - lmno\nINFO - pq\nINFO - rs\nINFO - xyz\nINFO - çw\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSyslogOutput(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	output, err := logng.NewSyslogOutput("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	output.SetTag("app").SetHostname("host1").SetFacility(logng.SyslogFacilityLocal0)
+	output.Log(&logng.Log{Severity: logng.SeverityError, Time: tm, Message: []byte("payment failed.")})
+	output.SetFormat(logng.SyslogFormatRFC5424)
+	output.Log(&logng.Log{Severity: logng.SeverityWarning, Time: tm, Message: []byte("disk is almost full."),
+		Fields: logng.Fields{{Key: "usage", Value: 91}}})
+	if !output.Healthy() {
+		t.Fatal(output.Err())
+	}
+
+	want := []string{
+		fmt.Sprintf("<131>Jan  2 03:04:05 host1 app[%d]: payment failed.", os.Getpid()),
+		fmt.Sprintf(`<132>1 2024-01-02T03:04:05.000000Z host1 app %d - [logng@32473 usage="91"] disk is almost full.`,
+			os.Getpid()),
+	}
+	b := make([]byte, 1024)
+	for _, w := range want {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b[:n]); got != w {
+			t.Errorf("got message %q, want %q", got, w)
+		}
+	}
+
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+	output.Log(&logng.Log{Severity: logng.SeverityError, Time: tm, Message: []byte("after close.")})
+	if err := output.Err(); !errors.Is(err, logng.ErrClosed) {
+		t.Errorf("got error %v, want %v", err, logng.ErrClosed)
+	}
+}
+
+func TestWebSocketOutput(t *testing.T) {
+	output := logng.NewWebSocketOutput(logng.JSONOutputFlagSeverity)
+	srv := httptest.NewServer(output)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.WriteString(conn, "GET /?severity=warning HTTP/1.1\r\n"+
+		"Host: "+srv.Listener.Addr().String()+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status code %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("accept %q", accept)
+	}
+	for output.Clients() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	output.Log(&logng.Log{Severity: logng.SeverityInfo, Message: []byte("below the severity filter.")})
+	output.Log(&logng.Log{Severity: logng.SeverityWarning, Message: []byte("disk is almost full.")})
+	readFrame := func(want string) {
+		t.Helper()
+		b := make([]byte, len(want))
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("frame %q, want %q", b, want)
+		}
+	}
+	readFrame("\x81\x37" + `{"severity":"WARNING","message":"disk is almost full."}`)
+	_ = output.Close()
+	readFrame("\x88\x0c\x03\xe9going away")
+}
+
+func TestLogger_FatalCode(t *testing.T) {
+	switch os.Getenv("LOGNG_TEST_FATAL") {
+	case "exit code":
+		logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+		logger.SetFatalExitCode(3).Fatal("unable to start.")
+	case "code":
+		logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+		logger.SetFatalExitCode(3).FatalCodef(4, "unable to start: %s.", "port in use")
+	case "reset":
+		// Reset restores the default exit code.
+		logng.SetFatalExitCode(3)
+		logng.Reset()
+		logng.SetTextOutputWriter(os.Stdout)
+		logng.SetTextOutputFlags(logng.TextOutputFlagSeverity)
+		logng.Fatal("unable to start.")
+	}
+	for _, tc := range []struct {
+		mode   string
+		code   int
+		output string
+	}{
+		{"exit code", 3, "FATAL - unable to start.\n"},
+		{"code", 4, "FATAL - unable to start: port in use.\n"},
+		{"reset", 1, "FATAL - unable to start.\n"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLogger_FatalCode$")
+		cmd.Env = append(os.Environ(), "LOGNG_TEST_FATAL="+tc.mode)
+		output, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("%s: unexpected error %v", tc.mode, err)
+		}
+		if code := exitErr.ExitCode(); code != tc.code {
+			t.Errorf("%s: exit code %d, want %d", tc.mode, code, tc.code)
+		}
+		if string(output) != tc.output {
+			t.Errorf("%s: output %q, want %q", tc.mode, output, tc.output)
+		}
+	}
+}
+
+func TestConfig_NewLogger(t *testing.T) {
+	before := runtime.NumGoroutine()
+	_, err := logng.Config{
+		Output: logng.OutputConfig{
+			Writer:        ioutil.Discard,
+			FlushInterval: time.Hour,
+			QueueLen:      16,
+		},
+		Outputs: []logng.OutputConfig{
+			{Writer: ioutil.Discard, Format: "xml"},
+		},
+	}.NewLogger()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// the outputs which have already been created must be closed, so their goroutines must exit.
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d > %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConfig_UnmarshalJSON(t *testing.T) {
+	logger, err := logng.ParseConfig([]byte(`{"severity":"warn","print_severity":"err"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	logger.SetOutput(logng.NewTextOutput(&sb, logng.TextOutputFlagSeverity))
+	logger.Warning("this is warning log.")
+	logger.Info("this is info log.")
+	logger.Print("this is print log.")
+	if got, want := sb.String(), "WARNING - this is warning log.\nERROR - this is print log.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := logng.ParseConfig([]byte(`{"severity":"verbose"}`)); !errors.Is(err, logng.ErrUnknownSeverity) {
+		t.Errorf("got error %v, want %v", err, logng.ErrUnknownSeverity)
+	}
+}
+
+// TestLogger_Fatal runs the Fatal methods in a child process, because they exit the process.
+func TestLogger_Fatal(t *testing.T) {
+	if mode := os.Getenv("LOGNG_TEST_FATAL"); mode != "" {
+		queue := logng.NewQueuedOutput(blockingOutput(make(chan struct{})), 16)
+		logger := logng.NewLogger(queue, logng.SeverityInfo, 0)
+		logger.SetFatalExitCode(3).SetFatalFlushTimeout(10 * time.Millisecond)
+		logger.Info("this log blocks the queue.")
+		if mode == "code" {
+			logger.FatalCode(4, "this is fatal log.")
+		}
+		logger.Fatal("this is fatal log.")
+		return
+	}
+	for mode, want := range map[string]int{"default": 3, "code": 4} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestLogger_Fatal$")
+		cmd.Env = append(os.Environ(), "LOGNG_TEST_FATAL="+mode)
+		err := cmd.Run()
+		cancel()
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != want {
+			t.Errorf("%s: got %v, want exit status %d", mode, err, want)
+		}
+	}
+}
+
 func BenchmarkInfo(b *testing.B) {
 	logng.Reset()
 	logng.SetTextOutputWriter(io.Discard)
@@ -361,7 +3070,7 @@ func BenchmarkWithFieldKeyVals(b *testing.B) {
 }
 
 func BenchmarkLogger_Info(b *testing.B) {
-	logger := logng.NewLogger(nopOutput{}, logng.SeverityInfo, 0)
+	logger := logng.NewLogger(logng.Discard, logng.SeverityInfo, 0)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		logger.Info("benchmark")
@@ -369,7 +3078,7 @@ func BenchmarkLogger_Info(b *testing.B) {
 }
 
 func BenchmarkLogger_Info_withStackTrace(b *testing.B) {
-	logger := logng.NewLogger(nopOutput{}, logng.SeverityInfo, 0)
+	logger := logng.NewLogger(logng.Discard, logng.SeverityInfo, 0)
 	logger.SetStackTraceSeverity(logng.SeverityInfo)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -377,9 +3086,172 @@ func BenchmarkLogger_Info_withStackTrace(b *testing.B) {
 	}
 }
 
-type nopOutput struct{}
+type panicOutput struct{}
+
+func (panicOutput) Log(*logng.Log) {
+	panic("unable to log")
+}
+
+type blockingOutput chan struct{}
+
+func (o blockingOutput) Log(*logng.Log) {
+	<-o
+}
+
+type printProducer struct{}
+
+func (printProducer) Produce(msgs []logng.KafkaMessage) error {
+	for _, msg := range msgs {
+		fmt.Println(msg.Topic, string(msg.Key), string(msg.Value))
+	}
+	return nil
+}
+
+type printPublisher struct{}
+
+func (printPublisher) Publish(subject string, data []byte) error {
+	if data[0] == '{' {
+		fmt.Println(subject, string(data))
+	} else {
+		fmt.Printf("%s %x\n", subject, data)
+	}
+	return nil
+}
+
+type exampleFlakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published chan struct{}
+}
+
+func (p *exampleFlakyPublisher) Publish(subject string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("connection lost")
+	}
+	fmt.Println(subject, string(data))
+	p.published <- struct{}{}
+	return nil
+}
+
+func (p *exampleFlakyPublisher) fail(failures int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = failures
+}
+
+func ExampleNATSOutput_Flush() {
+	publisher := &exampleFlakyPublisher{failures: 1, published: make(chan struct{}, 16)}
+	output := logng.NewNATSOutput(publisher, "logs", logng.JSONOutputFlagSeverity).
+		SetRetryInterval(time.Millisecond).
+		SetOnError(func(err error) {
+			fmt.Println("error:", err)
+		})
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	// the pending message is published by the background goroutine.
+	logger.Info("first.")
+	<-publisher.published
+
+	// the pending message is published by Flush.
+	output.SetRetryInterval(0)
+	publisher.fail(1)
+	logger.Info("second.")
+	if err := output.Flush(); err != nil {
+		panic(err)
+	}
+
+	// the pending message which still can't be published is dropped on closing.
+	publisher.fail(2)
+	logger.Info("third.")
+	_ = output.Close()
+	logger.Info("fourth.")
+
+	// Output:
+	// error: unable to publish message: connection lost
+	// logs {"severity":"INFO","message":"first."}
+	// error: unable to publish message: connection lost
+	// logs {"severity":"INFO","message":"second."}
+	// error: unable to publish message: connection lost
+	// error: 1 pending messages dropped: unable to publish message: connection lost
+	// error: closed
+}
+
+type exampleSQLDriver struct{}
+
+func (exampleSQLDriver) Open(string) (driver.Conn, error) {
+	return exampleSQLConn{}, nil
+}
+
+// exampleSQLConn prints the executed values and the transaction results. It fails executing the values of errors.
+type exampleSQLConn struct{}
+
+func (exampleSQLConn) Prepare(string) (driver.Stmt, error) {
+	return exampleSQLConn{}, nil
+}
+
+func (exampleSQLConn) Close() error {
+	return nil
+}
+
+func (exampleSQLConn) Begin() (driver.Tx, error) {
+	return exampleSQLConn{}, nil
+}
+
+func (exampleSQLConn) NumInput() int {
+	return -1
+}
+
+func (exampleSQLConn) Exec(args []driver.Value) (driver.Result, error) {
+	fmt.Println("exec", args)
+	if args[len(args)-1] == "ERROR" {
+		return nil, errors.New("exec failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (exampleSQLConn) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (exampleSQLConn) Commit() error {
+	fmt.Println("commit")
+	return nil
+}
+
+func (exampleSQLConn) Rollback() error {
+	fmt.Println("rollback")
+	return nil
+}
+
+func init() {
+	sql.Register("logng-example", exampleSQLDriver{})
+}
+
+type hexWriter struct{}
 
-func (nopOutput) Log(*logng.Log) {}
+func (hexWriter) Write(p []byte) (int, error) {
+	fmt.Printf("%x\n", p)
+	return len(p), nil
+}
+
+type testUser struct {
+	ID           int
+	Name         string
+	passwordHash string
+}
+
+func (u testUser) MarshalField() interface{} {
+	return map[string]interface{}{"id": u.ID, "name": u.Name}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write error")
+}
 
 var (
 	testTime, _ = time.ParseInLocation("2006-01-02T15:04:05", "2010-11-12T13:14:15", time.Local)
@@ -0,0 +1,13 @@
+//go:build go1.14
+// +build go1.14
+
+package logngtest
+
+import (
+	"testing"
+)
+
+// registerCleanup registers the given function to be called after the test has completed.
+func registerCleanup(tb testing.TB, f func()) {
+	tb.Cleanup(f)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.14
+// +build !go1.14
+
+package logngtest
+
+import (
+	"testing"
+)
+
+// registerCleanup does nothing, because testing.TB doesn't support cleanup functions before Go 1.14.
+func registerCleanup(tb testing.TB, f func()) {
+}
@@ -0,0 +1,76 @@
+package logngtest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// update is the flag -update to update golden files instead of comparing.
+var update = flag.Bool("update", false, "update golden files of logngtest")
+
+// GoldenTime is the time which the logs are normalized to by AssertGolden.
+var GoldenTime = time.Date(2010, 11, 12, 13, 14, 15, 0, time.UTC)
+
+// GoldenCaller is the caller which the logs are normalized to by AssertGolden.
+var GoldenCaller = logng.StackCaller{
+	Frame: runtime.Frame{
+		Function: "example.com/app.Func",
+		File:     "/src/app/app.go",
+		Line:     42,
+	},
+}
+
+// AssertGolden renders the given logs through the output which is created by newOutput with a buffer,
+// and compares the rendered bytes with the golden file at path. If the test binary runs with the flag -update,
+// the golden file is written instead of comparing.
+//
+// The logs are cloned and normalized before rendering: their times are GoldenTime, their callers are GoldenCaller
+// and they don't have stack traces. The time is in UTC, so the outputs which print the time in the local time zone
+// should be configured to print in UTC. If the output implements io.Closer, it is closed after rendering.
+func AssertGolden(tb testing.TB, path string, newOutput func(w io.Writer) logng.Output, logs ...*logng.Log) {
+	tb.Helper()
+
+	buf := bytes.NewBuffer(nil)
+	output := newOutput(buf)
+	for _, log := range logs {
+		log = log.Clone()
+		log.Time = GoldenTime
+		log.StackCaller = GoldenCaller
+		log.StackTrace = nil
+		output.Log(log)
+	}
+	if closer, ok := output.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			tb.Fatalf("unable to close output: %v", err)
+		}
+	}
+	got := buf.Bytes()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatalf("unable to create golden file directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			tb.Fatalf("unable to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("unable to read golden file: %v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		tb.Errorf("output doesn't match golden file %s (run with -update to update it)\ngot:\n%s\nwant:\n%s",
+			path, got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package logngtest_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/logngtest"
+)
+
+func TestTestingOutput(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	output := logngtest.NewTestingOutput(tb, logng.TextOutputFlagSeverity).SetFailSeverity(logng.SeverityError)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	logger.Info("this is info log.")
+	if tb.failed {
+		t.Fatal("info log has failed the test")
+	}
+	logger.Error("this is error log.")
+	if !tb.failed {
+		t.Fatal("error log hasn't failed the test")
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Warning("this is warning log after closing.")
+	want := []string{"INFO - this is info log.", "ERROR - this is error log."}
+	if fmt.Sprint(tb.logs) != fmt.Sprint(want) {
+		t.Fatalf("logs %q, want %q", tb.logs, want)
+	}
+}
+
+type fakeTB struct {
+	testing.TB
+	logs    []string
+	failed  bool
+	cleanup func()
+}
+
+func (tb *fakeTB) Logf(format string, args ...interface{}) {
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fail() {
+	tb.failed = true
+}
+
+func (tb *fakeTB) Cleanup(f func()) {
+	tb.cleanup = f
+}
+
+func ExampleObserverOutput() {
+	observer := logngtest.NewObserverOutput()
+	logger := logng.NewLogger(observer, logng.SeverityInfo, 0)
+	logger.WithFieldKeyVals("user", "john").Info("login succeeded")
+	logger.WithFieldKeyVals("user", "jane").Error("login failed")
+	logger.WithFieldKeyVals("user", "john").Error("login failed")
+
+	fmt.Println(observer.Len())
+	fmt.Println(observer.FilterSeverity(logng.SeverityError).Len())
+	fmt.Println(observer.FilterMessage("failed").FilterField("user", "john").Len())
+	for _, log := range observer.FilterField("user", "jane").All() {
+		fmt.Printf("%s - %s\n", log.Severity, log.Message)
+	}
+
+	// Output:
+	// 3
+	// 2
+	// 1
+	// ERROR - login failed
+}
+
+func TestAssertGolden(t *testing.T) {
+	logs := []*logng.Log{
+		{Message: []byte("login failed"), Severity: logng.SeverityWarning,
+			Fields: logng.Fields{{Key: "user", Value: "john"}}},
+		{Message: []byte("connection lost"), Severity: logng.SeverityError, Error: errors.New("EOF")},
+	}
+	logngtest.AssertGolden(t, "testdata/textoutput.golden", func(w io.Writer) logng.Output {
+		return logng.NewTextOutput(w, logng.TextOutputFlagDefault|logng.TextOutputFlagUTC|logng.TextOutputFlagShortFile)
+	}, logs...)
+	logngtest.AssertGolden(t, "testdata/jsonoutput.golden", func(w io.Writer) logng.Output {
+		return logng.NewJSONOutput(w, logng.JSONOutputFlagDefault&^logng.JSONOutputFlagLocalTZ)
+	}, logs...)
+}
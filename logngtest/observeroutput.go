@@ -0,0 +1,152 @@
+package logngtest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// ObserverOutput is an implementation of logng.Output by recording logs in memory, so tests can verify
+// exactly what was logged. The filter methods return a new ObserverOutput which holds the matching logs,
+// and they can be chained:
+//
+//	observer.FilterSeverity(logng.SeverityError).FilterField("user", "john").AssertLen(t, 1)
+type ObserverOutput struct {
+	mu   sync.RWMutex
+	logs []*logng.Log
+}
+
+// NewObserverOutput creates a new ObserverOutput.
+func NewObserverOutput() *ObserverOutput {
+	return &ObserverOutput{}
+}
+
+// Log is the implementation of logng.Output.
+func (o *ObserverOutput) Log(log *logng.Log) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.logs = append(o.logs, log.Clone())
+}
+
+// All returns all recorded logs in order.
+func (o *ObserverOutput) All() []*logng.Log {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	result := make([]*logng.Log, len(o.logs))
+	copy(result, o.logs)
+	return result
+}
+
+// TakeAll returns all recorded logs in order, and resets the underlying ObserverOutput.
+func (o *ObserverOutput) TakeAll() []*logng.Log {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	result := o.logs
+	o.logs = nil
+	return result
+}
+
+// Len returns the number of recorded logs.
+func (o *ObserverOutput) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.logs)
+}
+
+// Reset removes all recorded logs.
+func (o *ObserverOutput) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.logs = nil
+}
+
+// Filter returns a new ObserverOutput which holds the logs matching the given function.
+func (o *ObserverOutput) Filter(f func(log *logng.Log) bool) *ObserverOutput {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	result := &ObserverOutput{}
+	for _, log := range o.logs {
+		if f(log) {
+			result.logs = append(result.logs, log)
+		}
+	}
+	return result
+}
+
+// FilterSeverity returns a new ObserverOutput which holds the logs with exactly the given severity.
+func (o *ObserverOutput) FilterSeverity(severity logng.Severity) *ObserverOutput {
+	return o.Filter(func(log *logng.Log) bool {
+		return log.Severity == severity
+	})
+}
+
+// FilterMessage returns a new ObserverOutput which holds the logs whose messages contain substr.
+func (o *ObserverOutput) FilterMessage(substr string) *ObserverOutput {
+	return o.Filter(func(log *logng.Log) bool {
+		return bytes.Contains(log.Message, []byte(substr))
+	})
+}
+
+// FilterField returns a new ObserverOutput which holds the logs having the field with the given key and value.
+// The values are compared by reflect.DeepEqual.
+func (o *ObserverOutput) FilterField(key string, value interface{}) *ObserverOutput {
+	return o.Filter(func(log *logng.Log) bool {
+		for _, field := range log.Fields {
+			if field.Key == key && reflect.DeepEqual(field.Value, value) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FilterFieldKey returns a new ObserverOutput which holds the logs having the field with the given key.
+func (o *ObserverOutput) FilterFieldKey(key string) *ObserverOutput {
+	return o.Filter(func(log *logng.Log) bool {
+		for _, field := range log.Fields {
+			if field.Key == key {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AssertLen marks the test as failed if the number of recorded logs isn't n, and reports the recorded logs.
+func (o *ObserverOutput) AssertLen(tb testing.TB, n int) {
+	tb.Helper()
+	if logs := o.All(); len(logs) != n {
+		tb.Errorf("got %d logs, want %d:%s", len(logs), n, formatLogs(logs))
+	}
+}
+
+// AssertEmpty marks the test as failed if any log has been recorded, and reports the recorded logs.
+func (o *ObserverOutput) AssertEmpty(tb testing.TB) {
+	tb.Helper()
+	o.AssertLen(tb, 0)
+}
+
+// AssertNotEmpty marks the test as failed if no log has been recorded.
+func (o *ObserverOutput) AssertNotEmpty(tb testing.TB) {
+	tb.Helper()
+	if o.Len() == 0 {
+		tb.Errorf("got no logs, want any")
+	}
+}
+
+// formatLogs formats the given logs line by line for the failure messages.
+func formatLogs(logs []*logng.Log) string {
+	var sb strings.Builder
+	for _, log := range logs {
+		sb.WriteString(fmt.Sprintf("\n\t%s - %s", log.Severity, log.Message))
+		for _, field := range log.Fields {
+			sb.WriteString(fmt.Sprintf(" %s=%v", field.Key, field.Value))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+// Package logngtest provides logng outputs and helpers for tests.
+package logngtest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// TestingOutput is an implementation of logng.Output by writing formatted logs via testing.TB.Logf.
+// So, the logs appear under the test case which they belong to, and only when the test fails or runs verbose.
+//
+// Logs after the test has completed are discarded, because testing.TB panics on logging after completion.
+// Optionally, the test is marked as failed by the logs at or above the fail severity.
+type TestingOutput struct {
+	mu           sync.RWMutex
+	tb           testing.TB
+	text         *logng.TextOutput
+	failSeverity logng.Severity
+	done         bool
+}
+
+// NewTestingOutput creates a new TestingOutput by the given testing.TB and flags of logng.TextOutput.
+// On Go 1.14 and later, it registers a cleanup function to tb to discard the logs after the test has completed.
+// On the earlier versions, TestingOutput must be closed by Close before the test completes.
+func NewTestingOutput(tb testing.TB, flags logng.TextOutputFlag) *TestingOutput {
+	o := &TestingOutput{
+		tb: tb,
+	}
+	o.text = logng.NewTextOutput(testingWriter{o}, flags)
+	registerCleanup(tb, func() {
+		_ = o.Close()
+	})
+	return o
+}
+
+// Log is the implementation of logng.Output.
+func (o *TestingOutput) Log(log *logng.Log) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.done {
+		return
+	}
+	o.text.Log(log)
+	if o.failSeverity != logng.SeverityNone && log.Severity.Rank() <= o.failSeverity.Rank() {
+		o.tb.Fail()
+	}
+}
+
+// Close is the implementation of io.Closer. The logs after closing are discarded.
+func (o *TestingOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done = true
+	return nil
+}
+
+// SetFailSeverity sets the severity which the logs at or above fail the test, e.g. logng.SeverityError.
+// If failSeverity is logng.SeverityNone, the logs don't fail the test. By default, logng.SeverityNone.
+// It returns the underlying TestingOutput.
+func (o *TestingOutput) SetFailSeverity(failSeverity logng.Severity) *TestingOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failSeverity = failSeverity
+	return o
+}
+
+// SetFlags sets the flags of the underlying logng.TextOutput.
+// It returns the underlying TestingOutput.
+func (o *TestingOutput) SetFlags(flags logng.TextOutputFlag) *TestingOutput {
+	o.text.SetFlags(flags)
+	return o
+}
+
+// testingWriter writes to testing.TB of TestingOutput without the trailing new line.
+type testingWriter struct {
+	o *TestingOutput
+}
+
+func (w testingWriter) Write(p []byte) (n int, err error) {
+	w.o.tb.Logf("%s", bytes.TrimSuffix(p, []byte("\n")))
+	return len(p), nil
+}
@@ -0,0 +1,9 @@
+module github.com/goinsane/logng/v2/logrbridge
+
+go 1.18
+
+require github.com/goinsane/logng/v2 v2.0.0
+
+require github.com/go-logr/logr v1.4.4
+
+replace github.com/goinsane/logng/v2 => ../
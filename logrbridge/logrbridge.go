@@ -0,0 +1,97 @@
+// Package logrbridge provides an implementation of logr.LogSink on top of logng.Logger.
+package logrbridge
+
+import (
+	"github.com/go-logr/logr"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// LogSink is an implementation of logr.LogSink on top of logng.Logger.
+// V-levels are mapped to logng.Verbose, and key/values are mapped to logng.Fields.
+// The name of LogSink is added as the field "logger".
+type LogSink struct {
+	logger *logng.Logger
+	name   string
+}
+
+var (
+	_ logr.LogSink          = (*LogSink)(nil)
+	_ logr.CallDepthLogSink = (*LogSink)(nil)
+)
+
+// NewLogSink creates a new LogSink by the given logger.
+func NewLogSink(logger *logng.Logger) *LogSink {
+	return &LogSink{
+		logger: logger,
+	}
+}
+
+// New creates a new logr.Logger by the given logger.
+func New(logger *logng.Logger) logr.Logger {
+	return logr.New(NewLogSink(logger))
+}
+
+// Init is the implementation of logr.LogSink.
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.logger = s.logger.WithCallerSkip(info.CallDepth + 1)
+}
+
+// Enabled is the implementation of logr.LogSink.
+func (s *LogSink) Enabled(level int) bool {
+	return s.logger.V(logng.Verbose(level)).Enabled(logng.SeverityInfo)
+}
+
+// Info is the implementation of logr.LogSink.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.withValues(keysAndValues).V(logng.Verbose(level)).Info(msg)
+}
+
+// Error is the implementation of logr.LogSink.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	logger := s.withValues(keysAndValues)
+	if err == nil {
+		logger.Error(msg)
+		return
+	}
+	logger.Errorf("%s: %w", msg, err)
+}
+
+// WithValues is the implementation of logr.LogSink.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &LogSink{
+		logger: s.logger.WithFieldKeyVals(keysAndValues...),
+		name:   s.name,
+	}
+}
+
+// WithName is the implementation of logr.LogSink.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	s2 := &LogSink{
+		logger: s.logger,
+		name:   name,
+	}
+	if s.name != "" {
+		s2.name = s.name + "/" + name
+	}
+	return s2
+}
+
+// WithCallDepth is the implementation of logr.CallDepthLogSink.
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	return &LogSink{
+		logger: s.logger.WithCallerSkip(depth),
+		name:   s.name,
+	}
+}
+
+func (s *LogSink) withValues(keysAndValues []interface{}) *logng.Logger {
+	logger := s.logger
+	if s.name != "" {
+		logger = logger.WithFieldKeyVals("logger", s.name)
+	}
+	if len(keysAndValues) > 0 {
+		logger = logger.WithFieldKeyVals(keysAndValues...)
+	}
+	return logger
+}
@@ -0,0 +1,24 @@
+package logrbridge_test
+
+import (
+	"errors"
+	"os"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/logrbridge"
+)
+
+func ExampleNew() {
+	output := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity|logng.TextOutputFlagShortFile)
+	logger := logrbridge.New(logng.NewLogger(output, logng.SeverityInfo, 1))
+
+	logger.Info("this is info log.")
+	logger.V(1).Info("this is info log, verbosity 1.")
+	logger.V(2).Info("this is info log, verbosity 2. it won't be shown.")
+	logger.Error(errors.New("unknown"), "this is error log")
+
+	// Output:
+	// INFO - logrbridge_test.go:15 - this is info log.
+	// INFO - logrbridge_test.go:16 - this is info log, verbosity 1.
+	// ERROR - logrbridge_test.go:18 - this is error log: unknown
+}
@@ -0,0 +1,12 @@
+module github.com/goinsane/logng/v2/logrusbridge
+
+go 1.23
+
+require (
+	github.com/goinsane/logng/v2 v2.0.0
+	github.com/sirupsen/logrus v1.10.2
+)
+
+require golang.org/x/sys v0.13.0 // indirect
+
+replace github.com/goinsane/logng/v2 => ../
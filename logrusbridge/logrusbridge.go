@@ -0,0 +1,86 @@
+// Package logrusbridge provides a logrus hook which forwards logrus entries to a logng.Output.
+package logrusbridge
+
+import (
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// Hook is an implementation of logrus.Hook which forwards every entry to the given logng.Output.
+// The level, message, time, data and caller of entries are mapped to logng.Log.
+// The error in the entry data by the key logrus.ErrorKey is used as logng.Log.Error.
+type Hook struct {
+	output logng.Output
+	levels []logrus.Level
+}
+
+var (
+	_ logrus.Hook = (*Hook)(nil)
+)
+
+// NewHook creates a new Hook by the given output and levels.
+// If no level is given, the Hook fires for all levels.
+func NewHook(output logng.Output, levels ...logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	h := &Hook{
+		output: output,
+		levels: make([]logrus.Level, len(levels)),
+	}
+	copy(h.levels, levels)
+	return h
+}
+
+// Levels is the implementation of logrus.Hook.
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire is the implementation of logrus.Hook.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	log := &logng.Log{
+		Message:  []byte(entry.Message),
+		Severity: SeverityFromLevel(entry.Level),
+		Time:     entry.Time,
+		Fields:   make(logng.Fields, 0, len(entry.Data)),
+	}
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok && k == logrus.ErrorKey {
+			log.Error = err
+		}
+		log.Fields = append(log.Fields, logng.Field{Key: k, Value: v})
+	}
+	if entry.Caller != nil {
+		log.StackCaller = logng.StackCaller{Frame: *entry.Caller}
+	}
+	h.output.Log(log)
+	return nil
+}
+
+// SeverityFromLevel returns the logng.Severity by the given logrus level.
+func SeverityFromLevel(level logrus.Level) logng.Severity {
+	switch level {
+	case logrus.PanicLevel:
+		return logng.SeverityPanic
+	case logrus.FatalLevel:
+		return logng.SeverityFatal
+	case logrus.ErrorLevel:
+		return logng.SeverityError
+	case logrus.WarnLevel:
+		return logng.SeverityWarning
+	case logrus.InfoLevel:
+		return logng.SeverityInfo
+	default:
+		return logng.SeverityDebug
+	}
+}
@@ -0,0 +1,24 @@
+package logrusbridge_test
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/goinsane/logng/v2"
+	"github.com/goinsane/logng/v2/logrusbridge"
+)
+
+func ExampleNewHook() {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	output := logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields)
+	logger.AddHook(logrusbridge.NewHook(output, logrus.WarnLevel, logrus.ErrorLevel))
+
+	logger.Info("this is info log. it won't be forwarded.")
+	logger.WithField("key1", "val1").Warn("this is warning log.")
+
+	// Output:
+	// {"severity":"WARNING","message":"this is warning log.","_key1":"val1"}
+}
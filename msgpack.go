@@ -0,0 +1,182 @@
+package logng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// jsonToMsgPack transcodes the given json value into MessagePack, preserving the order of object keys.
+func jsonToMsgPack(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	b, err := appendMsgPackValue(make([]byte, 0, len(data)), dec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to transcode json to msgpack: %w", err)
+	}
+	return b, nil
+}
+
+func appendMsgPackValue(dst []byte, dec *json.Decoder) ([]byte, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return dst, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		var body []byte
+		n := 0
+		for dec.More() {
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return dst, err
+				}
+				body = appendMsgPackString(body, key.(string))
+			}
+			if body, err = appendMsgPackValue(body, dec); err != nil {
+				return dst, err
+			}
+			n++
+		}
+		if _, err = dec.Token(); err != nil {
+			return dst, err
+		}
+		if t == '{' {
+			dst = appendMsgPackHeader(dst, n, 0x80, 0xde, 0xdf)
+		} else {
+			dst = appendMsgPackHeader(dst, n, 0x90, 0xdc, 0xdd)
+		}
+		return append(dst, body...), nil
+	case nil:
+		return append(dst, 0xc0), nil
+	case bool:
+		if t {
+			return append(dst, 0xc3), nil
+		}
+		return append(dst, 0xc2), nil
+	case string:
+		return appendMsgPackString(dst, t), nil
+	case json.Number:
+		if x, e := strconv.ParseInt(string(t), 10, 64); e == nil {
+			return appendMsgPackInt(dst, x), nil
+		}
+		if x, e := strconv.ParseUint(string(t), 10, 64); e == nil {
+			return appendMsgPackUint64(dst, 0xcf, x), nil
+		}
+		x, e := strconv.ParseFloat(string(t), 64)
+		if e != nil {
+			return dst, e
+		}
+		return appendMsgPackUint64(dst, 0xcb, math.Float64bits(x)), nil
+	default:
+		return dst, io.ErrUnexpectedEOF
+	}
+}
+
+func appendMsgPackHeader(dst []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, b16, byte(n>>8), byte(n))
+	default:
+		return append(dst, b32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendMsgPackString(dst []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = append(dst, 0xda, byte(n>>8), byte(n))
+	default:
+		dst = append(dst, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, s...)
+}
+
+func appendMsgPackInt(dst []byte, x int64) []byte {
+	if x >= -32 && x < 128 {
+		return append(dst, byte(x))
+	}
+	return appendMsgPackUint64(dst, 0xd3, uint64(x))
+}
+
+func appendMsgPackUint64(dst []byte, code byte, x uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	return append(append(dst, code), b[:]...)
+}
+
+// appendMsgPackAny appends the given value as MessagePack.
+// The values of the types other than basic types are transcoded from their json encodings.
+func appendMsgPackAny(dst []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(dst, 0xc0), nil
+	case bool:
+		if v {
+			return append(dst, 0xc3), nil
+		}
+		return append(dst, 0xc2), nil
+	case string:
+		return appendMsgPackString(dst, v), nil
+	case []byte:
+		return appendMsgPackString(dst, string(v)), nil
+	case int:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case int8:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case int16:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case int32:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case int64:
+		return appendMsgPackInt(dst, v), nil
+	case uint:
+		return appendMsgPackUint64(dst, 0xcf, uint64(v)), nil
+	case uint8:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case uint16:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case uint32:
+		return appendMsgPackInt(dst, int64(v)), nil
+	case uint64:
+		return appendMsgPackUint64(dst, 0xcf, v), nil
+	case float32:
+		return appendMsgPackUint64(dst, 0xcb, math.Float64bits(float64(v))), nil
+	case float64:
+		return appendMsgPackUint64(dst, 0xcb, math.Float64bits(v)), nil
+	case time.Time:
+		return appendMsgPackTime(dst, v), nil
+	case error:
+		return appendMsgPackString(dst, v.Error()), nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return dst, fmt.Errorf("unable to marshal value: %w", err)
+	}
+	b, err = jsonToMsgPack(b)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, b...), nil
+}
+
+// appendMsgPackTime appends the given time as MessagePack timestamp extension type in 96-bit format.
+func appendMsgPackTime(dst []byte, tm time.Time) []byte {
+	var b [12]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(tm.Nanosecond()))
+	binary.BigEndian.PutUint64(b[4:], uint64(tm.Unix()))
+	return append(append(dst, 0xc7, 12, 0xff), b[:]...)
+}
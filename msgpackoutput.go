@@ -0,0 +1,129 @@
+package logng
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MsgPackOutput is an implementation of Output by writing MessagePack to io.Writer w.
+// Each log is encoded as a map with the keys message, severity, time, and caller, error and fields if present.
+// The time is encoded as the timestamp extension type, and the fields are encoded as a map.
+// The maps are written one after another without delimiters.
+type MsgPackOutput struct {
+	mu sync.RWMutex
+	w  io.Writer
+	health
+}
+
+// NewMsgPackOutput creates a new MsgPackOutput.
+func NewMsgPackOutput(w io.Writer) *MsgPackOutput {
+	return &MsgPackOutput{
+		w: w,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *MsgPackOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var b []byte
+	b, err = appendMsgPackLog(make([]byte, 0, 4096), log)
+	if err != nil {
+		return
+	}
+
+	_, err = o.w.Write(b)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *MsgPackOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer.
+// It returns the underlying MsgPackOutput.
+func (o *MsgPackOutput) SetWriter(w io.Writer) *MsgPackOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying MsgPackOutput.
+func (o *MsgPackOutput) SetOnError(f func(error)) *MsgPackOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *MsgPackOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *MsgPackOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// appendMsgPackLog appends the given log as a MessagePack map.
+func appendMsgPackLog(dst []byte, log *Log) ([]byte, error) {
+	n := 3
+	hasCaller := log.StackCaller.Function != ""
+	if hasCaller {
+		n++
+	}
+	if log.Error != nil {
+		n++
+	}
+	if len(log.Fields) > 0 {
+		n++
+	}
+	dst = appendMsgPackHeader(dst, n, 0x80, 0xde, 0xdf)
+	dst = appendMsgPackString(dst, "message")
+	dst = appendMsgPackString(dst, string(log.Message))
+	dst = appendMsgPackString(dst, "severity")
+	dst = appendMsgPackString(dst, log.Severity.String())
+	dst = appendMsgPackString(dst, "time")
+	dst = appendMsgPackTime(dst, log.Time)
+	if hasCaller {
+		dst = appendMsgPackString(dst, "caller")
+		dst = appendMsgPackString(dst, fmt.Sprintf("%s:%d", log.StackCaller.File, log.StackCaller.Line))
+	}
+	if log.Error != nil {
+		dst = appendMsgPackString(dst, "error")
+		dst = appendMsgPackString(dst, log.Error.Error())
+	}
+	if len(log.Fields) > 0 {
+		var err error
+		dst = appendMsgPackString(dst, "fields")
+		dst = appendMsgPackHeader(dst, len(log.Fields), 0x80, 0xde, 0xdf)
+		keys := make(map[string]struct{}, len(log.Fields))
+		for idx, field := range log.Fields {
+			key := field.Key
+			if _, ok := keys[key]; ok {
+				key = fmt.Sprintf("%d_%s", idx, key)
+			}
+			keys[key] = struct{}{}
+			dst = appendMsgPackString(dst, key)
+			if dst, err = appendMsgPackAny(dst, field.Value); err != nil {
+				return dst, fmt.Errorf("unable to encode field %q: %w", field.Key, err)
+			}
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,265 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher is the interface that wraps the Publish method.
+//
+// Publish publishes the given data to the given subject. *nats.Conn of nats.go implements NATSPublisher.
+// A JetStream context can be used by a wrapper which discards the publish acknowledgement.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSEncoding is the type of encoding of logs published by NATSOutput.
+type NATSEncoding int
+
+const (
+	// NATSEncodingJSON encodes logs as json.
+	NATSEncodingJSON NATSEncoding = iota
+
+	// NATSEncodingMsgPack encodes logs as MessagePack, with the same keys as json.
+	NATSEncodingMsgPack
+)
+
+// NATSOutput is an implementation of Output by publishing logs to a NATS subject.
+// The subject may include the placeholder {severity}, which is replaced by the lower case severity name of each log,
+// e.g. "logs.{severity}".
+//
+// Messages which can't be published, e.g. while the connection is reconnecting, are kept as pending up to
+// the maximum pending count. They are published in order before the next log, or by a background goroutine
+// every retry interval until they are drained. The oldest pending messages are dropped when the maximum pending
+// count is exceeded.
+type NATSOutput struct {
+	mu            sync.Mutex
+	publisher     NATSPublisher
+	subject       string
+	encoding      NATSEncoding
+	json          *JSONOutput
+	pending       []natsMessage
+	maxPending    int
+	retryInterval time.Duration
+	notifyCh      chan struct{}
+	stopCh        chan struct{}
+	stopped       bool
+	wg            sync.WaitGroup
+	health
+}
+
+type natsMessage struct {
+	subject string
+	data    []byte
+}
+
+// NewNATSOutput creates a new NATSOutput by the given publisher, subject and json flags.
+// By default, the encoding is NATSEncodingJSON, the maximum pending count is 1024, and the retry interval is
+// 1 second. Unused NATSOutput must be closed for freeing resources.
+func NewNATSOutput(publisher NATSPublisher, subject string, flags JSONOutputFlag) *NATSOutput {
+	o := &NATSOutput{
+		publisher:     publisher,
+		subject:       subject,
+		json:          NewJSONOutput(nil, flags),
+		maxPending:    1024,
+		retryInterval: time.Second,
+		notifyCh:      make(chan struct{}, 1),
+		stopCh:        make(chan struct{}),
+	}
+	o.wg.Add(1)
+	go o.drainer()
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *NATSOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.stopped {
+		err = ErrClosed
+		return
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.json.mu.RLock()
+	err = o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		return
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if o.encoding == NATSEncodingMsgPack {
+		if data, err = jsonToMsgPack(data); err != nil {
+			return
+		}
+	}
+	subject := strings.Replace(o.subject, "{severity}", strings.ToLower(log.Severity.String()), -1)
+
+	o.pending = append(o.pending, natsMessage{subject: subject, data: data})
+	err = o.publishPending()
+}
+
+// Flush is the implementation of Flusher. It publishes the pending messages immediately, and returns the error
+// if any of them can't be published. Then, it flushes the publisher if it implements Flusher, e.g. *nats.Conn.
+func (o *NATSOutput) Flush() error {
+	o.mu.Lock()
+	err := o.publishPending()
+	o.mu.Unlock()
+	o.report(err)
+	if err != nil {
+		return err
+	}
+	if f, ok := o.publisher.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("unable to flush publisher: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close stops the background goroutine, and publishes the pending messages once more. The messages which
+// still can't be published are dropped, and the error is returned. It doesn't close the publisher.
+// Logs after closing are dropped with ErrClosed.
+func (o *NATSOutput) Close() error {
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		return nil
+	}
+	o.stopped = true
+	close(o.stopCh)
+	o.mu.Unlock()
+	o.wg.Wait()
+
+	o.mu.Lock()
+	err := o.publishPending()
+	if n := len(o.pending); n > 0 {
+		o.pending = nil
+		err = fmt.Errorf("%d pending messages dropped: %w", n, err)
+	}
+	o.mu.Unlock()
+	o.report(err)
+	return err
+}
+
+// SetEncoding sets the encoding of logs.
+// It returns the underlying NATSOutput.
+func (o *NATSOutput) SetEncoding(encoding NATSEncoding) *NATSOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.encoding = encoding
+	return o
+}
+
+// SetMaxPending sets the maximum number of messages to keep while publishing fails.
+// If maxPending is less than 0, it is set to 0.
+// It returns the underlying NATSOutput.
+func (o *NATSOutput) SetMaxPending(maxPending int) *NATSOutput {
+	if maxPending < 0 {
+		maxPending = 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxPending = maxPending
+	return o
+}
+
+// SetRetryInterval sets the interval to retry publishing the pending messages in the background.
+// If retryInterval is less or equal than 0, the pending messages are retried only before the next log or on Flush.
+// It returns the underlying NATSOutput.
+func (o *NATSOutput) SetRetryInterval(retryInterval time.Duration) *NATSOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retryInterval = retryInterval
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying NATSOutput.
+func (o *NATSOutput) SetOnError(f func(error)) *NATSOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *NATSOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been published successfully.
+// It returns true if no log has been published yet.
+func (o *NATSOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// publishPending publishes the pending messages in order until one of them fails, and drops the oldest messages
+// exceeding the maximum pending count. It wakes up the background goroutine if any message is still pending.
+// It must be called with o.mu held.
+func (o *NATSOutput) publishPending() (err error) {
+	for len(o.pending) > 0 {
+		msg := o.pending[0]
+		if err = o.publisher.Publish(msg.subject, msg.data); err != nil {
+			err = fmt.Errorf("unable to publish message: %w", err)
+			break
+		}
+		o.pending[0] = natsMessage{}
+		o.pending = o.pending[1:]
+	}
+	if len(o.pending) == 0 {
+		o.pending = nil
+		return nil
+	}
+	if n := len(o.pending) - o.maxPending; n > 0 {
+		o.pending = o.pending[n:]
+		err = fmt.Errorf("%d pending messages dropped: %w", n, err)
+	}
+	select {
+	case o.notifyCh <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// drainer retries publishing the pending messages every retry interval until they are drained.
+func (o *NATSOutput) drainer() {
+	defer o.wg.Done()
+	for {
+		select {
+		case <-o.stopCh:
+			return
+		case <-o.notifyCh:
+		}
+		for {
+			o.mu.Lock()
+			retryInterval := o.retryInterval
+			o.mu.Unlock()
+			if retryInterval <= 0 {
+				break
+			}
+			timer := time.NewTimer(retryInterval)
+			select {
+			case <-o.stopCh:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			o.mu.Lock()
+			err := o.publishPending()
+			drained := len(o.pending) == 0
+			o.mu.Unlock()
+			o.report(err)
+			if drained {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package logng
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Field keys of trace context. OTLPOutput uses the values of these fields as trace and span IDs.
+const (
+	FieldKeyTraceID = "trace_id"
+	FieldKeySpanID  = "span_id"
+)
+
+// OTLPExporter is the interface that wraps the Export method.
+//
+// Export exports the given OTLP ExportLogsServiceRequest which is encoded as protobuf json.
+// It can be implemented by a gRPC client, e.g. by unmarshaling the request using protojson.
+type OTLPExporter interface {
+	Export(ctx context.Context, request []byte) error
+}
+
+// OTLPHTTPExporter is an implementation of OTLPExporter by exporting logs over OTLP/HTTP with json encoding.
+type OTLPHTTPExporter struct {
+	mu      sync.RWMutex
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewOTLPHTTPExporter creates a new OTLPHTTPExporter by the given url of the logs endpoint of a collector,
+// e.g. http://localhost:4318/v1/logs.
+func NewOTLPHTTPExporter(url string) *OTLPHTTPExporter {
+	return &OTLPHTTPExporter{
+		url:     url,
+		headers: make(http.Header),
+		client:  http.DefaultClient,
+	}
+}
+
+// Export is the implementation of OTLPExporter.
+func (e *OTLPHTTPExporter) Export(ctx context.Context, request []byte) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(request))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	for key, values := range e.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetHeader sets a header which is sent with every request, e.g. for authentication.
+// It returns the underlying OTLPHTTPExporter.
+func (e *OTLPHTTPExporter) SetHeader(key, value string) *OTLPHTTPExporter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.headers.Set(key, value)
+	return e
+}
+
+// SetClient sets the HTTP client. By default, http.DefaultClient.
+// It returns the underlying OTLPHTTPExporter.
+func (e *OTLPHTTPExporter) SetClient(client *http.Client) *OTLPHTTPExporter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client = client
+	return e
+}
+
+// OTLPOutput is an implementation of Output by converting logs to OTLP log records,
+// and exporting them in batches to an OpenTelemetry collector by the given OTLPExporter.
+//
+// Fields are converted to attributes, except the fields with the keys FieldKeyTraceID and FieldKeySpanID,
+// which are used as trace and span IDs in hex. Log.Error, Log.StackCaller and Log.StackTrace are converted
+// to the semantic convention attributes exception.message, code.* and exception.stacktrace.
+type OTLPOutput struct {
+	mu                 sync.Mutex
+	exporter           OTLPExporter
+	resourceAttributes []otlpKeyValue
+	scopeName          string
+	timeout            time.Duration
+	batcher            batcher
+}
+
+// NewOTLPOutput creates a new OTLPOutput by the given exporter and the service name as the resource attribute.
+// By default, the batch size is 512, the flush interval is 1 second, the maximum pending count is 10000
+// and the export timeout is 10 seconds.
+func NewOTLPOutput(exporter OTLPExporter, serviceName string) *OTLPOutput {
+	o := &OTLPOutput{
+		exporter:  exporter,
+		scopeName: "github.com/goinsane/logng",
+		timeout:   10 * time.Second,
+	}
+	if serviceName != "" {
+		o.resourceAttributes = []otlpKeyValue{otlpAttribute("service.name", serviceName)}
+	}
+	o.batcher.start(512, time.Second, o.export, nil)
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *OTLPOutput) Log(log *Log) {
+	record := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(log.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverityNumber(log.Severity),
+		SeverityText:         log.Severity.String(),
+		Body:                 otlpAnyValue{StringValue: stringPtr(string(log.Message))},
+		Attributes:           make([]otlpKeyValue, 0, len(log.Fields)+4),
+	}
+	for _, field := range log.Fields {
+		switch field.Key {
+		case FieldKeyTraceID:
+			record.TraceID = fmt.Sprintf("%v", field.Value)
+			continue
+		case FieldKeySpanID:
+			record.SpanID = fmt.Sprintf("%v", field.Value)
+			continue
+		}
+		record.Attributes = append(record.Attributes, otlpAttribute(field.Key, field.Value))
+	}
+	if log.Error != nil {
+		record.Attributes = append(record.Attributes, otlpAttribute("exception.message", log.Error.Error()))
+	}
+	if log.StackCaller.Function != "" {
+		record.Attributes = append(record.Attributes,
+			otlpAttribute("code.function", log.StackCaller.Function),
+			otlpAttribute("code.filepath", log.StackCaller.File),
+			otlpAttribute("code.lineno", log.StackCaller.Line))
+	}
+	if log.StackTrace != nil {
+		record.Attributes = append(record.Attributes, otlpAttribute("exception.stacktrace", fmt.Sprintf("%+.1s", log.StackTrace)))
+	}
+
+	o.batcher.add(record)
+}
+
+// Flush exports the pending logs immediately, and returns the export error if any.
+func (o *OTLPOutput) Flush() error {
+	return o.batcher.flush()
+}
+
+// Close stops the background goroutine and exports the pending logs.
+// Logs after closing are dropped with ErrClosed.
+func (o *OTLPOutput) Close() error {
+	return o.batcher.close()
+}
+
+// export exports the given batch of log records.
+func (o *OTLPOutput) export(items []interface{}) error {
+	records := make([]otlpLogRecord, 0, len(items))
+	for _, item := range items {
+		records = append(records, item.(otlpLogRecord))
+	}
+	o.mu.Lock()
+	request := otlpExportLogsServiceRequest{
+		ResourceLogs: []otlpResourceLogs{
+			{
+				Resource: otlpResource{Attributes: o.resourceAttributes},
+				ScopeLogs: []otlpScopeLogs{
+					{
+						Scope:      otlpScope{Name: o.scopeName},
+						LogRecords: records,
+					},
+				},
+			},
+		},
+	}
+	timeout := o.timeout
+	o.mu.Unlock()
+	b, err := json.Marshal(&request)
+	if err != nil {
+		return fmt.Errorf("unable to marshal request: %w", err)
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err = o.exporter.Export(ctx, b); err != nil {
+		return fmt.Errorf("unable to export logs: %w", err)
+	}
+	return nil
+}
+
+// SetResourceAttributes sets the attributes of the resource, e.g. service.version and host.name.
+// The service name given to NewOTLPOutput is kept unless it is overridden.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetResourceAttributes(fields Fields) *OTLPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	attributes := make([]otlpKeyValue, 0, len(fields)+1)
+	hasServiceName := false
+	for _, field := range fields {
+		hasServiceName = hasServiceName || field.Key == "service.name"
+		attributes = append(attributes, otlpAttribute(field.Key, field.Value))
+	}
+	if !hasServiceName {
+		for _, kv := range o.resourceAttributes {
+			if kv.Key == "service.name" {
+				attributes = append([]otlpKeyValue{kv}, attributes...)
+			}
+		}
+	}
+	o.resourceAttributes = attributes
+	return o
+}
+
+// SetBatchSize sets the number of logs to export at once. If batchSize is less than 1, it is set to 1.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetBatchSize(batchSize int) *OTLPOutput {
+	o.batcher.setBatchSize(batchSize)
+	return o
+}
+
+// SetFlushInterval sets the maximum duration to wait before exporting a batch which is not full.
+// It takes effect after the next flush. If flushInterval is less or equal than 0, the batch is only exported when full.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetFlushInterval(flushInterval time.Duration) *OTLPOutput {
+	o.batcher.setFlushInterval(flushInterval)
+	return o
+}
+
+// SetMaxPending sets the maximum number of logs waiting to be exported. The logs beyond it are dropped with
+// ErrQueueFull. If maxPending is less or equal than 0, there is no limit.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetMaxPending(maxPending int) *OTLPOutput {
+	o.batcher.setMaxPending(maxPending)
+	return o
+}
+
+// SetTimeout sets the timeout of each export. If timeout is less or equal than 0, exports don't time out.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetTimeout(timeout time.Duration) *OTLPOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timeout = timeout
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying OTLPOutput.
+func (o *OTLPOutput) SetOnError(f func(error)) *OTLPOutput {
+	o.batcher.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *OTLPOutput) Err() error {
+	return o.batcher.err()
+}
+
+// Healthy reports whether the last batch has been exported successfully.
+// It returns true if no batch has been exported yet.
+func (o *OTLPOutput) Healthy() bool {
+	return o.batcher.healthy()
+}
+
+// otlpSeverityNumber returns the OTLP severity number by the given Severity.
+func otlpSeverityNumber(severity Severity) int {
+	switch severity {
+	case SeverityEmergency:
+		return 24
+	case SeverityAlert:
+		return 22
+	case SeverityFatal:
+		return 21
+	case SeverityPanic:
+		return 20
+	case SeverityCritical:
+		return 19
+	case SeverityError:
+		return 17
+	case SeverityWarning:
+		return 13
+	case SeverityNotice:
+		return 11
+	case SeverityInfo:
+		return 9
+	case SeverityDebug:
+		return 5
+	default:
+		return 0
+	}
+}
+
+type otlpExportLogsServiceRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// setDouble sets the double value, or the string value for NaN and infinities which json can't encode.
+func (v *otlpAnyValue) setDouble(x float64) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		v.StringValue = stringPtr(strconv.FormatFloat(x, 'g', -1, 64))
+		return
+	}
+	v.DoubleValue = &x
+}
+
+func otlpAttribute(key string, value interface{}) otlpKeyValue {
+	kv := otlpKeyValue{Key: key}
+	switch v := value.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case bool:
+		kv.Value.BoolValue = &v
+	case int:
+		kv.Value.IntValue = stringPtr(strconv.FormatInt(int64(v), 10))
+	case int8:
+		kv.Value.IntValue = stringPtr(strconv.FormatInt(int64(v), 10))
+	case int16:
+		kv.Value.IntValue = stringPtr(strconv.FormatInt(int64(v), 10))
+	case int32:
+		kv.Value.IntValue = stringPtr(strconv.FormatInt(int64(v), 10))
+	case int64:
+		kv.Value.IntValue = stringPtr(strconv.FormatInt(v, 10))
+	case uint:
+		kv.Value.IntValue = stringPtr(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		kv.Value.IntValue = stringPtr(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		kv.Value.IntValue = stringPtr(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		kv.Value.IntValue = stringPtr(strconv.FormatUint(uint64(v), 10))
+	case float32:
+		kv.Value.setDouble(float64(v))
+	case float64:
+		kv.Value.setDouble(v)
+	case error:
+		kv.Value.StringValue = stringPtr(v.Error())
+	default:
+		kv.Value.StringValue = stringPtr(fmt.Sprintf("%v", v))
+	}
+	return kv
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -2,101 +2,440 @@ package logng
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 // Output is an interface for Logger output.
 // All of Output implementations must be safe for concurrency.
+// An Output may optionally implement io.Closer to release its resources, and Flusher to write its buffered data.
+// The wrapper outputs, such as MultiOutput and QueuedOutput, propagate Close and Flush to their outputs.
 type Output interface {
 	Log(log *Log)
 }
 
+// Discard is an Output that discards all logs without any allocation.
+// It is useful for benchmarks, and for disabling an output without nil checks.
+var Discard Output = discardOutput{}
+
+type discardOutput struct{}
+
+func (discardOutput) Log(*Log) {}
+
 type multiOutput []Output
 
 func (o multiOutput) Log(log *Log) {
 	for _, o1 := range o {
-		o1.Log(log)
+		safeLog(o1, log)
+	}
+}
+
+func (o multiOutput) LogBatch(logs []*Log) {
+	for _, o1 := range o {
+		safeLogBatch(o1, logs)
 	}
 }
 
+func (o multiOutput) Flush() error {
+	return flushOutputs(o)
+}
+
+func (o multiOutput) Close() error {
+	return closeOutputs(o)
+}
+
 // MultiOutput creates an output that clones its logs to all the provided outputs.
+// If an output panics, the panic is recovered and reported to the error handler as ErrOutputPanic,
+// and the log is still delivered to the rest of the outputs.
 func MultiOutput(outputs ...Output) Output {
 	o := make(multiOutput, len(outputs))
 	copy(o, outputs)
 	return o
 }
 
+// ConcurrentMultiOutput is an Output implementation that clones its logs to all the provided outputs concurrently.
+// So, one slow output doesn't delay the others.
+type ConcurrentMultiOutput struct {
+	outputs  []Output
+	onLogged *func(Output, time.Duration)
+}
+
+// NewConcurrentMultiOutput creates a new ConcurrentMultiOutput by the given outputs.
+func NewConcurrentMultiOutput(outputs ...Output) *ConcurrentMultiOutput {
+	o := &ConcurrentMultiOutput{
+		outputs: make([]Output, len(outputs)),
+	}
+	copy(o.outputs, outputs)
+	return o
+}
+
+// Log is the implementation of Output.
+// Log dispatches the log to every output in a separate goroutine, and waits until all of them have finished.
+// The outputs must not modify the log.
+// If an output panics, the panic is recovered and reported to the error handler as ErrOutputPanic.
+func (o *ConcurrentMultiOutput) Log(log *Log) {
+	onLogged := (*func(Output, time.Duration))(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&o.onLogged))))
+	var wg sync.WaitGroup
+	wg.Add(len(o.outputs))
+	for _, o1 := range o.outputs {
+		go func(o1 Output) {
+			defer wg.Done()
+			start := time.Now()
+			safeLog(o1, log)
+			if onLogged != nil && *onLogged != nil {
+				(*onLogged)(o1, time.Since(start))
+			}
+		}(o1)
+	}
+	wg.Wait()
+}
+
+// Flush is the implementation of Flusher. It flushes all the outputs, and returns the first error.
+func (o *ConcurrentMultiOutput) Flush() error {
+	return flushOutputs(o.outputs)
+}
+
+// Close is the implementation of io.Closer. It closes all the outputs, and returns the first error.
+func (o *ConcurrentMultiOutput) Close() error {
+	return closeOutputs(o.outputs)
+}
+
+// SetOnLogged sets a function to call with the elapsed time when an output has finished logging.
+// It can be used to collect per-output timing metrics.
+// It returns the underlying ConcurrentMultiOutput.
+func (o *ConcurrentMultiOutput) SetOnLogged(f func(output Output, elapsed time.Duration)) *ConcurrentMultiOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onLogged)), unsafe.Pointer(&f))
+	return o
+}
+
+type timeoutOutput struct {
+	output   Output
+	timeout  time.Duration
+	inFlight chan struct{}
+}
+
+func (o *timeoutOutput) Log(log *Log) {
+	select {
+	case o.inFlight <- struct{}{}:
+	default:
+		handleError(fmt.Errorf("%w: log dropped, because %d deliveries are in flight",
+			ErrOutputTimeout, cap(o.inFlight)))
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer func() { <-o.inFlight }()
+		defer close(done)
+		safeLog(o.output, log)
+	}()
+	timer := time.NewTimer(o.timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		handleError(fmt.Errorf("%w: log dropped after %v", ErrOutputTimeout, o.timeout))
+	}
+}
+
+func (o *timeoutOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+func (o *timeoutOutput) Close() error {
+	return closeOutput(o.output)
+}
+
+// TimeoutOutput creates an output that bounds how long a single log delivery to the given output may take.
+// Every log is delivered in a separate goroutine. If the delivery doesn't finish in the given timeout,
+// Log returns and the log is reported as dropped to the error handler as ErrOutputTimeout.
+// The delivery of the dropped log isn't canceled and it continues in the background.
+// It is same as TimeoutOutputWithLimit with the limit of 100 deliveries in flight.
+func TimeoutOutput(output Output, timeout time.Duration) Output {
+	return TimeoutOutputWithLimit(output, timeout, 100)
+}
+
+// TimeoutOutputWithLimit is similar to TimeoutOutput, but it limits the deliveries in flight including the ones
+// continuing in the background after the timeout. While the limit is reached, e.g. the output hangs, the logs are
+// dropped immediately and reported to the error handler as ErrOutputTimeout. So, a hanging output can't leak
+// goroutines without limit. If maxInFlight is less than 1, it is 1.
+func TimeoutOutputWithLimit(output Output, timeout time.Duration, maxInFlight int) Output {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &timeoutOutput{
+		output:   output,
+		timeout:  timeout,
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+}
+
+// safeLog calls output.Log by recovering panics and reporting them to the error handler.
+func safeLog(output Output, log *Log) {
+	defer func() {
+		if r := recover(); r != nil {
+			handleError(fmt.Errorf("%w: %v", ErrOutputPanic, r))
+		}
+	}()
+	output.Log(log)
+}
+
 // QueuedOutput is intermediate Output implementation between Logger and given Output.
 // QueuedOutput has queueing for unblocking Log() method.
 type QueuedOutput struct {
-	output      Output
-	queue       chan *Log
-	closing     int32
-	wg          sync.WaitGroup
-	logWg       sync.WaitGroup
-	blocking    uint32
-	onQueueFull *func()
+	// the 64-bit fields which are accessed atomically must be at the start of the struct,
+	// to be 64-bit aligned on 32-bit platforms.
+	enqueued       uint64
+	delivered      uint64
+	dropped        uint64
+	spilled        uint64
+	spillDropped   uint64
+	spillMaxSize   int64
+	batchSize      int64
+	batchWait      int64
+	blockWait      int64
+	output         Output
+	queue          chan *Log
+	closing        int32
+	closeOnce      sync.Once
+	closeDone      chan struct{}
+	closeErr       error
+	wg             sync.WaitGroup
+	logWg          sync.WaitGroup
+	overflow       uint32
+	onQueueFull    *func()
+	onQueueFullLog *func(dropped uint64, log *Log)
+	pendingMu      sync.Mutex
+	pending        int
+	idle           chan struct{}
+	flushChs       []chan struct{}
+	spill          *queueSpill
+	startOnce      sync.Once
+	workers        int
+	orderKey       string
+	closeOutput    bool
 }
 
 // NewQueuedOutput creates a new QueuedOutput by the given output.
 func NewQueuedOutput(output Output, queueLen int) (o *QueuedOutput) {
 	o = &QueuedOutput{
-		output: output,
-		queue:  make(chan *Log, queueLen),
+		output:  output,
+		queue:   make(chan *Log, queueLen),
+		idle:    make(chan struct{}),
+		workers: 1,
 	}
-	o.wg.Add(1)
-	go o.worker()
+	close(o.idle)
 	return
 }
 
-// Close stops accepting new logs to the underlying QueuedOutput and waits for the queue to empty.
+// start starts the workers, and the dispatcher in the ordered mode.
+func (o *QueuedOutput) start() {
+	n := o.workers
+	o.flushChs = make([]chan struct{}, n)
+	for i := range o.flushChs {
+		o.flushChs[i] = make(chan struct{}, 1)
+	}
+	if n <= 1 || o.orderKey == "" {
+		o.wg.Add(n)
+		for i := 0; i < n; i++ {
+			go o.worker(o.queue, o.flushChs[i])
+		}
+		return
+	}
+	queues := make([]chan *Log, n)
+	for i := range queues {
+		queues[i] = make(chan *Log, cap(o.queue)/n+1)
+	}
+	o.wg.Add(1 + n)
+	go o.dispatcher(queues)
+	for i := 0; i < n; i++ {
+		go o.worker(queues[i], o.flushChs[i])
+	}
+}
+
+// Close stops accepting new logs to the underlying QueuedOutput, waits for the queue to empty,
+// and then closes the output if SetCloseOutput has been enabled and the output implements io.Closer.
 // Unused QueuedOutput must be closed for freeing resources.
 func (o *QueuedOutput) Close() error {
-	if !atomic.CompareAndSwapInt32(&o.closing, 0, 1) {
-		return nil
+	return o.CloseContext(context.Background())
+}
+
+// CloseContext is similar to Close, but it returns the error of ctx if ctx is done before the queue is emptied and
+// the output is closed. In that case, the remaining logs continue to be passed to the output in the background,
+// and the output is closed after them. The later calls of Close or CloseContext wait for the same closing.
+func (o *QueuedOutput) CloseContext(ctx context.Context) error {
+	o.closeOnce.Do(func() {
+		o.closeDone = make(chan struct{})
+		atomic.StoreInt32(&o.closing, 1)
+		go func() {
+			defer close(o.closeDone)
+			o.closeErr = o.close()
+		}()
+	})
+	select {
+	case <-o.closeDone:
+		return o.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
+
+// CloseWithTimeout is similar to CloseContext with a context which is done after the given timeout.
+func (o *QueuedOutput) CloseWithTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return o.CloseContext(ctx)
+}
+
+// close waits for the queue to empty, and closes the output and the spill file.
+func (o *QueuedOutput) close() error {
+	o.startOnce.Do(o.start)
 	o.logWg.Wait()
+	if o.spill != nil {
+		close(o.spill.stop)
+		o.spill.wg.Wait()
+	}
 	close(o.queue)
 	o.wg.Wait()
-	return nil
+	var err error
+	if o.closeOutput {
+		err = closeOutput(o.output)
+	}
+	if o.spill != nil {
+		if e := o.spill.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
 }
 
 // Log is the implementation of Output.
-// If blocking is true, Log method blocks execution until the underlying output has finished execution.
-// Otherwise, Log method sends the log to the queue if the queue is available.
-// When the queue is full, it tries to call OnQueueFull function.
+// Log method sends the log to the queue. When the queue is full, it writes the log to the spill file if it is set,
+// or drops it if the spill file is full, otherwise it behaves by the overflow policy, and tries to call
+// OnQueueFull and OnQueueFullLog functions for each dropped log.
 func (o *QueuedOutput) Log(log *Log) {
 	o.logWg.Add(1)
 	defer o.logWg.Done()
-	if o.closing != 0 {
+	if atomic.LoadInt32(&o.closing) != 0 {
 		return
 	}
-	if o.blocking != 0 {
-		o.queue <- log
+	o.startOnce.Do(o.start)
+	o.addPending(1)
+	if o.spill != nil && o.spill.len() > 0 && o.spillLog(log) {
 		return
 	}
 	select {
 	case o.queue <- log:
+		atomic.AddUint64(&o.enqueued, 1)
+		return
 	default:
-		onQueueFull := o.onQueueFull
-		if onQueueFull != nil && *onQueueFull != nil {
-			(*onQueueFull)()
+	}
+	if o.spill != nil && o.spillLog(log) {
+		return
+	}
+	switch QueueOverflowPolicy(atomic.LoadUint32(&o.overflow)) {
+	case QueueOverflowBlock:
+		timeout := time.Duration(atomic.LoadInt64(&o.blockWait))
+		if timeout <= 0 {
+			o.queue <- log
+			atomic.AddUint64(&o.enqueued, 1)
+			return
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case o.queue <- log:
+			atomic.AddUint64(&o.enqueued, 1)
+			return
+		case <-timer.C:
+		}
+	case QueueOverflowDropOldest:
+		select {
+		case oldest := <-o.queue:
+			o.addPending(-1)
+			o.queueFull(oldest)
+		default:
 		}
+		select {
+		case o.queue <- log:
+			atomic.AddUint64(&o.enqueued, 1)
+			return
+		default:
+		}
+	}
+	o.addPending(-1)
+	o.queueFull(log)
+}
+
+// queueFull counts the given dropped log, and calls OnQueueFull and OnQueueFullLog functions if they are set.
+func (o *QueuedOutput) queueFull(log *Log) {
+	dropped := atomic.AddUint64(&o.dropped, 1)
+	onQueueFull := (*func())(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&o.onQueueFull))))
+	if onQueueFull != nil && *onQueueFull != nil {
+		(*onQueueFull)()
+	}
+	onQueueFullLog := (*func(uint64, *Log))(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&o.onQueueFullLog))))
+	if onQueueFullLog != nil && *onQueueFullLog != nil {
+		(*onQueueFullLog)(dropped, log)
+	}
+}
+
+// Flush blocks until the queue is drained, and then flushes the output if it implements Flusher.
+// Unlike Close, the underlying QueuedOutput continues accepting new logs. The logs which are queued after
+// the call may be waited too. If ctx is done before the queue is drained, it returns the error of ctx.
+func (o *QueuedOutput) Flush(ctx context.Context) error {
+	o.startOnce.Do(o.start)
+	for _, flushCh := range o.flushChs {
+		select {
+		case flushCh <- struct{}{}:
+		default:
+		}
+	}
+	select {
+	case <-o.idleCh():
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+	return flushOutput(o.output)
 }
 
 // SetBlocking sets QueuedOutput behavior when the queue is full.
+// If blocking is true, it is same with SetOverflowPolicy(QueueOverflowBlock, 0).
+// Otherwise, it is same with SetOverflowPolicy(QueueOverflowDropNewest, 0).
 // It returns the underlying QueuedOutput.
 func (o *QueuedOutput) SetBlocking(blocking bool) *QueuedOutput {
-	var b uint32
 	if blocking {
-		b = 1
+		return o.SetOverflowPolicy(QueueOverflowBlock, 0)
 	}
-	atomic.StoreUint32(&o.blocking, b)
+	return o.SetOverflowPolicy(QueueOverflowDropNewest, 0)
+}
+
+// SetOverflowPolicy sets QueuedOutput behavior when the queue is full. The timeout is used only by
+// QueueOverflowBlock, and Log blocks without timeout if it is less or equal than 0.
+// It returns the underlying QueuedOutput.
+// By default, QueueOverflowDropNewest.
+func (o *QueuedOutput) SetOverflowPolicy(policy QueueOverflowPolicy, timeout time.Duration) *QueuedOutput {
+	atomic.StoreInt64(&o.blockWait, int64(timeout))
+	atomic.StoreUint32(&o.overflow, uint32(policy))
+	return o
+}
+
+// SetBatch sets the batch mode of the underlying QueuedOutput. The worker collects up to size logs, or waits up to
+// interval after the first log of the batch, then passes the batch to the output at once. If the output implements
+// BatchOutput, the batch is passed to LogBatch, e.g. for a single write or request. Otherwise, the logs are passed
+// to Log one by one.
+// If interval is less or equal than 0, the batch is passed as soon as the queue is empty, without waiting.
+// If size is less or equal than 1, the batch mode is disabled.
+// It returns the underlying QueuedOutput.
+// By default, the batch mode is disabled.
+func (o *QueuedOutput) SetBatch(size int, interval time.Duration) *QueuedOutput {
+	atomic.StoreInt64(&o.batchSize, int64(size))
+	atomic.StoreInt64(&o.batchWait, int64(interval))
 	return o
 }
 
@@ -107,46 +446,269 @@ func (o *QueuedOutput) SetOnQueueFull(f func()) *QueuedOutput {
 	return o
 }
 
-func (o *QueuedOutput) worker() {
+// SetOnQueueFullLog sets a function to call when a log is dropped because the queue is full.
+// The function receives the total count of the dropped logs including this one, and the dropped log.
+// It is called after the OnQueueFull function.
+// It returns the underlying QueuedOutput.
+func (o *QueuedOutput) SetOnQueueFullLog(f func(dropped uint64, log *Log)) *QueuedOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onQueueFullLog)), unsafe.Pointer(&f))
+	return o
+}
+
+// SetCloseOutput sets whether Close closes the output after the queue is emptied. So, the QueuedOutput owns
+// the output, e.g. when the output tree is closed by Logger.Close.
+// SetCloseOutput must be called before closing.
+// It returns the underlying QueuedOutput.
+// By default, false.
+func (o *QueuedOutput) SetCloseOutput(closeOutput bool) *QueuedOutput {
+	o.closeOutput = closeOutput
+	return o
+}
+
+// SetWorkers sets the count of the worker goroutines which pass the logs to the output concurrently.
+// So, a slow output can be parallelized. The output must be safe for concurrency, as all of Output implementations.
+// If orderKey is empty, the order of the logs isn't preserved. Otherwise, the logs which have the same value of
+// the field with orderKey are passed by the same worker, so their order is preserved, e.g. per request by a field
+// such as "request_id". The logs without the field are passed by the first worker.
+// If workers is less than 1, it is set to 1. In the batch mode, each worker collects its own batches.
+// SetWorkers must be called before logging.
+// It returns the underlying QueuedOutput.
+// By default, 1.
+func (o *QueuedOutput) SetWorkers(workers int, orderKey string) *QueuedOutput {
+	if workers < 1 {
+		workers = 1
+	}
+	o.workers = workers
+	o.orderKey = orderKey
+	return o
+}
+
+// dispatcher passes the logs in the queue to the worker queues by the value of the field with orderKey.
+func (o *QueuedOutput) dispatcher(queues []chan *Log) {
 	defer o.wg.Done()
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+	}()
+	n := uint32(len(queues))
 	for log := range o.queue {
-		o.output.Log(log)
+		idx := uint32(0)
+		for i := len(log.Fields) - 1; i >= 0; i-- {
+			if field := log.Fields[i]; field.Key == o.orderKey {
+				h := fnv.New32a()
+				_, _ = fmt.Fprintf(h, "%v", field.Value)
+				idx = h.Sum32() % n
+				break
+			}
+		}
+		queues[idx] <- log
 	}
 }
 
+func (o *QueuedOutput) worker(queue <-chan *Log, flushCh <-chan struct{}) {
+	defer o.wg.Done()
+	var batch []*Log
+	var timer *time.Timer
+	var timerC <-chan time.Time
+	flushing := false
+	dispatch := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		flushing = false
+		if len(batch) <= 0 {
+			return
+		}
+		logBatch(o.output, batch)
+		atomic.AddUint64(&o.delivered, uint64(len(batch)))
+		o.addPending(-len(batch))
+		batch = nil
+	}
+	for {
+		select {
+		case log, ok := <-queue:
+			if !ok {
+				dispatch()
+				return
+			}
+			batch = append(batch, log)
+			size, interval := int(atomic.LoadInt64(&o.batchSize)), time.Duration(atomic.LoadInt64(&o.batchWait))
+			if len(batch) >= size || ((interval <= 0 || flushing) && len(queue) <= 0) {
+				dispatch()
+				break
+			}
+			if timerC == nil && interval > 0 {
+				timer = time.NewTimer(interval)
+				timerC = timer.C
+			}
+		case <-timerC:
+			timer, timerC = nil, nil
+			dispatch()
+		case <-flushCh:
+			if len(queue) <= 0 {
+				dispatch()
+				break
+			}
+			flushing = true
+		}
+	}
+}
+
+// Stats returns the statistics of the underlying QueuedOutput.
+func (o *QueuedOutput) Stats() QueuedOutputStats {
+	return QueuedOutputStats{
+		Enqueued:     atomic.LoadUint64(&o.enqueued),
+		Delivered:    atomic.LoadUint64(&o.delivered),
+		Dropped:      atomic.LoadUint64(&o.dropped),
+		Spilled:      atomic.LoadUint64(&o.spilled),
+		SpillDropped: atomic.LoadUint64(&o.spillDropped),
+		Depth:        len(o.queue),
+	}
+}
+
+// addPending adds n to the count of the logs which are queued but not yet written.
+// The idle channel is closed when the count becomes zero.
+func (o *QueuedOutput) addPending(n int) {
+	o.pendingMu.Lock()
+	defer o.pendingMu.Unlock()
+	if o.pending == 0 && n > 0 {
+		o.idle = make(chan struct{})
+	}
+	o.pending += n
+	if o.pending == 0 {
+		close(o.idle)
+	}
+}
+
+// idleCh returns a channel which is closed when the queue is drained.
+func (o *QueuedOutput) idleCh() <-chan struct{} {
+	o.pendingMu.Lock()
+	defer o.pendingMu.Unlock()
+	return o.idle
+}
+
+// QueuedOutputStats holds the statistics of QueuedOutput.
+type QueuedOutputStats struct {
+	// Enqueued is the count of the logs which have been sent to the queue, including the replayed logs
+	// from the spill file.
+	Enqueued uint64
+
+	// Delivered is the count of the logs which have been passed to the output.
+	Delivered uint64
+
+	// Dropped is the count of the logs which have been dropped because the queue is full,
+	// including SpillDropped.
+	Dropped uint64
+
+	// Spilled is the count of the logs which have been written to the spill file.
+	Spilled uint64
+
+	// SpillDropped is the count of the logs which have been dropped because the spill file is full.
+	SpillDropped uint64
+
+	// Depth is the current count of the logs in the queue.
+	Depth int
+}
+
+// QueueOverflowPolicy describes the behavior of QueuedOutput when the queue is full.
+type QueueOverflowPolicy int
+
+const (
+	// QueueOverflowDropNewest drops the new log.
+	QueueOverflowDropNewest QueueOverflowPolicy = iota
+
+	// QueueOverflowDropOldest drops the oldest log in the queue to keep the new log.
+	// So, the most recent logs are kept.
+	QueueOverflowDropOldest
+
+	// QueueOverflowBlock blocks Log until the queue is available, or the timeout elapses.
+	// The new log is dropped when the timeout elapses.
+	QueueOverflowBlock
+)
+
 // TextOutput is an implementation of Output by writing texts to io.Writer w.
 type TextOutput struct {
-	mu      sync.RWMutex
-	w       io.Writer
-	flags   TextOutputFlag
-	onError *func(error)
+	mu             sync.RWMutex
+	w              io.Writer
+	flags          TextOutputFlag
+	severityLabels map[Severity]string
+	locale         string
+	timeFormatter  func(time.Time, string) string
+	severityColors map[Severity]string
+	cw             io.Writer
+	color          bool
+	colorOverride  *bool
+	health
 }
 
 // NewTextOutput creates a new TextOutput.
 func NewTextOutput(w io.Writer, flags TextOutputFlag) *TextOutput {
-	return &TextOutput{
+	o := &TextOutput{
 		w:     w,
 		flags: flags,
 	}
+	o.resetColor()
+	return o
 }
 
 // Log is the implementation of Output.
 func (o *TextOutput) Log(log *Log) {
+	o.write([]*Log{log})
+}
+
+// LogBatch is the implementation of BatchOutput. It writes the logs by a single write.
+func (o *TextOutput) LogBatch(logs []*Log) {
+	o.write(logs)
+}
+
+// write formats the given logs and writes them to the writer by a single write.
+func (o *TextOutput) write(logs []*Log) {
 	var err error
 	defer func() {
-		onError := o.onError
-		if err == nil || onError == nil || *onError == nil {
-			return
-		}
-		(*onError)(err)
+		o.report(err)
 	}()
 
 	o.mu.RLock()
 	defer o.mu.RUnlock()
 
-	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	buf := bytes.NewBuffer(make([]byte, 0, 4096*len(logs)))
+	for _, log := range logs {
+		o.format(buf, log)
+	}
+
+	_, err = io.Copy(o.cw, buf)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
 
-	if o.flags&(TextOutputFlagDate|TextOutputFlagTime|TextOutputFlagMicroseconds) != 0 {
+// format formats the given log into buf by the underlying TextOutput's flags.
+func (o *TextOutput) format(buf *bytes.Buffer, log *Log) {
+	if o.timeFormatter != nil && o.flags&(TextOutputFlagDate|TextOutputFlagTime|TextOutputFlagMicroseconds|
+		TextOutputFlagRFC3339|TextOutputFlagRFC3339Milli) != 0 {
+		tm := log.Time.Local()
+		if o.flags&TextOutputFlagUTC != 0 {
+			tm = tm.UTC()
+		}
+		buf.WriteString(o.timeFormatter(tm, o.locale))
+		buf.WriteRune(' ')
+	} else if o.flags&(TextOutputFlagRFC3339|TextOutputFlagRFC3339Milli) != 0 {
+		tm := log.Time.Local()
+		if o.flags&TextOutputFlagUTC != 0 {
+			tm = tm.UTC()
+		}
+		layout := "2006-01-02T15:04:05Z07:00"
+		if o.flags&TextOutputFlagRFC3339Milli != 0 {
+			layout = "2006-01-02T15:04:05.000Z07:00"
+		}
+		b := make([]byte, 0, 128)
+		b = tm.AppendFormat(b, layout)
+		b = append(b, ' ')
+		buf.Write(b)
+	} else if o.flags&(TextOutputFlagDate|TextOutputFlagTime|TextOutputFlagMicroseconds) != 0 {
 		tm := log.Time.Local()
 		if o.flags&TextOutputFlagUTC != 0 {
 			tm = tm.UTC()
@@ -177,14 +739,39 @@ func (o *TextOutput) Log(log *Log) {
 		buf.Write(b)
 	}
 
+	if o.flags&TextOutputFlagUptime != 0 {
+		b := make([]byte, 0, 32)
+		appendUptime(&b, logUptime(log))
+		b = append(b, ' ')
+		buf.Write(b)
+	}
+
+	escapes := 0
 	if o.flags&TextOutputFlagSeverity != 0 {
-		buf.WriteString(log.Severity.String())
+		color := ""
+		if o.flags&TextOutputFlagColor != 0 && o.color {
+			color = o.severityColor(log.Severity)
+		}
+		if color != "" {
+			n := buf.Len()
+			buf.WriteString("\x1b[" + color + "m")
+			escapes += buf.Len() - n
+		}
+		if label, ok := o.severityLabels[log.Severity]; ok {
+			buf.WriteString(label)
+		} else {
+			buf.WriteString(log.Severity.String())
+		}
+		if color != "" {
+			buf.WriteString("\x1b[0m")
+			escapes += len("\x1b[0m")
+		}
 		buf.WriteString(" - ")
 	}
 
 	var padding []byte
 	if o.flags&TextOutputFlagPadding != 0 {
-		padding = bytes.Repeat([]byte(" "), buf.Len())
+		padding = bytes.Repeat([]byte(" "), buf.Len()-escapes)
 	}
 
 	if o.flags&(TextOutputFlagLongFunc|TextOutputFlagShortFunc) != 0 {
@@ -239,11 +826,11 @@ func (o *TextOutput) Log(log *Log) {
 		extend()
 		buf.WriteRune('\t')
 		buf.WriteString("+ ")
-		for idx, field := range log.Fields {
+		for idx, field := range log.Fields.Flatten() {
 			if idx > 0 {
 				buf.WriteRune(' ')
 			}
-			buf.WriteString(fmt.Sprintf("%q=%q", field.Key, fmt.Sprintf("%v", field.Value)))
+			buf.WriteString(fmt.Sprintf("%q=%q", field.Key, fmt.Sprintf("%v", fieldValue(field.Value))))
 		}
 		buf.WriteString("\n\t")
 		buf.WriteRune('\n')
@@ -259,12 +846,13 @@ func (o *TextOutput) Log(log *Log) {
 		buf.WriteString("\n\t")
 		buf.WriteRune('\n')
 	}
+}
 
-	_, err = io.Copy(o.w, buf)
-	if err != nil {
-		err = fmt.Errorf("unable to write to writer: %w", err)
-		return
-	}
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *TextOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
 }
 
 // SetWriter sets writer.
@@ -273,6 +861,7 @@ func (o *TextOutput) SetWriter(w io.Writer) *TextOutput {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 	o.w = w
+	o.resetColor()
 	return o
 }
 
@@ -282,16 +871,140 @@ func (o *TextOutput) SetFlags(flags TextOutputFlag) *TextOutput {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 	o.flags = flags
+	o.resetColor()
 	return o
 }
 
 // SetOnError sets a function to call when error occurs.
 // It returns the underlying TextOutput.
 func (o *TextOutput) SetOnError(f func(error)) *TextOutput {
-	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onError)), unsafe.Pointer(&f))
+	o.setOnError(f)
+	return o
+}
+
+// SetSeverityLabels sets labels to print instead of severity names, e.g. for localization.
+// Severities which don't have a label are printed by their names.
+// It returns the underlying TextOutput.
+func (o *TextOutput) SetSeverityLabels(labels map[Severity]string) *TextOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.severityLabels = make(map[Severity]string, len(labels))
+	for severity, label := range labels {
+		o.severityLabels[severity] = label
+	}
+	return o
+}
+
+// SetSeverityColors sets ANSI SGR parameters to color the severities by TextOutputFlagColor, e.g. "1;31" for bold red.
+// Severities which don't have a color are printed by the default colors. An empty color disables coloring the severity.
+// It returns the underlying TextOutput.
+func (o *TextOutput) SetSeverityColors(colors map[Severity]string) *TextOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.severityColors = make(map[Severity]string, len(colors))
+	for severity, color := range colors {
+		o.severityColors[severity] = color
+	}
+	return o
+}
+
+// SetColor sets whether the severities are colored by TextOutputFlagColor, overriding the detection by the writer
+// and the environment variable NO_COLOR. It's kept when the writer is changed by SetWriter.
+// It returns the underlying TextOutput.
+func (o *TextOutput) SetColor(color bool) *TextOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.colorOverride = &color
+	o.resetColor()
+	return o
+}
+
+// reset clears the severity labels, the severity colors, the color override, the locale and the time formatter.
+func (o *TextOutput) reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.severityLabels = nil
+	o.severityColors = nil
+	o.colorOverride = nil
+	o.locale = ""
+	o.timeFormatter = nil
+	o.resetColor()
+}
+
+// resetColor sets the writer to write colored text and whether colors are enabled. The writer is detected by
+// colorWriter only if TextOutputFlagColor is set, so plain outputs don't change the console mode on Windows.
+// It must be called with o.mu held.
+func (o *TextOutput) resetColor() {
+	o.cw, o.color = o.w, false
+	if o.flags&TextOutputFlagColor != 0 {
+		o.cw, o.color = colorWriter(o.w)
+	}
+	if o.colorOverride != nil {
+		o.color = *o.colorOverride
+	}
+}
+
+// severityColor returns the ANSI SGR parameters of the given severity.
+func (o *TextOutput) severityColor(severity Severity) string {
+	if color, ok := o.severityColors[severity]; ok {
+		return color
+	}
+	return defaultSeverityColor(severity)
+}
+
+// defaultSeverityColor returns the default ANSI SGR parameters of the given severity.
+func defaultSeverityColor(severity Severity) string {
+	switch severity {
+	case SeverityEmergency, SeverityAlert:
+		return "1;35"
+	case SeverityFatal, SeverityPanic, SeverityCritical:
+		return "1;31"
+	case SeverityError:
+		return "31"
+	case SeverityWarning:
+		return "33"
+	case SeverityNotice:
+		return "36"
+	case SeverityInfo:
+		return "32"
+	case SeverityDebug:
+		return "90"
+	default:
+		return ""
+	}
+}
+
+// SetLocale sets the locale which is passed to the time formatter.
+// It returns the underlying TextOutput.
+func (o *TextOutput) SetLocale(locale string) *TextOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.locale = locale
+	return o
+}
+
+// SetTimeFormatter sets a function to format the time instead of the flags TextOutputFlagDate, TextOutputFlagTime,
+// TextOutputFlagMicroseconds and TextOutputFlagRFC3339. The time is printed if any of these flags is set.
+// The function receives the time in the local time zone or UTC by TextOutputFlagUTC, and the locale set by SetLocale.
+// It returns the underlying TextOutput.
+func (o *TextOutput) SetTimeFormatter(f func(tm time.Time, locale string) string) *TextOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timeFormatter = f
 	return o
 }
 
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *TextOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *TextOutput) Healthy() bool {
+	return o.healthy()
+}
+
 // TextOutputFlag holds single or multiple flags of TextOutput.
 // A TextOutput instance uses these flags which are stored by TextOutputFlag type.
 type TextOutputFlag int
@@ -307,7 +1020,7 @@ const (
 	// assumes TextOutputFlagTime.
 	TextOutputFlagMicroseconds
 
-	// TextOutputFlagUTC uses UTC rather than the local time zone if TextOutputFlagDate or TextOutputFlagTime is set.
+	// TextOutputFlagUTC uses UTC rather than the local time zone if TextOutputFlagDate, TextOutputFlagTime or TextOutputFlagRFC3339 is set.
 	TextOutputFlagUTC
 
 	// TextOutputFlagSeverity prints the severity.
@@ -340,6 +1053,22 @@ const (
 	// assumes TextOutputFlagStackTrace.
 	TextOutputFlagStackTraceShortFile
 
+	// TextOutputFlagRFC3339 prints the date and time in the local time zone as RFC3339: 2009-01-23T01:23:23+03:00.
+	// overrides TextOutputFlagDate, TextOutputFlagTime and TextOutputFlagMicroseconds.
+	TextOutputFlagRFC3339
+
+	// TextOutputFlagRFC3339Milli prints RFC3339 with millisecond resolution: 2009-01-23T01:23:23.123+03:00.
+	// assumes TextOutputFlagRFC3339.
+	TextOutputFlagRFC3339Milli
+
+	// TextOutputFlagColor prints the severity in ANSI colors.
+	// colors are disabled if the writer isn't a terminal, or the environment variable NO_COLOR is set,
+	// unless they are overridden by TextOutput.SetColor.
+	TextOutputFlagColor
+
+	// TextOutputFlagUptime prints the elapsed seconds since the process start after the time: [12.345678].
+	TextOutputFlagUptime
+
 	// TextOutputFlagDefault holds predefined default flags.
 	// it used by the default Logger.
 	TextOutputFlagDefault = TextOutputFlagDate | TextOutputFlagTime | TextOutputFlagSeverity |
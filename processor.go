@@ -0,0 +1,51 @@
+package logng
+
+// Processor is the interface that wraps the Process method, which transforms the logs before the output,
+// e.g. for redaction, enrichment or truncation.
+//
+// Process returns the transformed log, or nil to drop the log. The given log may be shared by the other outputs,
+// so Process should modify a clone of the log instead of the given log.
+type Processor interface {
+	Process(log *Log) *Log
+}
+
+// ProcessorFunc is an adapter to allow the use of ordinary functions as Processor.
+type ProcessorFunc func(log *Log) *Log
+
+// Process is the implementation of Processor.
+func (f ProcessorFunc) Process(log *Log) *Log {
+	return f(log)
+}
+
+type chainOutput struct {
+	output     Output
+	processors []Processor
+}
+
+func (o *chainOutput) Log(log *Log) {
+	for _, p := range o.processors {
+		if log = p.Process(log); log == nil {
+			return
+		}
+	}
+	o.output.Log(log)
+}
+
+func (o *chainOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+func (o *chainOutput) Close() error {
+	return closeOutput(o.output)
+}
+
+// ChainOutput creates an output that passes its logs to the given output after processing them by the given
+// processors in order. So, the same processors can be composed and reused across different outputs.
+func ChainOutput(output Output, processors ...Processor) Output {
+	o := &chainOutput{
+		output:     output,
+		processors: make([]Processor, len(processors)),
+	}
+	copy(o.processors, processors)
+	return o
+}
@@ -0,0 +1,174 @@
+package logng
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitOutput is an implementation of Output by limiting the number of logs per key in each interval,
+// to protect the given output from log storms. The key of a log is its message by default, or the value of
+// the field with the configured key field.
+//
+// Keying by message limits only the identical messages. The formatted logs with varying arguments,
+// e.g. Warningf("connection to %s refused", addr), have distinct messages and aren't limited together.
+// To limit them effectively, key them by their call sites by SetKeyByCaller, or by a field by SetKeyField.
+//
+// The logs over the limit are suppressed. When the interval ends, a summary log like
+// "suppressed 1234 similar messages" is passed for each key which has suppressed logs. The summary log has
+// the severity and the caller of the first suppressed log, and the fields "rate_limit_key" and "suppressed".
+type RateLimitOutput struct {
+	mu          sync.Mutex
+	output      Output
+	limit       int
+	interval    time.Duration
+	keyField    string
+	keyByCaller bool
+	counters    map[string]*rateLimitCounter
+	stopCh      chan struct{}
+	stopped     bool
+	wg          sync.WaitGroup
+}
+
+// rateLimitCounter counts the logs of a key in the current interval.
+type rateLimitCounter struct {
+	count      int
+	suppressed int
+	first      *Log
+}
+
+// NewRateLimitOutput creates a new RateLimitOutput by the given output, limit and interval.
+// If limit is less than 1, it is set to 1. If interval is less or equal than 0, it is set to 1 second.
+// Unused RateLimitOutput must be closed for freeing resources.
+func NewRateLimitOutput(output Output, limit int, interval time.Duration) *RateLimitOutput {
+	if limit < 1 {
+		limit = 1
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	o := &RateLimitOutput{
+		output:   output,
+		limit:    limit,
+		interval: interval,
+		counters: make(map[string]*rateLimitCounter),
+		stopCh:   make(chan struct{}),
+	}
+	o.wg.Add(1)
+	go o.worker()
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *RateLimitOutput) Log(log *Log) {
+	o.mu.Lock()
+	key := o.key(log)
+	c, ok := o.counters[key]
+	if !ok {
+		c = &rateLimitCounter{}
+		o.counters[key] = c
+	}
+	c.count++
+	if c.count > o.limit {
+		if c.suppressed == 0 {
+			c.first = log
+		}
+		c.suppressed++
+		o.mu.Unlock()
+		return
+	}
+	o.mu.Unlock()
+	o.output.Log(log)
+}
+
+// Flush is the implementation of Flusher. It flushes the output.
+func (o *RateLimitOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+// Close stops the interval timer, passes the summary logs of the current interval,
+// and then closes the output if it implements io.Closer.
+func (o *RateLimitOutput) Close() error {
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		return nil
+	}
+	o.stopped = true
+	close(o.stopCh)
+	o.mu.Unlock()
+	o.wg.Wait()
+	o.flush()
+	return closeOutput(o.output)
+}
+
+// SetKeyField sets the field key whose value is used as the key of the logs instead of the message.
+// The logs without the field are keyed by their callers if SetKeyByCaller is enabled, otherwise by their messages.
+// It returns the underlying RateLimitOutput.
+func (o *RateLimitOutput) SetKeyField(key string) *RateLimitOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.keyField = key
+	return o
+}
+
+// SetKeyByCaller sets whether the logs are keyed by their callers, i.e. the file and line which has called
+// the Logger, instead of their messages. The logs without the caller are keyed by their messages.
+// It returns the underlying RateLimitOutput.
+func (o *RateLimitOutput) SetKeyByCaller(keyByCaller bool) *RateLimitOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.keyByCaller = keyByCaller
+	return o
+}
+
+func (o *RateLimitOutput) worker() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stopCh:
+			return
+		case <-ticker.C:
+			o.flush()
+		}
+	}
+}
+
+// flush resets the counters, and passes the summary logs of the suppressed logs.
+func (o *RateLimitOutput) flush() {
+	o.mu.Lock()
+	counters := o.counters
+	o.counters = make(map[string]*rateLimitCounter, len(counters))
+	o.mu.Unlock()
+	now := time.Now()
+	for key, c := range counters {
+		if c.suppressed == 0 {
+			continue
+		}
+		o.output.Log(&Log{
+			Message:     []byte(fmt.Sprintf("suppressed %d similar messages", c.suppressed)),
+			Severity:    c.first.Severity,
+			Verbosity:   c.first.Verbosity,
+			Time:        now,
+			Fields:      Fields{{Key: "rate_limit_key", Value: key}, {Key: "suppressed", Value: c.suppressed}},
+			StackCaller: c.first.StackCaller,
+		})
+	}
+}
+
+// key returns the key of the given log. It must be called with o.mu held.
+func (o *RateLimitOutput) key(log *Log) string {
+	if o.keyField != "" {
+		for i := len(log.Fields) - 1; i >= 0; i-- {
+			if field := log.Fields[i]; field.Key == o.keyField {
+				return fmt.Sprintf("%v", field.Value)
+			}
+		}
+	}
+	if o.keyByCaller && log.StackCaller.File != "" {
+		return fmt.Sprintf("%s:%d", log.StackCaller.File, log.StackCaller.Line)
+	}
+	return string(log.Message)
+}
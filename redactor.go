@@ -0,0 +1,167 @@
+package logng
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// RedactCreditCard matches the credit card numbers which have 13 to 19 digits, optionally separated by
+	// spaces or dashes.
+	RedactCreditCard = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+	// RedactEmail matches the email addresses.
+	RedactEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// Redactor is an implementation of Processor by masking the sensitive field values and message substrings,
+// before the logs reach any output.
+//
+// The values of the fields whose keys match any of the key patterns are masked entirely. The key patterns are
+// matched case-insensitively by path.Match, e.g. "password", "*_token" or "authorization".
+// The substrings matching any of the value regexps are masked in the message, the error message and
+// the string values of the fields.
+type Redactor struct {
+	mu           sync.RWMutex
+	keyPatterns  []string
+	valueRegexps []*regexp.Regexp
+	mask         string
+}
+
+// NewRedactor creates a new Redactor without patterns. By default, the mask is "***".
+func NewRedactor() *Redactor {
+	return &Redactor{
+		mask: "***",
+	}
+}
+
+// AddKeyPatterns adds the given key patterns. It ignores the invalid patterns.
+// It returns the underlying Redactor.
+func (r *Redactor) AddKeyPatterns(patterns ...string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			continue
+		}
+		r.keyPatterns = append(r.keyPatterns, pattern)
+	}
+	return r
+}
+
+// AddValueRegexps adds the given value regexps, e.g. RedactCreditCard and RedactEmail.
+// It returns the underlying Redactor.
+func (r *Redactor) AddValueRegexps(regexps ...*regexp.Regexp) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.valueRegexps = append(r.valueRegexps, regexps...)
+	return r
+}
+
+// SetMask sets the string which replaces the sensitive values.
+// It returns the underlying Redactor.
+func (r *Redactor) SetMask(mask string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mask = mask
+	return r
+}
+
+// Process is the implementation of Processor.
+// It returns the given log if nothing is masked, otherwise a masked clone of the log.
+func (r *Redactor) Process(log *Log) *Log {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := log
+	clone := func() {
+		if result == log {
+			result = log.Clone()
+		}
+	}
+
+	if msg, ok := r.redactString(string(log.Message)); ok {
+		clone()
+		result.Message = []byte(msg)
+	}
+	if log.Error != nil {
+		if msg, ok := r.redactString(log.Error.Error()); ok {
+			clone()
+			result.Error = &redactedError{msg: msg, err: log.Error}
+		}
+	}
+	if fields, ok := r.redactFields(log.Fields); ok {
+		clone()
+		result.Fields = fields
+	}
+	return result
+}
+
+// redactFields masks the given fields including the fields of the groups.
+// It returns a masked copy of the fields and true if anything is masked, otherwise the given fields and false.
+func (r *Redactor) redactFields(fields Fields) (Fields, bool) {
+	result, changed := fields, false
+	set := func(i int, value interface{}) {
+		if !changed {
+			result, changed = fields.Clone(), true
+		}
+		result[i].Value = value
+	}
+	for i, field := range fields {
+		if r.matchKey(field.Key) {
+			set(i, r.mask)
+			continue
+		}
+		switch v := field.Value.(type) {
+		case string:
+			if s, ok := r.redactString(v); ok {
+				set(i, s)
+			}
+		case GroupValue:
+			if g, ok := r.redactFields(Fields(v)); ok {
+				set(i, GroupValue(g))
+			}
+		}
+	}
+	return result, changed
+}
+
+// matchKey reports whether the given field key matches any of the key patterns.
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keyPatterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString masks the substrings of s matching any of the value regexps, and reports whether s has been changed.
+func (r *Redactor) redactString(s string) (string, bool) {
+	changed := false
+	for _, re := range r.valueRegexps {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, r.mask)
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// redactedError is an error whose message is masked by Redactor.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
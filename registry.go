@@ -0,0 +1,93 @@
+package logng
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	outputRegistryMu sync.RWMutex
+	outputRegistry   []Output
+)
+
+// RegisterOutput registers the given output into the output registry.
+// Registered outputs can be enumerated by Outputs for centralized management such as flushing, closing and health reporting.
+// The package-level Sync and Close flush and close the registered outputs as well as the default Logger's output.
+// If output is nil or already registered, RegisterOutput does nothing.
+func RegisterOutput(output Output) {
+	if output == nil {
+		return
+	}
+	outputRegistryMu.Lock()
+	defer outputRegistryMu.Unlock()
+	for _, o := range outputRegistry {
+		if outputEqual(o, output) {
+			return
+		}
+	}
+	outputRegistry = append(outputRegistry, output)
+}
+
+// UnregisterOutput removes the given output from the output registry.
+func UnregisterOutput(output Output) {
+	if output == nil {
+		return
+	}
+	outputRegistryMu.Lock()
+	defer outputRegistryMu.Unlock()
+	for i, o := range outputRegistry {
+		if outputEqual(o, output) {
+			outputRegistry = append(outputRegistry[:i], outputRegistry[i+1:]...)
+			return
+		}
+	}
+}
+
+// Outputs returns all registered outputs in registration order.
+func Outputs() []Output {
+	outputRegistryMu.RLock()
+	defer outputRegistryMu.RUnlock()
+	result := make([]Output, len(outputRegistry))
+	copy(result, outputRegistry)
+	return result
+}
+
+// registeredOutputs returns the registered outputs and the given output if it isn't registered.
+func registeredOutputs(output Output) []Output {
+	outputs := Outputs()
+	if output == nil {
+		return outputs
+	}
+	for _, o := range outputs {
+		if outputEqual(o, output) {
+			return outputs
+		}
+	}
+	return append([]Output{output}, outputs...)
+}
+
+// outputEqual reports whether a and b are the same Output by identity.
+// Pointers, maps and channels are compared by their pointers, and slices such as MultiOutput by their backing
+// arrays and lengths. The other comparable values are compared by value, and the others are never equal.
+func outputEqual(a, b Output) (equal bool) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	}
+	if !va.Type().Comparable() {
+		return false
+	}
+	defer func() {
+		// comparable structs and arrays panic if they hold non-comparable values in interfaces.
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
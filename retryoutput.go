@@ -0,0 +1,347 @@
+package logng
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// RetryPolicy describes how RetryOutput retries the failed logs.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a failed log. If it is negative, the log is retried until
+	// it succeeds or RetryOutput is closed.
+	MaxRetries int
+
+	// MinBackoff is the backoff before the first retry. The backoff doubles on each retry, up to MaxBackoff.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum backoff between the retries.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of the backoff to randomize between 0 and 1, e.g. 0.2 for ±20%.
+	Jitter float64
+
+	// BufferLen is the length of the buffer to re-queue the failed logs. If it is greater than 0, Log doesn't wait
+	// for the retries. The failed logs are re-queued to the buffer, and they are retried in the background in order.
+	// If the buffer is full, the oldest log is dropped.
+	BufferLen int
+}
+
+// DefaultRetryPolicy returns the default RetryPolicy. It retries a failed log 3 times with a backoff from
+// 100 milliseconds up to 5 seconds with 20% jitter, and it doesn't re-queue the failed logs.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		Jitter:     0.2,
+	}
+}
+
+// backoff returns the backoff before the retry with the given index which starts from 0.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 0; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		backoff += time.Duration(float64(backoff) * p.Jitter * (2*rand.Float64() - 1))
+	}
+	if backoff < 0 {
+		backoff = 0
+	}
+	return backoff
+}
+
+// retryEntry is a buffered log of RetryOutput with the number of its tries.
+type retryEntry struct {
+	log   *Log
+	tries int
+}
+
+// RetryOutput is an implementation of Output by passing the logs to the underlying output, and retrying the failed
+// logs with exponential backoff and jitter by the RetryPolicy. It is designed for flaky network destinations.
+// The output is considered as failed when the health function returns false after the log. By default,
+// the health function uses HealthChecker if the output implements it, otherwise the output never fails.
+//
+// Without the buffer, Log waits for the retries. It doesn't hold the lock of RetryOutput while logging and backing off,
+// so the concurrent logs are tried independently. RetryOutput should be wrapped by QueuedOutput in this case.
+// With the buffer, the logs are tried in order.
+// The logs which are still failed after the retries are dropped with ErrRetriesExhausted.
+type RetryOutput struct {
+	output     Output
+	policy     RetryPolicy
+	mu         sync.Mutex
+	buffer     []*retryEntry
+	trying     bool
+	closed     bool
+	notifyCh   chan struct{}
+	closeCh    chan struct{}
+	wg         sync.WaitGroup
+	healthFunc func(Output) bool
+	onDrop     *func(log *Log, err error)
+}
+
+// NewRetryOutput creates a new RetryOutput by the given output and RetryPolicy.
+// If the BufferLen of the policy is greater than 0, it starts a goroutine to retry the buffered logs.
+func NewRetryOutput(output Output, policy RetryPolicy) *RetryOutput {
+	o := &RetryOutput{
+		output:     output,
+		policy:     policy,
+		notifyCh:   make(chan struct{}, 1),
+		closeCh:    make(chan struct{}),
+		healthFunc: defaultHealthFunc,
+	}
+	if policy.BufferLen > 0 {
+		o.buffer = make([]*retryEntry, 0, policy.BufferLen+1)
+		o.wg.Add(1)
+		go o.retrier()
+	}
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *RetryOutput) Log(log *Log) {
+	if o.policy.BufferLen > 0 {
+		o.enqueue(log)
+		return
+	}
+	for retry := 0; ; retry++ {
+		o.mu.Lock()
+		closed, healthFunc := o.closed, o.healthFunc
+		o.mu.Unlock()
+		if closed {
+			o.drop(log, fmt.Errorf("unable to retry log: %w", ErrClosed))
+			return
+		}
+		if o.try(log, healthFunc) {
+			return
+		}
+		if o.policy.MaxRetries >= 0 && retry >= o.policy.MaxRetries {
+			o.drop(log, fmt.Errorf("unable to log after %d retries: %w", retry, ErrRetriesExhausted))
+			return
+		}
+		select {
+		case <-o.closeCh:
+		case <-time.After(o.policy.backoff(retry)):
+		}
+	}
+}
+
+// Close is the implementation of io.Closer. It stops retrying, tries the buffered logs once more, drops the logs
+// which are still failed, and closes the underlying output.
+func (o *RetryOutput) Close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	close(o.closeCh)
+	o.mu.Unlock()
+	o.wg.Wait()
+
+	o.mu.Lock()
+	buffer, healthFunc := o.buffer, o.healthFunc
+	o.buffer = nil
+	o.mu.Unlock()
+	for i, e := range buffer {
+		if !o.try(e.log, healthFunc) {
+			o.dropEntries(buffer[i:], fmt.Errorf("unable to retry log: %w", ErrClosed))
+			break
+		}
+	}
+	return closeOutput(o.output)
+}
+
+// Flush is the implementation of Flusher. It flushes the underlying output.
+func (o *RetryOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+// Buffered returns the number of the logs in the buffer waiting for retry.
+func (o *RetryOutput) Buffered() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.buffer)
+}
+
+// SetHealthFunc sets the function which reports whether the output has logged the last log successfully.
+// If f is nil, the default health function is used.
+// It returns the underlying RetryOutput.
+func (o *RetryOutput) SetHealthFunc(f func(output Output) bool) *RetryOutput {
+	if f == nil {
+		f = defaultHealthFunc
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.healthFunc = f
+	return o
+}
+
+// SetOnDrop sets a function to call when a log is dropped. The error wraps ErrRetriesExhausted, ErrQueueFull or
+// ErrClosed.
+// It returns the underlying RetryOutput.
+func (o *RetryOutput) SetOnDrop(f func(log *Log, err error)) *RetryOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onDrop)), unsafe.Pointer(&f))
+	return o
+}
+
+// try passes the given log to the output, and reports whether it succeeded by the given health function.
+func (o *RetryOutput) try(log *Log, healthFunc func(Output) bool) bool {
+	o.output.Log(log)
+	return healthFunc(o.output)
+}
+
+// enqueue tries the given log if the buffer is empty and no log is being tried, otherwise or if it fails,
+// re-queues the log to the buffer. The log is tried without holding the lock.
+func (o *RetryOutput) enqueue(log *Log) {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		o.drop(log, fmt.Errorf("unable to retry log: %w", ErrClosed))
+		return
+	}
+	if len(o.buffer) > 0 || o.trying {
+		drops := o.requeue(&retryEntry{log: log}, false)
+		o.mu.Unlock()
+		o.dropEntries(drops, fmt.Errorf("unable to re-queue log: %w", ErrQueueFull))
+		return
+	}
+	o.trying = true
+	healthFunc := o.healthFunc
+	o.mu.Unlock()
+
+	ok := o.try(log, healthFunc)
+
+	o.mu.Lock()
+	o.trying = false
+	o.notify()
+	switch {
+	case ok:
+		o.mu.Unlock()
+	case o.closed:
+		o.mu.Unlock()
+		o.drop(log, fmt.Errorf("unable to retry log: %w", ErrClosed))
+	case o.policy.MaxRetries == 0:
+		o.mu.Unlock()
+		o.drop(log, fmt.Errorf("unable to log after %d retries: %w", 0, ErrRetriesExhausted))
+	default:
+		// the log is older than the buffered logs, because they have been buffered while trying it.
+		drops := o.requeue(&retryEntry{log: log, tries: 1}, true)
+		o.mu.Unlock()
+		o.dropEntries(drops, fmt.Errorf("unable to re-queue log: %w", ErrQueueFull))
+	}
+}
+
+// requeue adds the given entry to the front or the back of the buffer, and notifies the retrier.
+// If the buffer is full, it removes the oldest entries and returns them to drop after unlocking.
+// It must be called while holding the lock.
+func (o *RetryOutput) requeue(e *retryEntry, front bool) (drops []*retryEntry) {
+	if front {
+		o.buffer = append(o.buffer, nil)
+		copy(o.buffer[1:], o.buffer)
+		o.buffer[0] = e
+	} else {
+		o.buffer = append(o.buffer, e)
+	}
+	if n := len(o.buffer) - o.policy.BufferLen; n > 0 {
+		drops = append(drops, o.buffer[:n]...)
+		copy(o.buffer, o.buffer[n:])
+		for i := len(o.buffer) - n; i < len(o.buffer); i++ {
+			o.buffer[i] = nil
+		}
+		o.buffer = o.buffer[:len(o.buffer)-n]
+	}
+	o.notify()
+	return drops
+}
+
+// notify notifies the retrier without blocking.
+func (o *RetryOutput) notify() {
+	select {
+	case o.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// retrier retries the buffered logs in order until the underlying RetryOutput is closed.
+// Each log is tried up to MaxRetries+1 times including the first try, and it is tried without holding the lock.
+func (o *RetryOutput) retrier() {
+	defer o.wg.Done()
+	for {
+		o.mu.Lock()
+		if len(o.buffer) == 0 || o.trying {
+			o.mu.Unlock()
+			select {
+			case <-o.closeCh:
+				return
+			case <-o.notifyCh:
+			}
+			continue
+		}
+		tries := o.buffer[0].tries
+		o.mu.Unlock()
+
+		if tries > 0 {
+			select {
+			case <-o.closeCh:
+				return
+			case <-time.After(o.policy.backoff(tries - 1)):
+			}
+		}
+
+		o.mu.Lock()
+		if len(o.buffer) == 0 || o.trying {
+			o.mu.Unlock()
+			continue
+		}
+		e := o.buffer[0]
+		copy(o.buffer, o.buffer[1:])
+		o.buffer[len(o.buffer)-1] = nil
+		o.buffer = o.buffer[:len(o.buffer)-1]
+		o.trying = true
+		healthFunc := o.healthFunc
+		o.mu.Unlock()
+
+		ok := o.try(e.log, healthFunc)
+		e.tries++
+
+		o.mu.Lock()
+		o.trying = false
+		switch {
+		case ok:
+			o.mu.Unlock()
+		case o.policy.MaxRetries >= 0 && e.tries > o.policy.MaxRetries:
+			o.mu.Unlock()
+			o.drop(e.log, fmt.Errorf("unable to log after %d retries: %w", e.tries-1, ErrRetriesExhausted))
+		default:
+			drops := o.requeue(e, true)
+			o.mu.Unlock()
+			o.dropEntries(drops, fmt.Errorf("unable to re-queue log: %w", ErrQueueFull))
+		}
+	}
+}
+
+// dropEntries drops the logs of the given entries by drop with the given error.
+func (o *RetryOutput) dropEntries(entries []*retryEntry, err error) {
+	for _, e := range entries {
+		o.drop(e.log, err)
+	}
+}
+
+// drop calls the drop function, and reports the error to the error handler.
+func (o *RetryOutput) drop(log *Log, err error) {
+	handleError(err)
+	onDrop := o.onDrop
+	if onDrop == nil || *onDrop == nil {
+		return
+	}
+	(*onDrop)(log, err)
+}
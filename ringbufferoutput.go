@@ -0,0 +1,92 @@
+package logng
+
+import (
+	"sync"
+)
+
+// RingBufferOutput is an implementation of Output by retaining the last logs in memory.
+// It is useful to expose recent logs on a debug endpoint, or to dump them to another Output when something goes wrong.
+type RingBufferOutput struct {
+	mu   sync.RWMutex
+	logs []*Log
+	next int
+	full bool
+}
+
+// NewRingBufferOutput creates a new RingBufferOutput which retains the last size logs.
+// If size is less than 1, it is set to 1.
+func NewRingBufferOutput(size int) *RingBufferOutput {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBufferOutput{
+		logs: make([]*Log, size),
+	}
+}
+
+// Log is the implementation of Output.
+func (o *RingBufferOutput) Log(log *Log) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.logs[o.next] = log
+	o.next++
+	if o.next >= len(o.logs) {
+		o.next = 0
+		o.full = true
+	}
+}
+
+// Snapshot returns the copies of the retained logs from the oldest to the newest.
+func (o *RingBufferOutput) Snapshot() []*Log {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	result := make([]*Log, 0, o.len())
+	o.each(func(log *Log) {
+		result = append(result, log.Clone())
+	})
+	return result
+}
+
+// Dump logs the retained logs from the oldest to the newest to the given output.
+// It doesn't remove the logs from the underlying RingBufferOutput.
+func (o *RingBufferOutput) Dump(output Output) {
+	for _, log := range o.Snapshot() {
+		output.Log(log)
+	}
+}
+
+// Len returns the number of the retained logs.
+func (o *RingBufferOutput) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.len()
+}
+
+// Reset removes all the retained logs.
+func (o *RingBufferOutput) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.logs {
+		o.logs[i] = nil
+	}
+	o.next = 0
+	o.full = false
+}
+
+func (o *RingBufferOutput) len() int {
+	if o.full {
+		return len(o.logs)
+	}
+	return o.next
+}
+
+func (o *RingBufferOutput) each(f func(log *Log)) {
+	if o.full {
+		for _, log := range o.logs[o.next:] {
+			f(log)
+		}
+	}
+	for _, log := range o.logs[:o.next] {
+		f(log)
+	}
+}
@@ -0,0 +1,95 @@
+package logng
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RouterOutput is an implementation of Output by routing the logs to the different outputs
+// by the value of the field which has the given key, e.g. "tenant" or "component".
+// So, multi-tenant services can separate the log destinations per tenant.
+//
+// The field values are compared by their string representations in the default format.
+// If a log has more than one field with the key, the last one is used. The logs which have no field with the key,
+// or have a value without a route, are passed to the default output.
+type RouterOutput struct {
+	mu            sync.RWMutex
+	key           string
+	routes        map[string]Output
+	defaultOutput Output
+}
+
+// NewRouterOutput creates a new RouterOutput by the given field key and default output.
+// If defaultOutput is nil, the logs without a route are discarded.
+func NewRouterOutput(key string, defaultOutput Output) *RouterOutput {
+	return &RouterOutput{
+		key:           key,
+		routes:        make(map[string]Output),
+		defaultOutput: defaultOutput,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *RouterOutput) Log(log *Log) {
+	o.mu.RLock()
+	output := o.defaultOutput
+	for i := len(log.Fields) - 1; i >= 0; i-- {
+		if field := log.Fields[i]; field.Key == o.key {
+			if route, ok := o.routes[fmt.Sprintf("%v", field.Value)]; ok {
+				output = route
+			}
+			break
+		}
+	}
+	o.mu.RUnlock()
+	if output != nil {
+		output.Log(log)
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the route outputs and the default output.
+func (o *RouterOutput) Flush() error {
+	return flushOutputs(o.allOutputs())
+}
+
+// Close is the implementation of io.Closer. It closes the route outputs and the default output.
+func (o *RouterOutput) Close() error {
+	return closeOutputs(o.allOutputs())
+}
+
+// allOutputs returns the route outputs and the default output.
+func (o *RouterOutput) allOutputs() []Output {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	outputs := make([]Output, 0, len(o.routes)+1)
+	for _, output := range o.routes {
+		outputs = append(outputs, output)
+	}
+	if o.defaultOutput != nil {
+		outputs = append(outputs, o.defaultOutput)
+	}
+	return outputs
+}
+
+// SetRoute sets the output of the logs which have the given field value.
+// If output is nil, it removes the route.
+// It returns the underlying RouterOutput.
+func (o *RouterOutput) SetRoute(value string, output Output) *RouterOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if output == nil {
+		delete(o.routes, value)
+		return o
+	}
+	o.routes[value] = output
+	return o
+}
+
+// SetDefaultOutput sets the output of the logs without a route.
+// It returns the underlying RouterOutput.
+func (o *RouterOutput) SetDefaultOutput(output Output) *RouterOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.defaultOutput = output
+	return o
+}
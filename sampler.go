@@ -0,0 +1,172 @@
+package logng
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Sampler decides whether the logs are kept by the sampling rules per severity.
+// So, high-volume logs can be reduced without touching the call sites. It is safe for concurrent use.
+// The logs of the severities without a rule are always kept.
+type Sampler struct {
+	mu    sync.Mutex
+	rules map[Severity]*samplingRule
+}
+
+// samplingRule is the sampling rule of a severity.
+type samplingRule struct {
+	every       uint64
+	probability float64
+	count       uint64
+	burst       *burstRule
+}
+
+// burstRuleSize is the number of the counters of burstRule. The messages are mapped to the counters by their hashes.
+const burstRuleSize = 4096
+
+// burstRule is the burst sampling rule of a severity.
+type burstRule struct {
+	tick       time.Duration
+	first      int
+	thereafter int
+	counters   [burstRuleSize]burstCounter
+}
+
+// burstCounter counts the identical messages in the current tick.
+type burstCounter struct {
+	resetAt time.Time
+	count   int
+}
+
+// NewSampler creates a new Sampler without rules.
+func NewSampler() *Sampler {
+	return &Sampler{
+		rules: make(map[Severity]*samplingRule),
+	}
+}
+
+// SetEvery sets the rule to keep 1 in n logs of the given severity, starting with the first log.
+// If n is less or equal than 1, all logs of the severity are kept by this rule.
+// It returns the underlying Sampler.
+func (s *Sampler) SetEvery(severity Severity, n int) *Sampler {
+	if n < 1 {
+		n = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rule(severity).every = uint64(n)
+	return s
+}
+
+// SetProbability sets the rule to keep the logs of the given severity by the given probability between 0 and 1.
+// If it is used with SetEvery for the same severity, a log is kept when both of the rules keep.
+// It returns the underlying Sampler.
+func (s *Sampler) SetProbability(severity Severity, probability float64) *Sampler {
+	if probability < 0 {
+		probability = 0
+	}
+	if probability > 1 {
+		probability = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rule(severity).probability = probability
+	return s
+}
+
+// SetBurst sets the rule to keep the first n identical messages of the given severity in each tick,
+// and thereafter every mth one in the same tick. If thereafter is less than 1, the identical messages
+// after the first n are dropped until the next tick. The messages are counted by their hashes,
+// so rarely different messages may share a counter.
+// It returns the underlying Sampler.
+func (s *Sampler) SetBurst(severity Severity, tick time.Duration, first, thereafter int) *Sampler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rule(severity).burst = &burstRule{
+		tick:       tick,
+		first:      first,
+		thereafter: thereafter,
+	}
+	return s
+}
+
+// Sample reports whether a log of the given severity and message is kept.
+func (s *Sampler) Sample(severity Severity, message string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.rules[severity]
+	if !ok {
+		return true
+	}
+	if r.burst != nil && !r.burst.sample(message, time.Now()) {
+		return false
+	}
+	r.count++
+	if (r.count-1)%r.every != 0 {
+		return false
+	}
+	return r.probability >= 1 || rand.Float64() < r.probability
+}
+
+// sample reports whether the given message is kept by the burst rule.
+func (b *burstRule) sample(message string, now time.Time) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(message))
+	c := &b.counters[h.Sum32()%burstRuleSize]
+	if !now.Before(c.resetAt) {
+		c.resetAt = now.Add(b.tick)
+		c.count = 0
+	}
+	c.count++
+	if c.count <= b.first {
+		return true
+	}
+	return b.thereafter > 0 && (c.count-b.first)%b.thereafter == 0
+}
+
+// rule returns the rule of the given severity by creating it if it doesn't exist.
+func (s *Sampler) rule(severity Severity) *samplingRule {
+	r, ok := s.rules[severity]
+	if !ok {
+		r = &samplingRule{every: 1, probability: 1}
+		s.rules[severity] = r
+	}
+	return r
+}
+
+// SamplerOutput is an implementation of Output by passing the logs which are kept by the Sampler to the given output.
+type SamplerOutput struct {
+	output  Output
+	sampler *Sampler
+}
+
+// NewSamplerOutput creates a new SamplerOutput by the given output and Sampler.
+func NewSamplerOutput(output Output, sampler *Sampler) *SamplerOutput {
+	return &SamplerOutput{
+		output:  output,
+		sampler: sampler,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *SamplerOutput) Log(log *Log) {
+	if !o.sampler.Sample(log.Severity, string(log.Message)) {
+		return
+	}
+	o.output.Log(log)
+}
+
+// Flush is the implementation of Flusher. It flushes the output.
+func (o *SamplerOutput) Flush() error {
+	return flushOutput(o.output)
+}
+
+// Close is the implementation of io.Closer. It closes the output.
+func (o *SamplerOutput) Close() error {
+	return closeOutput(o.output)
+}
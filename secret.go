@@ -0,0 +1,54 @@
+package logng
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// secretMask is the rendering of SecretValue.
+const secretMask = "***"
+
+// SecretValue is a field value wrapper whose renderings are always "***", so credentials never leak through
+// a forgotten With call. The raw value is accessible by the Reveal method for the explicitly trusted outputs.
+type SecretValue struct {
+	value interface{}
+}
+
+// Secret wraps the given value as SecretValue.
+func Secret(v interface{}) SecretValue {
+	return SecretValue{value: v}
+}
+
+// Reveal returns the raw value of the underlying SecretValue.
+func (s SecretValue) Reveal() interface{} {
+	return s.value
+}
+
+// String is the implementation of fmt.Stringer.
+func (s SecretValue) String() string {
+	return secretMask
+}
+
+// GoString is the implementation of fmt.GoStringer.
+func (s SecretValue) GoString() string {
+	return secretMask
+}
+
+// Format is the implementation of fmt.Formatter. It renders "***" for all verbs, and quoted for 'q'.
+func (s SecretValue) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		_, _ = f.Write([]byte(strconv.Quote(secretMask)))
+		return
+	}
+	_, _ = f.Write([]byte(secretMask))
+}
+
+// MarshalText is the implementation of encoding.TextMarshaler.
+func (s SecretValue) MarshalText() ([]byte, error) {
+	return []byte(secretMask), nil
+}
+
+// MarshalJSON is the implementation of json.Marshaler.
+func (s SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(secretMask)), nil
+}
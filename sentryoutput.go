@@ -0,0 +1,447 @@
+package logng
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentryOutputFlushTimeout is the timeout of SentryOutput.Flush.
+const sentryOutputFlushTimeout = 5 * time.Second
+
+// SentryOutput is an implementation of Output by sending logs at or above the minimum severity to Sentry as events.
+// Log.Error is sent as the exception with the frames of Log.StackTrace. Fields are sent as extra data,
+// except the fields whose keys are set by SetTagKeys, which are sent as tags.
+//
+// Events are sent by a background goroutine over the envelope endpoint of the DSN.
+// Events are dropped with an error if the queue is full.
+type SentryOutput struct {
+	mu          sync.RWMutex
+	endpoint    string
+	dsn         string
+	publicKey   string
+	client      *http.Client
+	severity    Severity
+	sampleRate  float64
+	tagKeys     map[string]struct{}
+	environment string
+	release     string
+	serverName  string
+	queue       chan []byte
+	pendingMu   sync.Mutex
+	pending     int
+	idleCh      chan struct{}
+	stopped     bool
+	wg          sync.WaitGroup
+	health
+}
+
+// NewSentryOutput creates a new SentryOutput by the given DSN, e.g. https://public@o0.ingest.sentry.io/123.
+// By default, the minimum severity is SeverityError, the sample rate is 1, the queue size is 100,
+// and the HTTP client has the timeout of 10 seconds.
+func NewSentryOutput(dsn string) (*SentryOutput, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse dsn: %w", err)
+	}
+	projectID := strings.TrimPrefix(u.Path[strings.LastIndex(u.Path, "/"):], "/")
+	if u.User == nil || u.User.Username() == "" || projectID == "" {
+		return nil, fmt.Errorf("invalid dsn %q", dsn)
+	}
+	serverName, _ := os.Hostname()
+	o := &SentryOutput{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/",
+			u.Scheme, u.Host, strings.TrimSuffix(u.Path, "/"+projectID), projectID),
+		dsn:        dsn,
+		publicKey:  u.User.Username(),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		severity:   SeverityError,
+		sampleRate: 1,
+		serverName: serverName,
+		queue:      make(chan []byte, 100),
+	}
+	o.wg.Add(1)
+	go o.worker()
+	return o, nil
+}
+
+// Log is the implementation of Output.
+func (o *SentryOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		if err != nil {
+			o.handleError(err)
+		}
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if log.Severity.Rank() > o.severity.Rank() {
+		return
+	}
+	if o.sampleRate < 1 && mathrand.Float64() >= o.sampleRate {
+		return
+	}
+	if o.stopped {
+		err = ErrClosed
+		return
+	}
+
+	var envelope []byte
+	envelope, err = o.envelope(log)
+	if err != nil {
+		return
+	}
+	o.addPending(1)
+	select {
+	case o.queue <- envelope:
+	default:
+		o.addPending(-1)
+		err = fmt.Errorf("unable to send event: %w", ErrQueueFull)
+	}
+}
+
+// Flush is the implementation of Flusher. It waits until the queued events have been sent,
+// up to the default flush timeout of 5 seconds. It returns ErrOutputTimeout if the timeout has been exceeded.
+func (o *SentryOutput) Flush() error {
+	if !o.FlushTimeout(sentryOutputFlushTimeout) {
+		return fmt.Errorf("unable to flush events: %w", ErrOutputTimeout)
+	}
+	return nil
+}
+
+// FlushTimeout waits until the queued events have been sent, up to the given timeout.
+// It returns false if the timeout has been exceeded.
+func (o *SentryOutput) FlushTimeout(timeout time.Duration) bool {
+	o.pendingMu.Lock()
+	if o.pending == 0 {
+		o.pendingMu.Unlock()
+		return true
+	}
+	idleCh := o.idleCh
+	o.pendingMu.Unlock()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-idleCh:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Close sends the queued events and stops the background goroutine.
+// Logs after closing are dropped with ErrClosed.
+func (o *SentryOutput) Close() error {
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		return nil
+	}
+	o.stopped = true
+	close(o.queue)
+	o.mu.Unlock()
+	o.wg.Wait()
+	return nil
+}
+
+// SetSeverity sets the minimum severity of logs to send.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetSeverity(severity Severity) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.severity = severity
+	return o
+}
+
+// SetSampleRate sets the rate of events to send, between 0 and 1.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetSampleRate(sampleRate float64) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sampleRate = sampleRate
+	return o
+}
+
+// SetTagKeys sets the keys of fields which are sent as tags instead of extra data.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetTagKeys(keys ...string) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tagKeys = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		o.tagKeys[key] = struct{}{}
+	}
+	return o
+}
+
+// SetEnvironment sets the environment of events, e.g. production.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetEnvironment(environment string) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.environment = environment
+	return o
+}
+
+// SetRelease sets the release of events, e.g. the version of the application.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetRelease(release string) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.release = release
+	return o
+}
+
+// SetServerName sets the server name of events. By default, the hostname reported by the kernel.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetServerName(serverName string) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.serverName = serverName
+	return o
+}
+
+// SetClient sets the HTTP client. By default, a client with the timeout of 10 seconds.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetClient(client *http.Client) *SentryOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client = client
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying SentryOutput.
+func (o *SentryOutput) SetOnError(f func(error)) *SentryOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *SentryOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last event has been sent successfully.
+// It returns true if no event has been sent yet.
+func (o *SentryOutput) Healthy() bool {
+	return o.healthy()
+}
+
+func (o *SentryOutput) worker() {
+	defer o.wg.Done()
+	for envelope := range o.queue {
+		if err := o.send(envelope); err != nil {
+			o.handleError(err)
+		} else {
+			o.setHealthy()
+		}
+		o.addPending(-1)
+	}
+}
+
+// addPending adds delta to the count of the events in flight. idleCh is created when the count leaves zero,
+// and closed when it gets back to zero, to wake up the waiting FlushTimeout calls.
+func (o *SentryOutput) addPending(delta int) {
+	o.pendingMu.Lock()
+	defer o.pendingMu.Unlock()
+	if o.pending == 0 {
+		o.idleCh = make(chan struct{})
+	}
+	o.pending += delta
+	if o.pending == 0 {
+		close(o.idleCh)
+	}
+}
+
+func (o *SentryOutput) send(envelope []byte) error {
+	o.mu.RLock()
+	client := o.client
+	o.mu.RUnlock()
+	req, err := http.NewRequest(http.MethodPost, o.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=logng/2, sentry_key=%s", o.publicKey))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unable to send event: unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (o *SentryOutput) envelope(log *Log) ([]byte, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("unable to generate event id: %w", err)
+	}
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id[:]),
+		Timestamp:   log.Time.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevel(log.Severity),
+		Platform:    "go",
+		Logger:      "logng",
+		ServerName:  o.serverName,
+		Environment: o.environment,
+		Release:     o.release,
+		Message:     &sentryMessage{Formatted: string(log.Message)},
+	}
+	for _, field := range log.Fields {
+		if _, ok := o.tagKeys[field.Key]; ok {
+			if event.Tags == nil {
+				event.Tags = make(map[string]string)
+			}
+			event.Tags[field.Key] = fmt.Sprintf("%v", field.Value)
+			continue
+		}
+		if event.Extra == nil {
+			event.Extra = make(map[string]interface{})
+		}
+		value := field.Value
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprintf("%v", value)
+		}
+		event.Extra[field.Key] = value
+	}
+	stacktrace := sentryStacktraceFrom(log.StackTrace)
+	if log.Error != nil {
+		event.Exception = []sentryException{
+			{
+				Type:       fmt.Sprintf("%T", log.Error),
+				Value:      log.Error.Error(),
+				Stacktrace: stacktrace,
+			},
+		}
+	} else if stacktrace != nil {
+		event.Threads = []sentryThread{
+			{
+				Stacktrace: stacktrace,
+				Current:    true,
+			},
+		}
+	}
+
+	eventData, err := json.Marshal(&event)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal event: %w", err)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(eventData)+256))
+	header, _ := json.Marshal(map[string]string{
+		"event_id": event.EventID,
+		"dsn":      o.dsn,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	buf.Write(header)
+	buf.WriteRune('\n')
+	buf.WriteString(fmt.Sprintf(`{"type":"event","length":%d}`, len(eventData)))
+	buf.WriteRune('\n')
+	buf.Write(eventData)
+	buf.WriteRune('\n')
+	return buf.Bytes(), nil
+}
+
+// sentryLevel returns the Sentry level by the given Severity.
+func sentryLevel(severity Severity) string {
+	switch severity {
+	case SeverityEmergency, SeverityAlert, SeverityFatal, SeverityPanic, SeverityCritical:
+		return "fatal"
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityDebug:
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Message     *sentryMessage         `json:"message,omitempty"`
+	Exception   []sentryException      `json:"exception,omitempty"`
+	Threads     []sentryThread         `json:"threads,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryThread struct {
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+	Current    bool              `json:"current"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+}
+
+// sentryStacktraceFrom returns the Sentry stack trace by the given StackTrace.
+// Sentry expects frames from the outermost to the innermost call.
+func sentryStacktraceFrom(trace *StackTrace) *sentryStacktrace {
+	if trace == nil || trace.SizeOfCallers() == 0 {
+		return nil
+	}
+	callers := trace.Callers()
+	frames := make([]sentryFrame, 0, len(callers))
+	for i := len(callers) - 1; i >= 0; i-- {
+		c := callers[i]
+		frame := sentryFrame{
+			Function: c.Function,
+			Filename: trimDirs(c.File),
+			AbsPath:  c.File,
+			Lineno:   c.Line,
+		}
+		if idx := strings.LastIndex(c.Function, "/"); idx >= 0 {
+			if idx2 := strings.Index(c.Function[idx:], "."); idx2 >= 0 {
+				frame.Module, frame.Function = c.Function[:idx+idx2], c.Function[idx+idx2+1:]
+			}
+		} else if idx2 := strings.Index(c.Function, "."); idx2 >= 0 {
+			frame.Module, frame.Function = c.Function[:idx2], c.Function[idx2+1:]
+		}
+		frames = append(frames, frame)
+	}
+	return &sentryStacktrace{Frames: frames}
+}
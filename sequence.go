@@ -0,0 +1,56 @@
+package logng
+
+import (
+	"sync/atomic"
+)
+
+// loggerSequence is the sequence number field of Logger.
+type loggerSequence struct {
+	key     string
+	counter *uint64
+}
+
+// next returns the field with the next sequence number.
+func (s *loggerSequence) next() Field {
+	return Field{Key: s.key, Value: atomic.AddUint64(s.counter, 1)}
+}
+
+// processSequenceCounter is the sequence counter shared by the Loggers stamped by WithProcessSequence.
+var processSequenceCounter = new(uint64)
+
+// WithSequence clones the default Logger with the sequence number field. See Logger.WithSequence.
+func WithSequence(key string) *Logger {
+	return defaultLogger.WithSequence(key)
+}
+
+// WithSequence clones the underlying Logger with a new sequence counter, and stamps each log with the field
+// which has the given key and the atomically incremented sequence number starting from 1. The counter is shared
+// by the Loggers cloned from the returned Logger. The key is "seq" if it is empty.
+// The sequence number is stamped after the hooks, so the logs dropped by filtering don't cause gaps.
+func (l *Logger) WithSequence(key string) *Logger {
+	return l.withSequence(key, new(uint64))
+}
+
+// WithProcessSequence clones the default Logger with the process-wide sequence number field.
+// See Logger.WithProcessSequence.
+func WithProcessSequence(key string) *Logger {
+	return defaultLogger.WithProcessSequence(key)
+}
+
+// WithProcessSequence is similar to WithSequence, but the counter is shared by the all Loggers stamped by
+// WithProcessSequence in the process.
+func (l *Logger) WithProcessSequence(key string) *Logger {
+	return l.withSequence(key, processSequenceCounter)
+}
+
+func (l *Logger) withSequence(key string, counter *uint64) *Logger {
+	if l == nil {
+		return nil
+	}
+	if key == "" {
+		key = "seq"
+	}
+	l2 := l.Clone()
+	l2.sequence = &loggerSequence{key: key, counter: counter}
+	return l2
+}
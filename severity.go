@@ -1,10 +1,15 @@
 package logng
 
 import (
+	"strconv"
 	"strings"
 )
 
 // Severity describes the severity level of Log.
+//
+// The numeric values of the severities don't follow their order from the most severe to the least severe,
+// because SeverityEmergency, SeverityAlert, SeverityCritical, SeverityNotice and SeverityPanic have been appended
+// after SeverityDebug to keep the values of the existing severities. So, severities must be compared by Severity.Rank.
 type Severity int
 
 const (
@@ -25,6 +30,21 @@ const (
 
 	// SeverityDebug is the debug severity level.
 	SeverityDebug
+
+	// SeverityEmergency is the emergency severity level. The system is unusable.
+	SeverityEmergency
+
+	// SeverityAlert is the alert severity level. An action must be taken immediately.
+	SeverityAlert
+
+	// SeverityCritical is the critical severity level.
+	SeverityCritical
+
+	// SeverityNotice is the notice severity level. Normal but significant condition.
+	SeverityNotice
+
+	// SeverityPanic is the panic severity level. The Panic methods panic after logging.
+	SeverityPanic
 )
 
 // IsValid returns whether s is valid.
@@ -34,7 +54,7 @@ func (s Severity) IsValid() bool {
 
 // CheckValid returns ErrInvalidSeverity for invalid s.
 func (s Severity) CheckValid() error {
-	if !(SeverityNone <= s && s <= SeverityDebug) {
+	if !(SeverityNone <= s && s <= SeverityPanic) {
 		return ErrInvalidSeverity
 	}
 	return nil
@@ -56,12 +76,22 @@ func (s Severity) MarshalText() (text []byte, err error) {
 	switch s {
 	case SeverityNone:
 		str = "NONE"
+	case SeverityEmergency:
+		str = "EMERGENCY"
+	case SeverityAlert:
+		str = "ALERT"
 	case SeverityFatal:
 		str = "FATAL"
+	case SeverityPanic:
+		str = "PANIC"
+	case SeverityCritical:
+		str = "CRITICAL"
 	case SeverityError:
 		str = "ERROR"
 	case SeverityWarning:
 		str = "WARNING"
+	case SeverityNotice:
+		str = "NOTICE"
 	case SeverityInfo:
 		str = "INFO"
 	case SeverityDebug:
@@ -78,12 +108,22 @@ func (s *Severity) UnmarshalText(text []byte) error {
 	switch str := strings.ToUpper(string(text)); str {
 	case "NONE":
 		*s = SeverityNone
+	case "EMERGENCY":
+		*s = SeverityEmergency
+	case "ALERT":
+		*s = SeverityAlert
 	case "FATAL":
 		*s = SeverityFatal
+	case "PANIC":
+		*s = SeverityPanic
+	case "CRITICAL":
+		*s = SeverityCritical
 	case "ERROR":
 		*s = SeverityError
 	case "WARNING":
 		*s = SeverityWarning
+	case "NOTICE":
+		*s = SeverityNotice
 	case "INFO":
 		*s = SeverityInfo
 	case "DEBUG":
@@ -94,6 +134,103 @@ func (s *Severity) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Set is the implementation of flag.Value.
+// It sets the severity by the given name or numeric value, which is parsed by ParseSeverity.
+func (s *Severity) Set(value string) error {
+	severity, err := ParseSeverity(value)
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// SeverityFromString returns the Severity by the given name.
+// Unlike Severity.UnmarshalText, it also accepts common aliases such as "warn", "err", "information", "crit" and
+// "emerg".
+// The name is case-insensitive and surrounding white spaces are ignored.
+// If name is unknown, it returns ErrUnknownSeverity.
+func SeverityFromString(name string) (Severity, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, x := range severityNames {
+		for _, n := range x.names {
+			if n == name {
+				return x.severity, nil
+			}
+		}
+	}
+	return SeverityNone, ErrUnknownSeverity
+}
+
+// severityNames lists the severities in the order of their ranks, with the lower-case names and aliases which are
+// accepted by SeverityFromString. The first name of each severity is its name.
+var severityNames = []struct {
+	severity Severity
+	names    []string
+}{
+	{SeverityNone, []string{"none"}},
+	{SeverityEmergency, []string{"emergency", "emerg"}},
+	{SeverityAlert, []string{"alert"}},
+	{SeverityFatal, []string{"fatal"}},
+	{SeverityPanic, []string{"panic", "dpanic"}},
+	{SeverityCritical, []string{"critical", "crit"}},
+	{SeverityError, []string{"error", "err"}},
+	{SeverityWarning, []string{"warning", "warn"}},
+	{SeverityNotice, []string{"notice"}},
+	{SeverityInfo, []string{"info", "information", "informational"}},
+	{SeverityDebug, []string{"debug", "dbg", "trace"}},
+}
+
+// ParseSeverity parses the given name or numeric value of Severity. The numeric values are the values of
+// the Severity constants, e.g. 2 for SeverityError.
+// The name is parsed by SeverityFromString, so it accepts the common aliases such as "warn" too.
+// If the numeric value is out of range, it returns ErrInvalidSeverity. If the name is unknown,
+// it returns ErrUnknownSeverity.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.TrimSpace(s)
+	if x, err := strconv.Atoi(s); err == nil {
+		severity := Severity(x)
+		if err := severity.CheckValid(); err != nil {
+			return SeverityNone, err
+		}
+		return severity, nil
+	}
+	return SeverityFromString(s)
+}
+
+// Rank returns the rank of s in the order from the most severe to the least severe: 0 for none, 1 for emergency,
+// 2 for alert, 3 for fatal, 4 for panic, 5 for critical, 6 for error, 7 for warning, 8 for notice, 9 for info and
+// 10 for debug. The numeric values of the severities which have been added later don't follow this order,
+// so severities must be compared by their ranks. If s is invalid, it returns -1.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityNone:
+		return 0
+	case SeverityEmergency:
+		return 1
+	case SeverityAlert:
+		return 2
+	case SeverityFatal:
+		return 3
+	case SeverityPanic:
+		return 4
+	case SeverityCritical:
+		return 5
+	case SeverityError:
+		return 6
+	case SeverityWarning:
+		return 7
+	case SeverityNotice:
+		return 8
+	case SeverityInfo:
+		return 9
+	case SeverityDebug:
+		return 10
+	default:
+		return -1
+	}
+}
+
 // custom severities
 const (
 	severityPrint Severity = -iota - 1
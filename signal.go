@@ -0,0 +1,90 @@
+package logng
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// HandleVerboseSignals starts listening for the signals SIGUSR1 and SIGUSR2 to raise and lower the default Logger's
+// verbosity at runtime. See Logger.HandleVerboseSignals.
+func HandleVerboseSignals() (stop func()) {
+	return defaultLogger.HandleVerboseSignals()
+}
+
+// HandleVerboseSignals starts listening for the signals SIGUSR1 and SIGUSR2 to raise and lower the underlying
+// Logger's verbosity at runtime, for quick diagnostics on live processes:
+//
+//	kill -USR1 <pid>
+//
+// SIGUSR1 sets the severity to SeverityDebug at first, and then increases the verbose by one on each signal.
+// SIGUSR2 reverts the changes in the reverse order; it decreases the verbose until zero, and then restores
+// the severity before the first SIGUSR1. Each change is logged as a warning log by the Logger.
+// It returns a function to stop listening. On the platforms without SIGUSR1 and SIGUSR2, e.g. windows,
+// it does nothing.
+func (l *Logger) HandleVerboseSignals() (stop func()) {
+	raiseSig, lowerSig := verboseSignals()
+	if l == nil || raiseSig == nil || lowerSig == nil {
+		return func() {}
+	}
+	sigCh := make(chan os.Signal, 1)
+	stopCh := make(chan struct{})
+	signal.Notify(sigCh, raiseSig, lowerSig)
+	go func() {
+		h := &verboseSignalHandler{l: l}
+		for {
+			select {
+			case <-stopCh:
+				return
+			case sig := <-sigCh:
+				switch sig {
+				case raiseSig:
+					h.raise()
+				case lowerSig:
+					h.lower()
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(stopCh)
+		})
+	}
+}
+
+// verboseSignalHandler raises and lowers the verbosity of the Logger on signals.
+type verboseSignalHandler struct {
+	l            *Logger
+	origSeverity Severity
+	raised       bool
+}
+
+func (h *verboseSignalHandler) raise() {
+	h.l.mu.Lock()
+	if !h.raised && h.l.severity != SeverityDebug {
+		h.origSeverity = h.l.severity
+		h.raised = true
+		h.l.severity = SeverityDebug
+	} else {
+		h.l.verbose++
+	}
+	severity, verbose := h.l.severity, h.l.verbose
+	h.l.mu.Unlock()
+	h.l.Warningf("log verbosity raised by signal: severity=%v verbose=%v", severity, verbose)
+}
+
+func (h *verboseSignalHandler) lower() {
+	h.l.mu.Lock()
+	if h.l.verbose > 0 {
+		h.l.verbose--
+	} else if h.raised {
+		h.raised = false
+		h.l.severity = h.origSeverity
+	}
+	severity, verbose := h.l.severity, h.l.verbose
+	h.l.mu.Unlock()
+	h.l.Warningf("log verbosity lowered by signal: severity=%v verbose=%v", severity, verbose)
+}
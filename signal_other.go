@@ -0,0 +1,13 @@
+//go:build windows || plan9 || js || wasip1
+// +build windows plan9 js wasip1
+
+package logng
+
+import (
+	"os"
+)
+
+// verboseSignals returns nil signals, because the platform doesn't have SIGUSR1 and SIGUSR2.
+func verboseSignals() (raise, lower os.Signal) {
+	return nil, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9 && !js && !wasip1
+// +build !windows,!plan9,!js,!wasip1
+
+package logng
+
+import (
+	"os"
+	"syscall"
+)
+
+// verboseSignals returns the signals to raise and lower the verbosity.
+func verboseSignals() (raise, lower os.Signal) {
+	return syscall.SIGUSR1, syscall.SIGUSR2
+}
@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+package logng_test
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/goinsane/logng/v2"
+)
+
+func ExampleSlogHandler() {
+	output := logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity|logng.TextOutputFlagShortFile)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	slogger := slog.New(logng.NewSlogHandler(logger))
+
+	slogger.Debug("this is debug log. it won't be shown.")
+	slogger.Warn("this is warning log.")
+
+	json := logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields)
+	logger.SetOutput(json)
+	slogger.WithGroup("request").With("method", "GET").Info("this is info log.", "status", 200)
+
+	// Output:
+	// WARNING - slog_test.go:19 - this is warning log.
+	// {"severity":"INFO","message":"this is info log.","_request.method":"GET","_request.status":200}
+}
+
+func ExampleSlogHandler_Enabled() {
+	// set logng for this example.
+	logng.Reset()
+	logng.SetOutput(logng.NewJSONOutput(os.Stdout, logng.JSONOutputFlagSeverity|logng.JSONOutputFlagFields))
+
+	// the severity overrides of the named loggers are honoured.
+	slogger := slog.New(logng.NewSlogHandler(logng.GetLogger("slog")))
+	slogger.Debug("this is debug log. it won't be shown.")
+	logng.SetLoggerSeverity("slog", logng.SeverityDebug)
+	defer logng.UnsetLoggerLevels("slog")
+	slogger.Debug("this is debug log of slog.")
+
+	// the verbose of vmodule is resolved by the caller.
+	logger := logng.NewLogger(logng.NewTextOutput(os.Stdout, logng.TextOutputFlagSeverity), logng.SeverityInfo, 0)
+	if err := logger.SetVModule("slog_test=2"); err != nil {
+		panic(err)
+	}
+	slog.New(logng.NewSlogHandler(logger.WithVerbosity(2))).Info("this is info log, verbosity 2.")
+	slog.New(logng.NewSlogHandler(logger.WithVerbosity(3))).Info("this is info log, verbosity 3. it won't be shown.")
+
+	// Output:
+	// {"severity":"DEBUG","message":"this is debug log of slog.","_logger":"slog"}
+	// INFO - this is info log, verbosity 2.
+}
+
+func ExampleSlogOutput() {
+	slogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger := logng.NewLogger(logng.NewSlogOutput(slogger), logng.SeverityDebug, 0)
+
+	logger.Debug("this is debug log. it won't be shown.")
+	logger.WithFieldKeyVals("key1", "val1").Warning("this is warning log.")
+
+	// Output:
+	// level=WARN msg="this is warning log." key1=val1
+}
@@ -0,0 +1,126 @@
+//go:build go1.21
+// +build go1.21
+
+package logng
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is an implementation of slog.Handler by forwarding records into the given Logger.
+// slog levels are mapped to Severity, and attributes are mapped to Fields.
+// The keys of attributes in groups are prefixed with the group names separated by dots.
+type SlogHandler struct {
+	logger *Logger
+	fields Fields
+	prefix string
+}
+
+// NewSlogHandler creates a new SlogHandler by the given logger.
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+// Enabled is the implementation of slog.Handler.
+// It resolves the severity and the verbose as Logger.Enabled does, so the overrides of the named Loggers are honoured.
+// The verbose of vmodule is resolved by the caller of the slog.Logger's logging method, e.g. slog.Logger.Info.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	// the callers are slog.Logger.Enabled, slog.Logger.log and the logging method.
+	return h.logger.enabled(SeverityFromSlogLevel(level), 4)
+}
+
+// Handle is the implementation of slog.Handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ctxFields := contextFields(ctx)
+	fields := make(Fields, 0, len(ctxFields)+len(h.fields)+r.NumAttrs())
+	fields = append(fields, ctxFields...)
+	fields = append(fields, h.fields...)
+	var err error
+	r.Attrs(func(a slog.Attr) bool {
+		if e, ok := a.Value.Resolve().Any().(error); ok && err == nil {
+			err = e
+		}
+		fields = appendSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	h.logger.out(SeverityFromSlogLevel(r.Level), r.Message, err, &logOptions{
+		fields: fields,
+		time:   r.Time,
+		pc:     r.PC,
+	})
+	return nil
+}
+
+// WithAttrs is the implementation of slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := &SlogHandler{
+		logger: h.logger,
+		fields: make(Fields, 0, len(h.fields)+len(attrs)),
+		prefix: h.prefix,
+	}
+	h2.fields = append(h2.fields, h.fields...)
+	for _, a := range attrs {
+		h2.fields = appendSlogAttr(h2.fields, h.prefix, a)
+	}
+	return h2
+}
+
+// WithGroup is the implementation of slog.Handler.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger: h.logger,
+		fields: h.fields.Clone(),
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// SeverityFromSlogLevel returns the Severity by the given slog level.
+// It is the reverse of SlogLevelFromSeverity, e.g. the levels which are 2 greater than slog.LevelError are
+// mapped to SeverityCritical.
+func SeverityFromSlogLevel(level slog.Level) Severity {
+	switch {
+	case level >= slogLevelEmergency:
+		return SeverityEmergency
+	case level >= slogLevelAlert:
+		return SeverityAlert
+	case level >= slogLevelFatal:
+		return SeverityFatal
+	case level >= slogLevelPanic:
+		return SeverityPanic
+	case level >= slogLevelCritical:
+		return SeverityCritical
+	case level >= slog.LevelError:
+		return SeverityError
+	case level >= slog.LevelWarn:
+		return SeverityWarning
+	case level >= slogLevelNotice:
+		return SeverityNotice
+	case level >= slog.LevelInfo:
+		return SeverityInfo
+	default:
+		return SeverityDebug
+	}
+}
+
+func appendSlogAttr(fields Fields, prefix string, a slog.Attr) Fields {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, a2 := range v.Group() {
+			fields = appendSlogAttr(fields, prefix, a2)
+		}
+		return fields
+	}
+	if a.Key == "" {
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: v.Any()})
+}
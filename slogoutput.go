@@ -0,0 +1,112 @@
+//go:build go1.21
+// +build go1.21
+
+package logng
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"unsafe"
+)
+
+// slog levels of the severities which are greater than slog.LevelError, and SeverityNotice.
+const (
+	slogLevelEmergency = slog.LevelError + 8
+	slogLevelAlert     = slog.LevelError + 6
+	slogLevelFatal     = slog.LevelError + 4
+	slogLevelPanic     = slog.LevelError + 3
+	slogLevelCritical  = slog.LevelError + 2
+	slogLevelNotice    = slog.LevelInfo + 2
+)
+
+// SlogOutput is an implementation of Output by forwarding logs to slog.Logger.
+// Severity is mapped to slog level, and Fields are mapped to attributes.
+// Log.Error is added as the attribute "error", and Log.StackCaller is used as the source of records.
+type SlogOutput struct {
+	logger  *slog.Logger
+	onError *func(error)
+}
+
+// NewSlogOutput creates a new SlogOutput by the given slog logger.
+func NewSlogOutput(logger *slog.Logger) *SlogOutput {
+	return &SlogOutput{
+		logger: logger,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *SlogOutput) Log(log *Log) {
+	ctx := context.Background()
+	handler := o.logger.Handler()
+	level := SlogLevelFromSeverity(log.Severity)
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(log.Time, level, string(log.Message), log.StackCaller.PC)
+	for _, field := range log.Fields {
+		r.AddAttrs(slogAttrFromField(field))
+	}
+	if log.Error != nil {
+		r.AddAttrs(slog.Any("error", log.Error))
+	}
+	err := handler.Handle(ctx, r)
+	if err == nil {
+		return
+	}
+	onError := o.onError
+	if onError == nil || *onError == nil {
+		return
+	}
+	(*onError)(fmt.Errorf("unable to handle record: %w", err))
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying SlogOutput.
+func (o *SlogOutput) SetOnError(f func(error)) *SlogOutput {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.onError)), unsafe.Pointer(&f))
+	return o
+}
+
+// SlogLevelFromSeverity returns the slog level by the given Severity.
+// SeverityCritical, SeverityPanic, SeverityFatal, SeverityAlert and SeverityEmergency are mapped to the levels
+// which are 2, 3, 4, 6 and 8 greater than slog.LevelError. SeverityNotice is mapped to a level which is 2 greater than slog.LevelInfo.
+func SlogLevelFromSeverity(severity Severity) slog.Level {
+	switch severity {
+	case SeverityEmergency:
+		return slogLevelEmergency
+	case SeverityAlert:
+		return slogLevelAlert
+	case SeverityFatal:
+		return slogLevelFatal
+	case SeverityPanic:
+		return slogLevelPanic
+	case SeverityCritical:
+		return slogLevelCritical
+	case SeverityError:
+		return slog.LevelError
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityNotice:
+		return slogLevelNotice
+	case SeverityDebug:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// slogAttrFromField converts the given field to slog.Attr. GroupValue is converted to slog group.
+func slogAttrFromField(field Field) slog.Attr {
+	value := fieldValue(field.Value)
+	g, ok := value.(GroupValue)
+	if !ok {
+		return slog.Any(field.Key, value)
+	}
+	attrs := make([]slog.Attr, 0, len(g))
+	for _, field2 := range g {
+		attrs = append(attrs, slogAttrFromField(field2))
+	}
+	return slog.Attr{Key: field.Key, Value: slog.GroupValue(attrs...)}
+}
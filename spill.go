@@ -0,0 +1,321 @@
+package logng
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// queueSpill is the disk-backed overflow of QueuedOutput. The logs are appended to the spill file as json lines,
+// and read in the same order to replay.
+type queueSpill struct {
+	mu     sync.Mutex
+	path   string
+	w      *os.File
+	r      *os.File
+	br     *bufio.Reader
+	count  int
+	size   int64
+	head   *Log
+	notify chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// errSpillFull is returned by queueSpill.write when the spill file has reached its maximum size.
+var errSpillFull = errors.New("spill file is full")
+
+// spillRecord is the json representation of a spilled log.
+type spillRecord struct {
+	Message    string       `json:"message"`
+	Error      string       `json:"error,omitempty"`
+	Severity   string       `json:"severity"`
+	Verbosity  int          `json:"verbosity"`
+	Time       time.Time    `json:"time"`
+	Fields     []spillField `json:"fields,omitempty"`
+	Caller     *spillCaller `json:"caller,omitempty"`
+	StackTrace string       `json:"stack_trace,omitempty"`
+}
+
+type spillField struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type spillCaller struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// newQueueSpill creates a new queueSpill by truncating or creating the file at the given path.
+func newQueueSpill(path string) (*queueSpill, error) {
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open spill file: %w", err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("unable to open spill file: %w", err)
+	}
+	return &queueSpill{
+		path:   path,
+		w:      w,
+		r:      r,
+		br:     bufio.NewReader(r),
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+// len returns the count of the logs in the spill file.
+func (s *queueSpill) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// write appends the given log to the spill file. It returns errSpillFull if maxSize is positive and the spill file
+// would exceed maxSize bytes.
+func (s *queueSpill) write(log *Log, maxSize int64) error {
+	b, err := marshalSpillRecord(log)
+	if err != nil {
+		return fmt.Errorf("unable to encode spill record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if maxSize > 0 && s.size+int64(len(b)) > maxSize {
+		return errSpillFull
+	}
+	if _, err = s.w.Write(b); err != nil {
+		return fmt.Errorf("unable to write to spill file: %w", err)
+	}
+	s.count++
+	s.size += int64(len(b))
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// peek returns the oldest log in the spill file without removing it.
+// It returns false if the spill file is empty. The records which can't be read are skipped by calling skip.
+func (s *queueSpill) peek(skip func(error)) (*Log, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.head == nil {
+		if s.count <= 0 {
+			return nil, false
+		}
+		line, err := s.br.ReadBytes('\n')
+		if err == nil {
+			s.head, err = unmarshalSpillRecord(line)
+		}
+		if err != nil {
+			s.count--
+			s.reset()
+			skip(fmt.Errorf("unable to read spill record: %w", err))
+		}
+	}
+	return s.head, true
+}
+
+// advance removes the oldest log which is returned by peek. The spill file is truncated when it becomes empty.
+func (s *queueSpill) advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.head = nil
+	s.count--
+	s.reset()
+}
+
+// reset truncates the spill file if it is empty. It must be called with s.mu held.
+func (s *queueSpill) reset() {
+	if s.count > 0 {
+		return
+	}
+	s.count = 0
+	if err := s.w.Truncate(0); err != nil {
+		return
+	}
+	s.size = 0
+	if _, err := s.r.Seek(0, 0); err != nil {
+		return
+	}
+	s.br.Reset(s.r)
+}
+
+// close closes and removes the spill file.
+func (s *queueSpill) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.r.Close()
+	err := s.w.Close()
+	if e := os.Remove(s.path); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// SetSpillFile sets the disk-backed overflow of the underlying QueuedOutput by creating or truncating the file at
+// the given path. When the queue is full, the logs are written to the spill file instead of being dropped,
+// and replayed to the output in the same order when the queue has capacity again. While the spill file isn't empty,
+// the new logs are written to the spill file too, so the order of the logs is preserved.
+// The spill file is removed by Close.
+//
+// The spilled logs are encoded in json. So, the errors are restored as plain errors by their texts, the field values
+// are restored from json, and the stack traces are restored as the field "stack_trace".
+//
+// SetSpillFile must be called before logging, and only once.
+func (o *QueuedOutput) SetSpillFile(path string) error {
+	if o.spill != nil {
+		return errors.New("spill file already set")
+	}
+	s, err := newQueueSpill(path)
+	if err != nil {
+		return err
+	}
+	o.spill = s
+	s.wg.Add(1)
+	go o.spiller(s)
+	return nil
+}
+
+// SetSpillMaxSize sets the maximum size of the spill file in bytes. When the spill file is full, the new log is
+// dropped like the queue is full, instead of being sent to the queue ahead of the spilled logs, so the order of
+// the logs is preserved. The dropped logs are counted in Dropped and SpillDropped of Stats.
+// It returns the underlying QueuedOutput.
+// By default, 0 means no limit.
+func (o *QueuedOutput) SetSpillMaxSize(maxSize int64) *QueuedOutput {
+	atomic.StoreInt64(&o.spillMaxSize, maxSize)
+	return o
+}
+
+// spillLog writes the given log to the spill file. It returns false if the log couldn't be written
+// by an error other than the spill file is full.
+func (o *QueuedOutput) spillLog(log *Log) bool {
+	if err := o.spill.write(log, atomic.LoadInt64(&o.spillMaxSize)); err != nil {
+		if err == errSpillFull {
+			atomic.AddUint64(&o.spillDropped, 1)
+			o.addPending(-1)
+			o.queueFull(log)
+			return true
+		}
+		handleError(err)
+		return false
+	}
+	atomic.AddUint64(&o.spilled, 1)
+	return true
+}
+
+// spiller replays the logs in the spill file to the queue until the spill file is stopped and empty.
+func (o *QueuedOutput) spiller(s *queueSpill) {
+	defer s.wg.Done()
+	skip := func(err error) {
+		handleError(err)
+		atomic.AddUint64(&o.dropped, 1)
+		o.addPending(-1)
+	}
+	stopping := false
+	for {
+		if log, ok := s.peek(skip); ok {
+			o.queue <- log
+			atomic.AddUint64(&o.enqueued, 1)
+			s.advance()
+			continue
+		}
+		if stopping {
+			return
+		}
+		select {
+		case <-s.notify:
+		case <-s.stop:
+			stopping = true
+		}
+	}
+}
+
+// marshalSpillRecord encodes the given log as a json line.
+// Field values are encoded in json, and the stack trace is formatted as text.
+func marshalSpillRecord(log *Log) ([]byte, error) {
+	rec := &spillRecord{
+		Message:   string(log.Message),
+		Severity:  log.Severity.String(),
+		Verbosity: int(log.Verbosity),
+		Time:      log.Time,
+		Fields:    make([]spillField, 0, len(log.Fields)),
+	}
+	if log.Error != nil {
+		rec.Error = log.Error.Error()
+	}
+	for _, field := range log.Fields {
+		value, err := json.Marshal(fieldValue(field.Value))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprintf("%v", field.Value))
+		}
+		rec.Fields = append(rec.Fields, spillField{Key: field.Key, Value: value})
+	}
+	if log.StackCaller.Function != "" {
+		rec.Caller = &spillCaller{
+			Function: log.StackCaller.Function,
+			File:     log.StackCaller.File,
+			Line:     log.StackCaller.Line,
+		}
+	}
+	if log.StackTrace != nil {
+		rec.StackTrace = fmt.Sprintf("%+.1s", log.StackTrace)
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// unmarshalSpillRecord decodes the given json line to Log.
+// The error is restored as a plain error by its text, and field values are decoded from json.
+// Because StackTrace can't be restored, the stack trace is added as the field "stack_trace".
+func unmarshalSpillRecord(line []byte) (*Log, error) {
+	var rec spillRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, err
+	}
+	var severity Severity
+	_ = severity.UnmarshalText([]byte(rec.Severity))
+	log := &Log{
+		Message:   []byte(rec.Message),
+		Severity:  severity,
+		Verbosity: Verbose(rec.Verbosity),
+		Time:      rec.Time,
+		Fields:    make(Fields, 0, len(rec.Fields)+1),
+	}
+	if rec.Error != "" {
+		log.Error = errors.New(rec.Error)
+	}
+	for _, field := range rec.Fields {
+		var value interface{}
+		dec := json.NewDecoder(bytes.NewReader(field.Value))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			value = string(field.Value)
+		}
+		log.Fields = append(log.Fields, Field{Key: field.Key, Value: value})
+	}
+	if rec.Caller != nil {
+		log.StackCaller.Function = rec.Caller.Function
+		log.StackCaller.File = rec.Caller.File
+		log.StackCaller.Line = rec.Caller.Line
+	}
+	if rec.StackTrace != "" {
+		log.Fields = append(log.Fields, Field{Key: "stack_trace", Value: rec.StackTrace})
+	}
+	return log, nil
+}
@@ -0,0 +1,246 @@
+package logng
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLColumns holds the names of the columns which logs are inserted into.
+// The values of the columns with empty names are not inserted.
+type SQLColumns struct {
+	// Message is the column of Log.Message as string.
+	Message string
+
+	// Severity is the column of the severity name.
+	Severity string
+
+	// Time is the column of Log.Time.
+	Time string
+
+	// Fields is the column of Log.Fields as a json object.
+	Fields string
+
+	// Error is the column of Log.Error as string, or NULL if there is no error.
+	Error string
+
+	// Verbosity is the column of Log.Verbosity as integer.
+	Verbosity string
+}
+
+// SQLPlaceholder is the type of bind parameter style of SQL drivers.
+type SQLPlaceholder int
+
+const (
+	// SQLPlaceholderQuestion uses ? as placeholders, e.g. for MySQL and SQLite.
+	SQLPlaceholderQuestion SQLPlaceholder = iota
+
+	// SQLPlaceholderDollar uses $1, $2, ... as placeholders, e.g. for PostgreSQL.
+	SQLPlaceholderDollar
+)
+
+// SQLOutput is an implementation of Output by inserting logs into a table via database/sql.
+//
+// Logs are inserted in batches by a background goroutine, when the batch is full or the flush interval elapses.
+// Each batch is inserted in a transaction by a prepared statement.
+// The table and column names are used in the statement as they are, so they must be quoted if needed.
+type SQLOutput struct {
+	mu      sync.Mutex
+	db      *sql.DB
+	columns SQLColumns
+	stmt    *sql.Stmt
+	timeout time.Duration
+	batcher batcher
+}
+
+// NewSQLOutput creates a new SQLOutput by the given database, table, columns and placeholder style.
+// It prepares the insert statement, and returns the error if the statement can't be prepared.
+// By default, the batch size is 100, the flush interval is 1 second, the maximum pending count is 10000
+// and the timeout of each batch is 10 seconds.
+func NewSQLOutput(db *sql.DB, table string, columns SQLColumns, placeholder SQLPlaceholder) (*SQLOutput, error) {
+	names := columns.names()
+	if len(names) == 0 {
+		return nil, errors.New("no columns")
+	}
+	params := make([]string, 0, len(names))
+	for i := range names {
+		if placeholder == SQLPlaceholderDollar {
+			params = append(params, fmt.Sprintf("$%d", i+1))
+		} else {
+			params = append(params, "?")
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(params, ", "))
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare statement: %w", err)
+	}
+	o := &SQLOutput{
+		db:      db,
+		columns: columns,
+		stmt:    stmt,
+		timeout: 10 * time.Second,
+	}
+	o.batcher.start(100, time.Second, o.insert, nil)
+	return o, nil
+}
+
+// Log is the implementation of Output.
+func (o *SQLOutput) Log(log *Log) {
+	row, err := o.columns.values(log)
+	if err != nil {
+		o.batcher.handleError(err)
+		return
+	}
+	o.batcher.add(row)
+}
+
+// Flush inserts the pending logs immediately, and returns the error if the batch can't be inserted.
+func (o *SQLOutput) Flush() error {
+	return o.batcher.flush()
+}
+
+// Close stops the background goroutine, inserts the pending logs and closes the prepared statement.
+// It doesn't close the database. Logs after closing are dropped with ErrClosed.
+func (o *SQLOutput) Close() error {
+	err := o.batcher.close()
+	if e := o.stmt.Close(); e != nil && err == nil {
+		err = fmt.Errorf("unable to close statement: %w", e)
+	}
+	return err
+}
+
+// SetBatchSize sets the number of logs to insert in a transaction. If batchSize is less than 1, it is set to 1.
+// It returns the underlying SQLOutput.
+func (o *SQLOutput) SetBatchSize(batchSize int) *SQLOutput {
+	o.batcher.setBatchSize(batchSize)
+	return o
+}
+
+// SetFlushInterval sets the maximum duration to wait before inserting a batch which is not full.
+// It takes effect after the next flush. If flushInterval is less or equal than 0, the batch is only inserted when full.
+// It returns the underlying SQLOutput.
+func (o *SQLOutput) SetFlushInterval(flushInterval time.Duration) *SQLOutput {
+	o.batcher.setFlushInterval(flushInterval)
+	return o
+}
+
+// SetMaxPending sets the maximum number of logs waiting to be inserted. The logs beyond it are dropped with
+// ErrQueueFull. If maxPending is less or equal than 0, there is no limit.
+// It returns the underlying SQLOutput.
+func (o *SQLOutput) SetMaxPending(maxPending int) *SQLOutput {
+	o.batcher.setMaxPending(maxPending)
+	return o
+}
+
+// SetTimeout sets the timeout of inserting each batch. If timeout is less or equal than 0, inserting doesn't time out.
+// It returns the underlying SQLOutput.
+func (o *SQLOutput) SetTimeout(timeout time.Duration) *SQLOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timeout = timeout
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying SQLOutput.
+func (o *SQLOutput) SetOnError(f func(error)) *SQLOutput {
+	o.batcher.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *SQLOutput) Err() error {
+	return o.batcher.err()
+}
+
+// Healthy reports whether the last batch has been inserted successfully.
+// It returns true if no batch has been inserted yet.
+func (o *SQLOutput) Healthy() bool {
+	return o.batcher.healthy()
+}
+
+// insert inserts the given batch of rows in a transaction.
+func (o *SQLOutput) insert(rows []interface{}) (err error) {
+	o.mu.Lock()
+	timeout := o.timeout
+	o.mu.Unlock()
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	stmt := tx.StmtContext(ctx, o.stmt)
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err = stmt.ExecContext(ctx, row.([]interface{})...); err != nil {
+			return fmt.Errorf("unable to insert %d logs: %w", len(rows), err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// names returns the names of the columns which are not empty, in the order of values.
+func (c SQLColumns) names() []string {
+	names := make([]string, 0, 6)
+	for _, name := range []string{c.Message, c.Severity, c.Time, c.Fields, c.Error, c.Verbosity} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// values returns the values of the columns which are not empty by the given log.
+func (c SQLColumns) values(log *Log) ([]interface{}, error) {
+	values := make([]interface{}, 0, 6)
+	if c.Message != "" {
+		values = append(values, string(log.Message))
+	}
+	if c.Severity != "" {
+		values = append(values, log.Severity.String())
+	}
+	if c.Time != "" {
+		values = append(values, log.Time)
+	}
+	if c.Fields != "" {
+		buf := bytes.NewBuffer(make([]byte, 0, 1024))
+		obj := newJSONObject(buf)
+		for _, field := range log.Fields {
+			if err := obj.add(field.Key, field.Value); err != nil {
+				return nil, err
+			}
+		}
+		obj.close()
+		values = append(values, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	if c.Error != "" {
+		var x sql.NullString
+		if log.Error != nil {
+			x.String, x.Valid = log.Error.Error(), true
+		}
+		values = append(values, x)
+	}
+	if c.Verbosity != "" {
+		values = append(values, int64(log.Verbosity))
+	}
+	return values, nil
+}
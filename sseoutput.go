@@ -0,0 +1,236 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEOutput is an implementation of Output and http.Handler by streaming json encoded logs to the connected
+// clients as Server-Sent Events, e.g. for live-tailing a running process:
+//
+//	curl -N 'http://localhost:8080/debug/logs?severity=error&field=user=john'
+//
+// Each client receives the logs at or above the severity given by the query parameter "severity", and
+// having all fields given by the query parameters "field" as key=value. By default, clients receive all logs.
+// Logs are queued for each client, and they are dropped for the slow clients when their queues are full.
+type SSEOutput struct {
+	mu                sync.RWMutex
+	json              *JSONOutput
+	clients           map[*sseClient]struct{}
+	queueSize         int
+	heartbeatInterval time.Duration
+	stopped           bool
+	stopCh            chan struct{}
+	health
+}
+
+// NewSSEOutput creates a new SSEOutput by the given json flags.
+// By default, the queue size of each client is 256 and the heartbeat interval is 15 seconds.
+func NewSSEOutput(flags JSONOutputFlag) *SSEOutput {
+	return &SSEOutput{
+		json:              NewJSONOutput(nil, flags),
+		clients:           make(map[*sseClient]struct{}),
+		queueSize:         256,
+		heartbeatInterval: 15 * time.Second,
+		stopCh:            make(chan struct{}),
+	}
+}
+
+// Log is the implementation of Output.
+func (o *SSEOutput) Log(log *Log) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if len(o.clients) == 0 {
+		return
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	buf.WriteString("data: ")
+	o.json.mu.RLock()
+	err := o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		o.handleError(err)
+		return
+	}
+	buf.WriteRune('\n')
+	event := buf.Bytes()
+
+	healthy := true
+	for c := range o.clients {
+		if !c.match(log) {
+			continue
+		}
+		select {
+		case c.queue <- event:
+		default:
+			healthy = false
+			o.handleError(fmt.Errorf("unable to send log to client %s: %w", c.remoteAddr, ErrQueueFull))
+		}
+	}
+	if healthy {
+		o.setHealthy()
+	}
+}
+
+// ServeHTTP is the implementation of http.Handler.
+// ServeHTTP streams logs until the request is canceled or the output is closed.
+func (o *SSEOutput) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	c := &sseClient{
+		remoteAddr: req.RemoteAddr,
+		severity:   SeverityDebug,
+	}
+	query := req.URL.Query()
+	if name := query.Get("severity"); name != "" {
+		var err error
+		if c.severity, err = SeverityFromString(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, kv := range query["field"] {
+		idx := strings.Index(kv, "=")
+		if idx < 0 {
+			http.Error(w, fmt.Sprintf("invalid field filter %q", kv), http.StatusBadRequest)
+			return
+		}
+		c.fields = append(c.fields, [2]string{kv[:idx], kv[idx+1:]})
+	}
+
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		http.Error(w, ErrClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	c.queue = make(chan []byte, o.queueSize)
+	o.clients[c] = struct{}{}
+	heartbeatInterval := o.heartbeatInterval
+	o.mu.Unlock()
+
+	defer func() {
+		o.mu.Lock()
+		delete(o.clients, c)
+		o.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(": connected\n\n"))
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-req.Context().Done():
+			return
+		case <-o.stopCh:
+			return
+		case event := <-c.queue:
+			_, err = w.Write(event)
+		case <-ticker.C:
+			_, err = w.Write([]byte(": heartbeat\n\n"))
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// Close disconnects all clients, and stops accepting new clients.
+func (o *SSEOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		return nil
+	}
+	o.stopped = true
+	close(o.stopCh)
+	return nil
+}
+
+// Clients returns the number of connected clients.
+func (o *SSEOutput) Clients() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.clients)
+}
+
+// SetQueueSize sets the queue size of the clients which connect after the call.
+// It returns the underlying SSEOutput.
+func (o *SSEOutput) SetQueueSize(queueSize int) *SSEOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.queueSize = queueSize
+	return o
+}
+
+// SetHeartbeatInterval sets the interval of the comments which are sent to keep idle connections alive.
+// It returns the underlying SSEOutput.
+func (o *SSEOutput) SetHeartbeatInterval(heartbeatInterval time.Duration) *SSEOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.heartbeatInterval = heartbeatInterval
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying SSEOutput.
+func (o *SSEOutput) SetOnError(f func(error)) *SSEOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *SSEOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been queued to all clients successfully.
+// It returns true if no log has been queued yet.
+func (o *SSEOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// sseClient is a connected client of SSEOutput.
+type sseClient struct {
+	remoteAddr string
+	severity   Severity
+	fields     [][2]string
+	queue      chan []byte
+}
+
+// match reports whether the given log passes the filters of the client.
+func (c *sseClient) match(log *Log) bool {
+	if log.Severity.Rank() > c.severity.Rank() {
+		return false
+	}
+	for _, kv := range c.fields {
+		found := false
+		for _, field := range log.Fields {
+			if field.Key == kv[0] && fmt.Sprintf("%v", field.Value) == kv[1] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -98,6 +98,25 @@ func CurrentStackTrace(size, skip int) *StackTrace {
 	return newStackTrace(pc)
 }
 
+// stackTraceFromPC creates a new StackTrace which starts from the given program counter of the current stack.
+// If full is false or the program counter isn't in the current stack, the StackTrace has only the given program counter.
+func stackTraceFromPC(pc uintptr, full bool, size int) *StackTrace {
+	if full {
+		pcs := make([]uintptr, size+32)
+		pcs = pcs[:runtime.Callers(1, pcs)]
+		for i := range pcs {
+			if pcs[i] == pc {
+				pcs = pcs[i:]
+				if len(pcs) > size {
+					pcs = pcs[:size]
+				}
+				return newStackTrace(pcs)
+			}
+		}
+	}
+	return newStackTrace([]uintptr{pc})
+}
+
 // newStackTrace creates a new StackTrace from program counters without copying.
 func newStackTrace(programCounters []uintptr) *StackTrace {
 	t := &StackTrace{
@@ -0,0 +1,12 @@
+package logng
+
+import (
+	"log"
+)
+
+// StdLogger returns a standard library logger which logs every written line by the given severity to the underlying Logger.
+// It is useful for APIs which only accept *log.Logger such as http.Server.ErrorLog.
+// If severity is invalid, it uses SeverityInfo.
+func (l *Logger) StdLogger(severity Severity) *log.Logger {
+	return log.New(NewLineWriter(l.WithCallerSkip(2), severity), "", 0)
+}
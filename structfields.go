@@ -0,0 +1,67 @@
+package logng
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldsFromStruct returns the exported members of the given struct, or pointer to struct, as Fields.
+// It returns nil if v isn't a struct or is a nil pointer.
+//
+// The field keys are the member names by default, and can be customized by the "logng" tag with options:
+//
+//	Name     string `logng:"name"`             // the key is "name".
+//	Email    string `logng:"email,omitempty"`  // omitted if the value is zero.
+//	Password string `logng:"password,secret"`  // the value is wrapped by Secret.
+//	Internal string `logng:"-"`                // always omitted.
+func FieldsFromStruct(v interface{}) Fields {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	fields := make(Fields, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := sf.Tag.Get("logng")
+		if tag == "-" {
+			continue
+		}
+		key, opts := sf.Name, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			tag, opts = tag[:idx], tag[idx:]
+		}
+		if tag != "" {
+			key = tag
+		}
+		fv := rv.Field(i)
+		if strings.Contains(opts, ",omitempty") && fv.IsZero() {
+			continue
+		}
+		var value interface{} = fv.Interface()
+		if strings.Contains(opts, ",secret") {
+			value = Secret(value)
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	return fields
+}
+
+// WithStruct clones the default Logger with the fields of the given struct. See FieldsFromStruct.
+func WithStruct(v interface{}) *Logger {
+	return defaultLogger.WithStruct(v)
+}
+
+// WithStruct clones the underlying Logger with the fields of the given struct. See FieldsFromStruct.
+func (l *Logger) WithStruct(v interface{}) *Logger {
+	return l.WithFields(FieldsFromStruct(v)...)
+}
@@ -0,0 +1,393 @@
+package logng
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFacility is the type of syslog facility.
+type SyslogFacility int
+
+const (
+	SyslogFacilityKern SyslogFacility = iota
+	SyslogFacilityUser
+	SyslogFacilityMail
+	SyslogFacilityDaemon
+	SyslogFacilityAuth
+	SyslogFacilitySyslog
+	SyslogFacilityLPR
+	SyslogFacilityNews
+	SyslogFacilityUUCP
+	SyslogFacilityCron
+	SyslogFacilityAuthPriv
+	SyslogFacilityFTP
+)
+
+const (
+	SyslogFacilityLocal0 SyslogFacility = iota + 16
+	SyslogFacilityLocal1
+	SyslogFacilityLocal2
+	SyslogFacilityLocal3
+	SyslogFacilityLocal4
+	SyslogFacilityLocal5
+	SyslogFacilityLocal6
+	SyslogFacilityLocal7
+)
+
+// SyslogFormat is the type of syslog message format.
+type SyslogFormat int
+
+const (
+	// SyslogFormatRFC3164 is the BSD syslog format: <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG.
+	SyslogFormatRFC3164 SyslogFormat = iota
+
+	// SyslogFormatRFC5424 is the syslog protocol format with structured data generated from fields:
+	// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID - [SD-ID key="value"] MSG.
+	SyslogFormatRFC5424
+)
+
+// SyslogOutput is an implementation of Output by sending logs to a syslog server.
+// It supports local unix sockets, and remote UDP, TCP and TLS transports.
+// Messages over TCP and TLS are framed by octet counting in RFC5424 format, and by new line in RFC3164 format.
+// In RFC3164 format, the new lines in the messages are escaped as #012 over TCP and TLS.
+type SyslogOutput struct {
+	mu          sync.Mutex
+	dialMu      sync.Mutex
+	network     string
+	addr        string
+	tlsConfig   *tls.Config
+	dialTimeout time.Duration
+	conn        net.Conn
+	local       bool
+	format      SyslogFormat
+	facility    SyslogFacility
+	tag         string
+	hostname    string
+	sdID        string
+	stopped     bool
+	health
+}
+
+// NewSyslogOutput creates a new SyslogOutput by connecting to the syslog server on the given network and address.
+// If network is empty, it connects to the local syslog server by unix socket.
+// By default, the format is SyslogFormatRFC3164, the facility is SyslogFacilityUser, the tag is the program name,
+// the structured data ID is "logng@32473", and the dial timeout is 10 seconds.
+func NewSyslogOutput(network, addr string) (*SyslogOutput, error) {
+	return newSyslogOutput(network, addr, nil)
+}
+
+// NewSyslogTLSOutput creates a new SyslogOutput by connecting to the syslog server on the given address over TLS.
+func NewSyslogTLSOutput(addr string, config *tls.Config) (*SyslogOutput, error) {
+	return newSyslogOutput("tcp", addr, config)
+}
+
+func newSyslogOutput(network, addr string, config *tls.Config) (*SyslogOutput, error) {
+	hostname, _ := os.Hostname()
+	o := &SyslogOutput{
+		network:     network,
+		addr:        addr,
+		tlsConfig:   config,
+		dialTimeout: 10 * time.Second,
+		local:       network == "",
+		format:      SyslogFormatRFC3164,
+		facility:    SyslogFacilityUser,
+		tag:         trimDirs(os.Args[0]),
+		hostname:    hostname,
+		sdID:        "logng@32473",
+	}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Log is the implementation of Output.
+func (o *SyslogOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		err = ErrClosed
+		return
+	}
+	if o.conn != nil {
+		if _, err = o.conn.Write(o.message(log)); err == nil {
+			o.mu.Unlock()
+			return
+		}
+		_ = o.conn.Close()
+		o.conn = nil
+	}
+	o.mu.Unlock()
+
+	if err = o.connect(); err != nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.conn == nil {
+		err = fmt.Errorf("unable to write to syslog: %w", ErrClosed)
+		return
+	}
+	if _, err = o.conn.Write(o.message(log)); err != nil {
+		err = fmt.Errorf("unable to write to syslog: %w", err)
+		return
+	}
+}
+
+// Flush is the implementation of Flusher. SyslogOutput writes each log to the connection without buffering,
+// so there is nothing to flush.
+func (o *SyslogOutput) Flush() error {
+	return nil
+}
+
+// Close closes the connection to the syslog server.
+// Logs after closing are dropped with ErrClosed.
+func (o *SyslogOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stopped = true
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
+
+// SetFormat sets the message format.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetFormat(format SyslogFormat) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.format = format
+	return o
+}
+
+// SetFacility sets the facility.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetFacility(facility SyslogFacility) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.facility = facility
+	return o
+}
+
+// SetTag sets the tag, which is used as APP-NAME in RFC5424 format.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetTag(tag string) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tag = tag
+	return o
+}
+
+// SetHostname sets the hostname. By default, the hostname reported by the kernel.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetHostname(hostname string) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.hostname = hostname
+	return o
+}
+
+// SetStructuredDataID sets the SD-ID of the structured data generated from fields in RFC5424 format.
+// The default "logng@32473" uses the example enterprise number of RFC5612, so it should be set to an SD-ID
+// under the private enterprise number of your organization, e.g. "myapp@12345".
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetStructuredDataID(sdID string) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sdID = sdID
+	return o
+}
+
+// SetDialTimeout sets the timeout of connecting to the syslog server. Zero means no timeout.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetDialTimeout(timeout time.Duration) *SyslogOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.dialTimeout = timeout
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying SyslogOutput.
+func (o *SyslogOutput) SetOnError(f func(error)) *SyslogOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *SyslogOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been sent successfully.
+// It returns true if no log has been sent yet.
+func (o *SyslogOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// connect connects to the syslog server, unless another goroutine has connected meanwhile.
+// It doesn't hold mu while dialing, so logging isn't blocked by the dial timeout.
+func (o *SyslogOutput) connect() (err error) {
+	o.dialMu.Lock()
+	defer o.dialMu.Unlock()
+	o.mu.Lock()
+	if o.conn != nil {
+		o.mu.Unlock()
+		return nil
+	}
+	network, local, tlsConfig := o.network, o.local, o.tlsConfig
+	dialer := &net.Dialer{Timeout: o.dialTimeout}
+	o.mu.Unlock()
+
+	var conn net.Conn
+	switch {
+	case local:
+		for _, network = range []string{"unixgram", "unix"} {
+			for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+				if conn, err = dialer.Dial(network, path); err == nil {
+					break
+				}
+			}
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("unable to connect to local syslog: %w", err)
+		}
+	case tlsConfig != nil:
+		conn, err = tls.DialWithDialer(dialer, network, o.addr, tlsConfig)
+	default:
+		conn, err = dialer.Dial(network, o.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to connect to syslog: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		_ = conn.Close()
+		return ErrClosed
+	}
+	o.conn = conn
+	o.network = network
+	return nil
+}
+
+// message formats the given log as a syslog message with framing.
+func (o *SyslogOutput) message(log *Log) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	pri := int(o.facility)*8 + syslogSeverity(log.Severity)
+	tm := log.Time
+	if tm.IsZero() {
+		tm = time.Now()
+	}
+	tag := o.tag
+	if tag == "" {
+		tag = "-"
+	}
+	stream := strings.HasPrefix(o.network, "tcp") || o.network == "unix"
+
+	switch o.format {
+	case SyslogFormatRFC5424:
+		hostname := o.hostname
+		if hostname == "" {
+			hostname = "-"
+		}
+		buf.WriteString(fmt.Sprintf("<%d>1 %s %s %s %d - ",
+			pri, tm.Format("2006-01-02T15:04:05.000000Z07:00"), hostname, tag, os.Getpid()))
+		buf.Write(syslogStructuredData(o.sdID, log.Fields))
+		if len(log.Message) > 0 {
+			buf.WriteRune(' ')
+			buf.Write(log.Message)
+		}
+	default:
+		buf.WriteString(fmt.Sprintf("<%d>%s ", pri, tm.Format(time.Stamp)))
+		if !o.local && o.hostname != "" {
+			buf.WriteString(o.hostname)
+			buf.WriteRune(' ')
+		}
+		buf.WriteString(fmt.Sprintf("%s[%d]: ", tag, os.Getpid()))
+		if stream {
+			buf.Write(bytes.Replace(log.Message, []byte("\n"), []byte("#012"), -1))
+		} else {
+			buf.Write(log.Message)
+		}
+	}
+
+	if !stream {
+		return buf.Bytes()
+	}
+	if o.format == SyslogFormatRFC5424 {
+		return append([]byte(strconv.Itoa(buf.Len())+" "), buf.Bytes()...)
+	}
+	buf.WriteRune('\n')
+	return buf.Bytes()
+}
+
+// syslogSeverity returns the syslog severity by the given Severity.
+func syslogSeverity(severity Severity) int {
+	switch severity {
+	case SeverityEmergency:
+		return 0
+	case SeverityAlert:
+		return 1
+	case SeverityFatal, SeverityPanic, SeverityCritical:
+		return 2
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 4
+	case SeverityNotice:
+		return 5
+	case SeverityInfo:
+		return 6
+	case SeverityDebug:
+		return 7
+	default:
+		return 5
+	}
+}
+
+// syslogStructuredData returns the structured data of RFC5424 by the given SD-ID and fields.
+func syslogStructuredData(sdID string, fields Fields) []byte {
+	if len(fields) == 0 || sdID == "" {
+		return []byte("-")
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	buf.WriteString("[" + sdID)
+	for _, field := range fields {
+		name := strings.Map(func(r rune) rune {
+			if r <= ' ' || r >= 127 || r == '=' || r == ']' || r == '"' {
+				return '_'
+			}
+			return r
+		}, field.Key)
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		if name == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fmt.Sprintf("%v", field.Value))
+		buf.WriteString(" " + name + `="` + value + `"`)
+	}
+	buf.WriteRune(']')
+	return buf.Bytes()
+}
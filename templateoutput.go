@@ -0,0 +1,122 @@
+package logng
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data which is passed to the template of TemplateOutput for each log.
+type TemplateData struct {
+	Message     string
+	Error       error
+	Severity    Severity
+	Verbosity   Verbose
+	Time        time.Time
+	Fields      Fields
+	StackCaller StackCaller
+	StackTrace  *StackTrace
+}
+
+// TemplateOutput is an implementation of Output by writing logs rendered by text/template to io.Writer w.
+// The template is executed with *TemplateData, e.g.:
+//
+//	{{.Time.Format "15:04:05"}} [{{.Severity}}] {{.Message}}{{range .Fields}} {{.Key}}={{.Value}}{{end}}
+//
+// A new line is appended if the rendered text doesn't end with a new line.
+type TemplateOutput struct {
+	mu   sync.RWMutex
+	w    io.Writer
+	tmpl *template.Template
+	health
+}
+
+// NewTemplateOutput creates a new TemplateOutput by the given writer and template.
+func NewTemplateOutput(w io.Writer, tmpl *template.Template) *TemplateOutput {
+	return &TemplateOutput{
+		w:    w,
+		tmpl: tmpl,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *TemplateOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		o.report(err)
+	}()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	err = o.tmpl.Execute(buf, &TemplateData{
+		Message:     string(log.Message),
+		Error:       log.Error,
+		Severity:    log.Severity,
+		Verbosity:   log.Verbosity,
+		Time:        log.Time,
+		Fields:      log.Fields,
+		StackCaller: log.StackCaller,
+		StackTrace:  log.StackTrace,
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to execute template: %w", err)
+		return
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteRune('\n')
+	}
+
+	_, err = io.Copy(o.w, buf)
+	if err != nil {
+		err = fmt.Errorf("unable to write to writer: %w", err)
+		return
+	}
+}
+
+// Flush is the implementation of Flusher. It flushes the writer if it implements Flusher, e.g. BufferedWriter.
+func (o *TemplateOutput) Flush() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return flushWriter(o.w)
+}
+
+// SetWriter sets writer.
+// It returns the underlying TemplateOutput.
+func (o *TemplateOutput) SetWriter(w io.Writer) *TemplateOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.w = w
+	return o
+}
+
+// SetTemplate sets the template to render logs.
+// It returns the underlying TemplateOutput.
+func (o *TemplateOutput) SetTemplate(tmpl *template.Template) *TemplateOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tmpl = tmpl
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying TemplateOutput.
+func (o *TemplateOutput) SetOnError(f func(error)) *TemplateOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *TemplateOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been written successfully.
+// It returns true if no log has been written yet.
+func (o *TemplateOutput) Healthy() bool {
+	return o.healthy()
+}
@@ -0,0 +1,25 @@
+package logng
+
+import (
+	"io"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd reports whether the given file descriptor is a terminal.
+func isTerminalFd(fd uintptr) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
+
+// enableVirtualTerminal enables processing ANSI escape sequences on the given terminal.
+// Terminals process them natively on this platform.
+func enableVirtualTerminal(fd uintptr) bool {
+	return true
+}
+
+// newLegacyConsoleWriter returns nil, because terminals process ANSI escape sequences natively on this platform.
+func newLegacyConsoleWriter(w io.Writer, fd uintptr) io.Writer {
+	return nil
+}
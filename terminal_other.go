@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd,!windows
+
+package logng
+
+import "io"
+
+// isTerminalFd reports whether the given file descriptor is a terminal.
+// Terminals can't be detected on this platform.
+func isTerminalFd(fd uintptr) bool {
+	return false
+}
+
+// enableVirtualTerminal enables processing ANSI escape sequences on the given terminal.
+func enableVirtualTerminal(fd uintptr) bool {
+	return false
+}
+
+// newLegacyConsoleWriter returns nil, because there is no console API on this platform.
+func newLegacyConsoleWriter(w io.Writer, fd uintptr) io.Writer {
+	return nil
+}
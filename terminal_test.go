@@ -0,0 +1,65 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package logng_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// ttyWriter writes to a buffer, and reports the file descriptor of a terminal to be detected as a terminal.
+type ttyWriter struct {
+	strings.Builder
+	fd uintptr
+}
+
+func (w *ttyWriter) Fd() uintptr {
+	return w.fd
+}
+
+func TestTextOutput_noColor(t *testing.T) {
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("unable to open terminal: %v", err)
+	}
+	defer tty.Close()
+	noColor, ok := os.LookupEnv("NO_COLOR")
+	defer func() {
+		if ok {
+			_ = os.Setenv("NO_COLOR", noColor)
+		} else {
+			_ = os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	const colored, plain = "\x1b[32mINFO\x1b[0m - log\n", "INFO - log\n"
+	for _, tt := range []struct {
+		noColor string
+		color   *bool
+		want    string
+	}{
+		{"", nil, colored},
+		{"1", nil, plain},
+		{"1", newBool(true), colored},
+		{"", newBool(false), plain},
+	} {
+		_ = os.Setenv("NO_COLOR", tt.noColor)
+		w := &ttyWriter{fd: tty.Fd()}
+		output := logng.NewTextOutput(w, logng.TextOutputFlagSeverity|logng.TextOutputFlagColor)
+		if tt.color != nil {
+			output.SetColor(*tt.color)
+		}
+		logng.NewLogger(output, logng.SeverityInfo, 0).Info("log")
+		if got := w.String(); got != tt.want {
+			t.Errorf("got %q with NO_COLOR %q, want %q", got, tt.noColor, tt.want)
+		}
+	}
+}
+
+func newBool(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,155 @@
+package logng
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag ENABLE_VIRTUAL_TERMINAL_PROCESSING.
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleMode             = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+	procSetConsoleTextAttribute    = kernel32.NewProc("SetConsoleTextAttribute")
+)
+
+// Console character attributes.
+const (
+	consoleForegroundIntensity = 0x0008
+	consoleForegroundMask      = 0x000f
+	consoleBackgroundIntensity = 0x0080
+	consoleBackgroundMask      = 0x00f0
+)
+
+// consoleColors maps the ANSI colors from black to white to the console color bits of blue, green and red.
+var consoleColors = [8]uint16{0, 4, 2, 6, 1, 5, 3, 7}
+
+// consoleScreenBufferInfo is the CONSOLE_SCREEN_BUFFER_INFO structure.
+type consoleScreenBufferInfo struct {
+	size              [2]int16
+	cursorPosition    [2]int16
+	attributes        uint16
+	window            [4]int16
+	maximumWindowSize [2]int16
+}
+
+// isTerminalFd reports whether the given file descriptor is a console.
+func isTerminalFd(fd uintptr) bool {
+	var mode uint32
+	r, _, _ := procGetConsoleMode.Call(fd, uintptr(unsafe.Pointer(&mode)))
+	return r != 0
+}
+
+// enableVirtualTerminal enables processing ANSI escape sequences on the given console.
+// It reports false if the console doesn't support virtual terminal processing, e.g. on Windows versions before 10.
+func enableVirtualTerminal(fd uintptr) bool {
+	var mode uint32
+	if r, _, _ := procGetConsoleMode.Call(fd, uintptr(unsafe.Pointer(&mode))); r == 0 {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(fd, uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
+
+// legacyConsoleWriter is an implementation of io.Writer by writing to a console which doesn't support virtual
+// terminal processing, e.g. on Windows versions before 10. It converts the ANSI SGR escape sequences to
+// the console text attributes, and writes the rest of the text to the underlying writer.
+// An escape sequence must not be split between writes.
+type legacyConsoleWriter struct {
+	w        io.Writer
+	fd       uintptr
+	defaults uint16
+	attr     uint16
+}
+
+// newLegacyConsoleWriter creates a new legacyConsoleWriter by the given writer and its console.
+// It returns nil if the console attributes can't be got.
+func newLegacyConsoleWriter(w io.Writer, fd uintptr) io.Writer {
+	var info consoleScreenBufferInfo
+	if r, _, _ := procGetConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info))); r == 0 {
+		return nil
+	}
+	return &legacyConsoleWriter{
+		w:        w,
+		fd:       fd,
+		defaults: info.attributes,
+		attr:     info.attributes,
+	}
+}
+
+// Write is the implementation of io.Writer.
+func (w *legacyConsoleWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		i := bytes.Index(p, []byte("\x1b["))
+		if i < 0 {
+			m, err := w.w.Write(p)
+			return n + m, err
+		}
+		j := bytes.IndexByte(p[i:], 'm')
+		if j < 0 {
+			m, err := w.w.Write(p)
+			return n + m, err
+		}
+		j += i
+		if i > 0 {
+			m, err := w.w.Write(p[:i])
+			n += m
+			if err != nil {
+				return n, err
+			}
+		}
+		w.setGraphicRendition(p[i+2 : j])
+		n += j + 1 - i
+		p = p[j+1:]
+	}
+	return n, nil
+}
+
+// setGraphicRendition sets the console text attributes by the given SGR parameters, e.g. "1;31".
+func (w *legacyConsoleWriter) setGraphicRendition(params []byte) {
+	attr := w.attr
+	for _, param := range bytes.Split(params, []byte(";")) {
+		x, err := strconv.Atoi(string(param))
+		if err != nil {
+			if len(param) > 0 {
+				continue
+			}
+			x = 0
+		}
+		switch {
+		case x == 0:
+			attr = w.defaults
+		case x == 1:
+			attr |= consoleForegroundIntensity
+		case x == 22:
+			attr &^= consoleForegroundIntensity
+		case x >= 30 && x <= 37:
+			attr = attr&^(consoleForegroundMask&^consoleForegroundIntensity) | consoleColors[x-30]
+		case x == 39:
+			attr = attr&^consoleForegroundMask | w.defaults&consoleForegroundMask
+		case x >= 40 && x <= 47:
+			attr = attr&^(consoleBackgroundMask&^consoleBackgroundIntensity) | consoleColors[x-40]<<4
+		case x == 49:
+			attr = attr&^consoleBackgroundMask | w.defaults&consoleBackgroundMask
+		case x >= 90 && x <= 97:
+			attr = attr&^consoleForegroundMask | consoleColors[x-90] | consoleForegroundIntensity
+		case x >= 100 && x <= 107:
+			attr = attr&^consoleBackgroundMask | (consoleColors[x-100]<<4 | consoleBackgroundIntensity)
+		}
+	}
+	if attr == w.attr {
+		return
+	}
+	if r, _, _ := procSetConsoleTextAttribute.Call(w.fd, uintptr(attr)); r != 0 {
+		w.attr = attr
+	}
+}
@@ -0,0 +1,119 @@
+package logng
+
+import (
+	"sync"
+	"time"
+)
+
+// TriggerOutput is an implementation of Output by buffering the logs below the trigger severity,
+// and passing them to the given output only when a log at or above the trigger severity arrives.
+// So the output gets the debug context around errors without the constant volume of debug logs.
+//
+// The buffered logs which are older than the window are discarded, and the oldest buffered logs are discarded
+// when the buffer exceeds the maximum size. When triggered, the buffered logs are passed before the trigger log.
+type TriggerOutput struct {
+	mu       sync.Mutex
+	output   Output
+	severity Severity
+	window   time.Duration
+	maxSize  int
+	logs     []*Log
+}
+
+// NewTriggerOutput creates a new TriggerOutput by the given output, trigger severity and window.
+// If window is less or equal than 0, the buffered logs are discarded only by the maximum size.
+// By default, the maximum size is 1000.
+func NewTriggerOutput(output Output, severity Severity, window time.Duration) *TriggerOutput {
+	return &TriggerOutput{
+		output:   output,
+		severity: severity,
+		window:   window,
+		maxSize:  1000,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *TriggerOutput) Log(log *Log) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.discard(log.Time)
+
+	if log.Severity.Rank() > o.severity.Rank() {
+		o.logs = append(o.logs, log)
+		if n := len(o.logs) - o.maxSize; n > 0 {
+			o.shift(n)
+		}
+		return
+	}
+
+	for _, l := range o.logs {
+		o.output.Log(l)
+	}
+	o.shift(len(o.logs))
+	o.output.Log(log)
+}
+
+// Flush is the implementation of Flusher. It passes the buffered logs to the output regardless of the trigger,
+// and then flushes the output.
+func (o *TriggerOutput) Flush() error {
+	o.mu.Lock()
+	for _, l := range o.logs {
+		o.output.Log(l)
+	}
+	o.shift(len(o.logs))
+	o.mu.Unlock()
+	return flushOutput(o.output)
+}
+
+// Close is the implementation of io.Closer. It discards the buffered logs, and closes the output.
+func (o *TriggerOutput) Close() error {
+	o.mu.Lock()
+	o.shift(len(o.logs))
+	o.mu.Unlock()
+	return closeOutput(o.output)
+}
+
+// SetMaxSize sets the maximum number of logs to buffer. If maxSize is less than 1, it is set to 1.
+// It returns the underlying TriggerOutput.
+func (o *TriggerOutput) SetMaxSize(maxSize int) *TriggerOutput {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxSize = maxSize
+	if n := len(o.logs) - o.maxSize; n > 0 {
+		o.shift(n)
+	}
+	return o
+}
+
+// discard discards the buffered logs which are older than the window, relative to the given time.
+func (o *TriggerOutput) discard(now time.Time) {
+	if o.window <= 0 {
+		return
+	}
+	n := 0
+	for _, l := range o.logs {
+		if now.Sub(l.Time) <= o.window {
+			break
+		}
+		n++
+	}
+	o.shift(n)
+}
+
+// shift removes the first n buffered logs.
+func (o *TriggerOutput) shift(n int) {
+	if n <= 0 {
+		return
+	}
+	for i := 0; i < n; i++ {
+		o.logs[i] = nil
+	}
+	o.logs = o.logs[n:]
+	if len(o.logs) == 0 {
+		o.logs = nil
+	}
+}
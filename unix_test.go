@@ -0,0 +1,111 @@
+//go:build !windows && !plan9 && !js && !wasip1
+// +build !windows,!plan9,!js,!wasip1
+
+package logng_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/goinsane/logng/v2"
+)
+
+func TestFileOutput_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logng-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	output, err := logng.NewFileOutput(path, logng.TextOutputFlagSeverity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+
+	// the file is moved by an external tool such as logrotate, and reopened explicitly.
+	logger.Info("first.")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("second.")
+
+	// the file is moved, and reopened on signal.
+	output.ReopenOnSignal(syscall.SIGHUP)
+	if err := os.Rename(path, path+".2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file hasn't been reopened on signal")
+		}
+	}
+	logger.Info("third.")
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		path + ".1": "INFO - first.\n",
+		path + ".2": "INFO - second.\n",
+		path:        "INFO - third.\n",
+	} {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q, want %q", filepath.Base(name), data, want)
+		}
+	}
+}
+
+type chanOutput chan string
+
+func (o chanOutput) Log(log *logng.Log) {
+	o <- string(log.Message)
+}
+
+func TestLogger_HandleVerboseSignals(t *testing.T) {
+	output := make(chanOutput, 1)
+	logger := logng.NewLogger(output, logng.SeverityInfo, 0)
+	stop := logger.HandleVerboseSignals()
+	defer stop()
+
+	for _, test := range []struct {
+		sig  syscall.Signal
+		want string
+	}{
+		{syscall.SIGUSR1, "log verbosity raised by signal: severity=DEBUG verbose=0"},
+		{syscall.SIGUSR1, "log verbosity raised by signal: severity=DEBUG verbose=1"},
+		{syscall.SIGUSR2, "log verbosity lowered by signal: severity=DEBUG verbose=0"},
+		{syscall.SIGUSR2, "log verbosity lowered by signal: severity=INFO verbose=0"},
+	} {
+		if err := syscall.Kill(os.Getpid(), test.sig); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-output:
+			if got != test.want {
+				t.Errorf("%v: got %q, want %q", test.sig, got, test.want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: signal hasn't been handled", test.sig)
+		}
+	}
+}
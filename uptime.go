@@ -0,0 +1,58 @@
+package logng
+
+import (
+	"time"
+)
+
+// processStartTime is the time when the package is initialized, approximately the process start time.
+var processStartTime = time.Now()
+
+// StartTime returns the time when the package is initialized, which the uptimes are measured from.
+func StartTime() time.Time {
+	return processStartTime
+}
+
+// Uptime returns the elapsed duration since the process start.
+func Uptime() time.Duration {
+	return time.Since(processStartTime)
+}
+
+// logUptime returns the elapsed duration from the process start to the time of the given log.
+// It returns 0 if the time of the log is before the process start.
+func logUptime(log *Log) time.Duration {
+	d := log.Time.Sub(processStartTime)
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// appendUptime appends the given duration as seconds with microsecond resolution into b: [12.345678].
+func appendUptime(b *[]byte, d time.Duration) {
+	*b = append(*b, '[')
+	itoa(b, int(d/time.Second), -1)
+	*b = append(*b, '.')
+	itoa(b, int(d%time.Second/time.Microsecond), 6)
+	*b = append(*b, ']')
+}
+
+// WithUptime clones the default Logger with the uptime field. See Logger.WithUptime.
+func WithUptime(key string) *Logger {
+	return defaultLogger.WithUptime(key)
+}
+
+// WithUptime clones the underlying Logger, and stamps each log with the field which has the given key and
+// the elapsed seconds since this call. The key is "uptime" if it is empty.
+// To have the elapsed duration since the process start, use TextOutputFlagUptime or JSONOutputFlagUptime.
+func (l *Logger) WithUptime(key string) *Logger {
+	if l == nil {
+		return nil
+	}
+	if key == "" {
+		key = "uptime"
+	}
+	start := time.Now()
+	return l.WithFields(Lazy(key, func() interface{} {
+		return time.Since(start).Seconds()
+	}))
+}
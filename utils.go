@@ -2,6 +2,8 @@ package logng
 
 import (
 	"fmt"
+	"io"
+	"os"
 )
 
 // wrappedError is an interface to simulate GoLang's wrapped errors.
@@ -47,3 +49,26 @@ func getPadWidPrec(f fmt.State) (pad byte, wid, prec int) {
 	}
 	return
 }
+
+// colorWriter returns the writer to write ANSI colored text to the given writer, and reports whether colors are
+// enabled. Colors are disabled if the writer isn't a terminal, or the environment variable NO_COLOR is set and
+// not empty. On Windows, it enables virtual terminal processing on the console, or falls back to the console API
+// on the consoles which don't support it. Otherwise, the returned writer is w.
+func colorWriter(w io.Writer) (io.Writer, bool) {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return w, false
+	}
+	fd := f.Fd()
+	if !isTerminalFd(fd) {
+		return w, false
+	}
+	color := os.Getenv("NO_COLOR") == ""
+	if enableVirtualTerminal(fd) {
+		return w, color
+	}
+	if lw := newLegacyConsoleWriter(w, fd); lw != nil {
+		return lw, color
+	}
+	return w, false
+}
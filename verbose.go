@@ -1,4 +1,35 @@
 package logng
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Verbose is the type of verbose level.
 type Verbose int
+
+// String is the implementation of fmt.Stringer.
+func (v Verbose) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// Set is the implementation of flag.Value.
+// It sets the verbose by parsing the given decimal value by ParseVerbose.
+func (v *Verbose) Set(value string) error {
+	x, err := ParseVerbose(value)
+	if err != nil {
+		return err
+	}
+	*v = x
+	return nil
+}
+
+// ParseVerbose parses the given decimal value of Verbose. Surrounding white spaces are ignored.
+func ParseVerbose(s string) (Verbose, error) {
+	x, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid verbose %q", s)
+	}
+	return Verbose(x), nil
+}
@@ -0,0 +1,158 @@
+package logng
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// vmodule holds the parsed patterns of SetVModule and caches the verbose of the callers by their program counters.
+type vmodule struct {
+	spec     string
+	patterns []vmodulePattern
+	cache    sync.Map
+}
+
+// vmodulePattern is a single pattern=level of vmodule.
+type vmodulePattern struct {
+	pattern string
+	verbose Verbose
+}
+
+// vmoduleResult is the cached result of a caller.
+type vmoduleResult struct {
+	verbose Verbose
+	ok      bool
+}
+
+// parseVModule parses the given comma-separated list of pattern=level.
+// It returns nil without error if spec is empty.
+func parseVModule(spec string) (*vmodule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	vm := &vmodule{spec: spec}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid vmodule item %q", item)
+		}
+		pattern := strings.TrimSuffix(strings.TrimSpace(item[:idx]), ".go")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule pattern %q: %w", pattern, err)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(item[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid vmodule level %q", item[idx+1:])
+		}
+		vm.patterns = append(vm.patterns, vmodulePattern{pattern: pattern, verbose: Verbose(level)})
+	}
+	return vm, nil
+}
+
+// String returns the spec of the underlying vmodule.
+func (vm *vmodule) String() string {
+	if vm == nil {
+		return ""
+	}
+	return vm.spec
+}
+
+// verbose returns the verbose of the first pattern matching the caller at the given program counter.
+func (vm *vmodule) verbose(pc uintptr) (Verbose, bool) {
+	if r, ok := vm.cache.Load(pc); ok {
+		result := r.(vmoduleResult)
+		return result.verbose, result.ok
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	var result vmoduleResult
+	for _, p := range vm.patterns {
+		if vmoduleMatch(p.pattern, frame) {
+			result = vmoduleResult{verbose: p.verbose, ok: true}
+			break
+		}
+	}
+	vm.cache.Store(pc, result)
+	return result.verbose, result.ok
+}
+
+// vmoduleMatch reports whether the given pattern matches the frame.
+// A pattern without slash is matched against the file name without ".go" suffix, and the last element of
+// the package path. A pattern with slash is matched against the trailing elements of the file path without ".go"
+// suffix, and the package path.
+func vmoduleMatch(pattern string, frame runtime.Frame) bool {
+	file := strings.TrimSuffix(frame.File, ".go")
+	pkg := funcPackage(frame.Function)
+	if !strings.Contains(pattern, "/") {
+		if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			return true
+		}
+		ok, _ := path.Match(pattern, path.Base(pkg))
+		return ok
+	}
+	if ok, _ := path.Match(pattern, pkg); ok {
+		return true
+	}
+	n := strings.Count(pattern, "/")
+	idx := len(file)
+	for i := 0; i <= n && idx >= 0; i++ {
+		idx = strings.LastIndex(file[:idx], "/")
+	}
+	ok, _ := path.Match(pattern, file[idx+1:])
+	return ok
+}
+
+// funcPackage returns the package path of the given function name of runtime.Frame.
+func funcPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// SetVModule sets the default Logger's per-module verbose. See Logger.SetVModule.
+func SetVModule(vmodule string) error {
+	return defaultLogger.SetVModule(vmodule)
+}
+
+// SetVModule sets the underlying Logger's per-module verbose by the given glog-style comma-separated list of
+// pattern=level, e.g. "handler=2,github.com/foo/bar/*=3". The patterns are matched against the caller's file
+// name and package path in the order, and the level of the first matching pattern is used instead of the Logger's
+// verbose. See path.Match for the pattern syntax. An empty vmodule clears the patterns.
+// It returns an error and doesn't change the Logger if vmodule is invalid.
+func (l *Logger) SetVModule(vmodule string) error {
+	if l == nil {
+		return nil
+	}
+	vm, err := parseVModule(vmodule)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.vmodule = vm
+	return nil
+}
+
+// callerPC returns the program counter of the caller, skipping the given number of stack frames and
+// the Logger's caller skip. It returns 0 if the Logger has neither vmodule nor call site limit.
+// It must be called with l.mu held.
+func (l *Logger) callerPC(skip int) uintptr {
+	if l.vmodule == nil && l.callSiteLimit == nil {
+		return 0
+	}
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(skip+1+l.callerSkip, pcs) < 1 {
+		return 0
+	}
+	return pcs[0]
+}
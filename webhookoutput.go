@@ -0,0 +1,309 @@
+package logng
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WebhookFormat is the type of payload format of WebhookOutput.
+type WebhookFormat int
+
+const (
+	// WebhookFormatSlack posts {"text": "..."} for Slack incoming webhooks.
+	WebhookFormatSlack WebhookFormat = iota
+
+	// WebhookFormatDiscord posts {"content": "..."} for Discord webhooks.
+	WebhookFormatDiscord
+
+	// WebhookFormatTeams posts {"text": "..."} for Microsoft Teams incoming webhooks.
+	WebhookFormatTeams
+)
+
+// WebhookData is the data which is passed to the template of WebhookOutput for each log.
+type WebhookData struct {
+	Severity Severity
+	Message  string
+	Error    error
+	Time     time.Time
+	Fields   Fields
+	Log      *Log
+}
+
+// WebhookOutput is an implementation of Output by posting logs at or above the minimum severity to a chat webhook.
+//
+// Logs are rendered as lines by the template, and they are posted by a background goroutine.
+// The logs in a burst are batched into a single message: after the first log, the output waits for the batch window,
+// and never posts more often than the rate limit. The lines exceeding the maximum lines are summarized as a count.
+type WebhookOutput struct {
+	mu          sync.Mutex
+	url         string
+	format      WebhookFormat
+	client      *http.Client
+	severity    Severity
+	tmpl        *template.Template
+	batchWindow time.Duration
+	rateLimit   time.Duration
+	maxLines    int
+	lines       []string
+	overflow    int
+	postMu      sync.Mutex
+	notifyCh    chan struct{}
+	stopCh      chan struct{}
+	stopped     bool
+	wg          sync.WaitGroup
+	health
+}
+
+// NewWebhookOutput creates a new WebhookOutput by the given webhook url and format.
+// By default, the minimum severity is SeverityError, the batch window is 2 seconds, the rate limit is 1 second,
+// the maximum lines is 20, and logs are rendered by their String method.
+func NewWebhookOutput(url string, format WebhookFormat) *WebhookOutput {
+	o := &WebhookOutput{
+		url:         url,
+		format:      format,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		severity:    SeverityError,
+		batchWindow: 2 * time.Second,
+		rateLimit:   time.Second,
+		maxLines:    20,
+		notifyCh:    make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+	}
+	o.wg.Add(1)
+	go o.worker()
+	return o
+}
+
+// Log is the implementation of Output.
+func (o *WebhookOutput) Log(log *Log) {
+	var err error
+	defer func() {
+		if err != nil {
+			o.handleError(err)
+		}
+	}()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if log.Severity.Rank() > o.severity.Rank() {
+		return
+	}
+	if o.stopped {
+		err = ErrClosed
+		return
+	}
+	if o.maxLines > 0 && len(o.lines) >= o.maxLines {
+		o.overflow++
+		return
+	}
+
+	line := log.String()
+	if o.tmpl != nil {
+		buf := bytes.NewBuffer(make([]byte, 0, 1024))
+		err = o.tmpl.Execute(buf, &WebhookData{
+			Severity: log.Severity,
+			Message:  string(log.Message),
+			Error:    log.Error,
+			Time:     log.Time,
+			Fields:   log.Fields,
+			Log:      log,
+		})
+		if err != nil {
+			err = fmt.Errorf("unable to execute template: %w", err)
+			return
+		}
+		line = buf.String()
+	}
+	o.lines = append(o.lines, line)
+	select {
+	case o.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// Flush is the implementation of Flusher. It posts the pending logs immediately regardless of the batch window
+// and the rate limit, and returns the error if the message can't be posted.
+func (o *WebhookOutput) Flush() error {
+	_, err := o.post()
+	return err
+}
+
+// Close posts the pending logs and stops the background goroutine.
+// Logs after closing are dropped with ErrClosed.
+func (o *WebhookOutput) Close() error {
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		return nil
+	}
+	o.stopped = true
+	close(o.stopCh)
+	o.mu.Unlock()
+	o.wg.Wait()
+	return nil
+}
+
+// SetSeverity sets the minimum severity of logs to post.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetSeverity(severity Severity) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.severity = severity
+	return o
+}
+
+// SetTemplate sets the template to render each log as a line. The template is executed with *WebhookData.
+// If tmpl is nil, logs are rendered by their String method.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetTemplate(tmpl *template.Template) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tmpl = tmpl
+	return o
+}
+
+// SetBatchWindow sets the duration to wait for more logs after the first log of a burst.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetBatchWindow(batchWindow time.Duration) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.batchWindow = batchWindow
+	return o
+}
+
+// SetRateLimit sets the minimum duration between two posts.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetRateLimit(rateLimit time.Duration) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rateLimit = rateLimit
+	return o
+}
+
+// SetMaxLines sets the maximum number of lines in a message. If maxLines is less or equal than 0, there is no limit.
+// The logs exceeding the maximum lines aren't kept until the next post, they are only counted.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetMaxLines(maxLines int) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxLines = maxLines
+	return o
+}
+
+// SetClient sets the HTTP client. By default, a client with the timeout of 10 seconds.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetClient(client *http.Client) *WebhookOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client = client
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying WebhookOutput.
+func (o *WebhookOutput) SetOnError(f func(error)) *WebhookOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *WebhookOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last message has been posted successfully.
+// It returns true if no message has been posted yet.
+func (o *WebhookOutput) Healthy() bool {
+	return o.healthy()
+}
+
+func (o *WebhookOutput) worker() {
+	defer o.wg.Done()
+	var last time.Time
+	for {
+		select {
+		case <-o.notifyCh:
+		case <-o.stopCh:
+			_, _ = o.post()
+			return
+		}
+		o.mu.Lock()
+		wait := o.batchWindow
+		if d := o.rateLimit - time.Since(last); d > wait {
+			wait = d
+		}
+		o.mu.Unlock()
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-o.stopCh:
+				timer.Stop()
+				_, _ = o.post()
+				return
+			}
+		}
+		if posted, _ := o.post(); posted {
+			last = time.Now()
+		}
+	}
+}
+
+// post posts the pending lines as a message, reports whether any message has been posted, and returns the error
+// if the message can't be posted.
+func (o *WebhookOutput) post() (posted bool, err error) {
+	o.postMu.Lock()
+	defer o.postMu.Unlock()
+	defer func() {
+		if err != nil {
+			o.handleError(err)
+		}
+	}()
+	o.mu.Lock()
+	lines, overflow, maxLines, format, client := o.lines, o.overflow, o.maxLines, o.format, o.client
+	o.lines, o.overflow = nil, 0
+	o.mu.Unlock()
+	if len(lines) == 0 {
+		return false, nil
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		overflow += len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+	if overflow > 0 {
+		lines = append(lines, fmt.Sprintf("... and %d more", overflow))
+	}
+	text := strings.Join(lines, "\n")
+
+	var payload interface{}
+	switch format {
+	case WebhookFormatDiscord:
+		payload = map[string]string{"content": text}
+	default:
+		payload = map[string]string{"text": text}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return true, fmt.Errorf("unable to marshal payload: %w", err)
+	}
+	resp, err := client.Post(o.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return true, fmt.Errorf("unable to post message: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return true, fmt.Errorf("unable to post message: unexpected status code %d", resp.StatusCode)
+	}
+	o.setHealthy()
+	return true, nil
+}
@@ -0,0 +1,437 @@
+package logng
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// websocketGUID is the GUID to compute Sec-WebSocket-Accept defined in RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes defined in RFC 6455.
+const (
+	websocketOpText  = 0x1
+	websocketOpClose = 0x8
+	websocketOpPing  = 0x9
+	websocketOpPong  = 0xa
+)
+
+// WebSocketSlowClientPolicy defines the behavior of WebSocketOutput when the queue of a client is full.
+type WebSocketSlowClientPolicy int
+
+const (
+	// WebSocketDropMessage drops the log for the slow client, and keeps the client connected.
+	WebSocketDropMessage WebSocketSlowClientPolicy = iota
+
+	// WebSocketDisconnect disconnects the slow client.
+	WebSocketDisconnect
+)
+
+// WebSocketOutput is an implementation of Output and http.Handler by streaming json encoded logs to the connected
+// WebSocket clients, e.g. for live-tailing dashboards.
+//
+// Each client receives the logs at or above its severity filter, which is given by the query parameter "severity"
+// on connecting, e.g. /logs?severity=warning, and can be changed by sending the severity name as a text message.
+// By default, clients receive all logs. Logs are queued for each client, and the slow clients are handled by
+// the slow client policy when their queues are full. By default, only the same-origin clients are accepted.
+type WebSocketOutput struct {
+	mu               sync.RWMutex
+	json             *JSONOutput
+	clients          map[*websocketClient]struct{}
+	queueSize        int
+	slowClientPolicy WebSocketSlowClientPolicy
+	checkOrigin      func(req *http.Request) bool
+	stopped          bool
+	health
+}
+
+// NewWebSocketOutput creates a new WebSocketOutput by the given json flags.
+// By default, the queue size of each client is 256 and the slow client policy is WebSocketDropMessage.
+func NewWebSocketOutput(flags JSONOutputFlag) *WebSocketOutput {
+	return &WebSocketOutput{
+		json:      NewJSONOutput(nil, flags),
+		clients:   make(map[*websocketClient]struct{}),
+		queueSize: 256,
+	}
+}
+
+// Log is the implementation of Output.
+func (o *WebSocketOutput) Log(log *Log) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if len(o.clients) == 0 {
+		return
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	o.json.mu.RLock()
+	err := o.json.format(buf, log)
+	o.json.mu.RUnlock()
+	if err != nil {
+		o.handleError(err)
+		return
+	}
+	frame := websocketFrame(websocketOpText, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+
+	healthy := true
+	for c := range o.clients {
+		if log.Severity.Rank() > c.Severity().Rank() {
+			continue
+		}
+		select {
+		case c.queue <- frame:
+		default:
+			healthy = false
+			if o.slowClientPolicy == WebSocketDisconnect {
+				c.close(1008, "slow client")
+				o.handleError(fmt.Errorf("slow client %s disconnected: %w", c.conn.RemoteAddr(), ErrQueueFull))
+				continue
+			}
+			o.handleError(fmt.Errorf("unable to send log to client %s: %w", c.conn.RemoteAddr(), ErrQueueFull))
+		}
+	}
+	if healthy {
+		o.setHealthy()
+	}
+}
+
+// ServeHTTP is the implementation of http.Handler.
+// ServeHTTP upgrades the connection to WebSocket, and streams logs until the client or the output is closed.
+func (o *WebSocketOutput) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet ||
+		!strings.EqualFold(req.Header.Get("Upgrade"), "websocket") ||
+		!headerContainsToken(req.Header, "Connection", "upgrade") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing websocket key", http.StatusBadRequest)
+		return
+	}
+	o.mu.RLock()
+	checkOrigin := o.checkOrigin
+	o.mu.RUnlock()
+	if checkOrigin == nil {
+		checkOrigin = websocketSameOrigin
+	}
+	if !checkOrigin(req) {
+		http.Error(w, "websocket origin not allowed", http.StatusForbidden)
+		return
+	}
+	severity := SeverityDebug
+	if name := req.URL.Query().Get("severity"); name != "" {
+		var err error
+		if severity, err = SeverityFromString(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		o.handleError(fmt.Errorf("unable to hijack connection: %w", err))
+		return
+	}
+
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(h.Sum(nil)) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		_ = conn.Close()
+		o.handleError(fmt.Errorf("unable to upgrade connection: %w", err))
+		return
+	}
+
+	o.mu.Lock()
+	if o.stopped {
+		o.mu.Unlock()
+		_, _ = conn.Write(websocketCloseFrame(1001, "going away"))
+		_ = conn.Close()
+		return
+	}
+	c := &websocketClient{
+		conn:     conn,
+		queue:    make(chan []byte, o.queueSize),
+		done:     make(chan struct{}),
+		severity: int64(severity),
+	}
+	o.clients[c] = struct{}{}
+	o.mu.Unlock()
+
+	go c.reader(rw.Reader)
+	c.writer()
+
+	o.mu.Lock()
+	delete(o.clients, c)
+	o.mu.Unlock()
+}
+
+// Close disconnects all clients, and stops accepting new clients.
+func (o *WebSocketOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		return nil
+	}
+	o.stopped = true
+	for c := range o.clients {
+		c.close(1001, "going away")
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients.
+func (o *WebSocketOutput) Clients() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.clients)
+}
+
+// SetQueueSize sets the queue size of the clients which connect after the call.
+// It returns the underlying WebSocketOutput.
+func (o *WebSocketOutput) SetQueueSize(queueSize int) *WebSocketOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.queueSize = queueSize
+	return o
+}
+
+// SetSlowClientPolicy sets the behavior when the queue of a client is full.
+// It returns the underlying WebSocketOutput.
+func (o *WebSocketOutput) SetSlowClientPolicy(policy WebSocketSlowClientPolicy) *WebSocketOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.slowClientPolicy = policy
+	return o
+}
+
+// SetCheckOrigin sets a function to check the Origin header of the handshake requests. The requests are rejected
+// with 403 Forbidden if it returns false. If f is nil, the default check is used, which accepts the requests
+// without Origin header and the requests whose Origin host equals the Host header.
+// It returns the underlying WebSocketOutput.
+func (o *WebSocketOutput) SetCheckOrigin(f func(req *http.Request) bool) *WebSocketOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.checkOrigin = f
+	return o
+}
+
+// SetOnError sets a function to call when error occurs.
+// It returns the underlying WebSocketOutput.
+func (o *WebSocketOutput) SetOnError(f func(error)) *WebSocketOutput {
+	o.setOnError(f)
+	return o
+}
+
+// Err returns the most recent error occurred while logging, or nil if no error has occurred yet.
+func (o *WebSocketOutput) Err() error {
+	return o.err()
+}
+
+// Healthy reports whether the last log has been queued to all clients successfully.
+// It returns true if no log has been queued yet.
+func (o *WebSocketOutput) Healthy() bool {
+	return o.healthy()
+}
+
+// websocketClient is a connected client of WebSocketOutput.
+type websocketClient struct {
+	// severity is accessed atomically, so it must be first to be 64-bit aligned on 32-bit platforms.
+	severity  int64
+	conn      net.Conn
+	writeMu   sync.Mutex
+	queue     chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Severity returns the severity filter of the client.
+func (c *websocketClient) Severity() Severity {
+	return Severity(atomic.LoadInt64(&c.severity))
+}
+
+// write writes the given frame with the write deadline.
+func (c *websocketClient) write(frame []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// close stops the writer, and closes the connection after sending the close frame with the given status code
+// and reason in the background. So, it doesn't block on slow clients.
+func (c *websocketClient) close(code uint16, reason string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		go func() {
+			_ = c.write(websocketCloseFrame(code, reason))
+			_ = c.conn.Close()
+		}()
+	})
+}
+
+func (c *websocketClient) writer() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case frame := <-c.queue:
+			if err := c.write(frame); err != nil {
+				c.close(1011, "write error")
+				return
+			}
+		}
+	}
+}
+
+// reader reads the frames from the client. It answers pings, updates the severity filter by text messages,
+// and closes the client by close frames or read errors.
+func (c *websocketClient) reader(r *bufio.Reader) {
+	for {
+		opcode, payload, err := readWebSocketFrame(r, 4096)
+		if err != nil {
+			c.close(1002, "protocol error")
+			return
+		}
+		switch opcode {
+		case websocketOpClose:
+			c.close(1000, "")
+			return
+		case websocketOpPing:
+			if err := c.write(websocketFrame(websocketOpPong, payload)); err != nil {
+				c.close(1011, "write error")
+				return
+			}
+		case websocketOpText:
+			if severity, err := SeverityFromString(strings.TrimSpace(string(payload))); err == nil {
+				atomic.StoreInt64(&c.severity, int64(severity))
+			}
+		}
+	}
+}
+
+// websocketFrame returns an unmasked final frame with the given opcode and payload.
+func websocketFrame(opcode byte, payload []byte) []byte {
+	n := len(payload)
+	frame := make([]byte, 0, n+10)
+	frame = append(frame, 0x80|opcode)
+	switch {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126, byte(n>>8), byte(n))
+	default:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		frame = append(append(frame, 127), b[:]...)
+	}
+	return append(frame, payload...)
+}
+
+// websocketCloseFrame returns a close frame with the given status code and reason.
+func websocketCloseFrame(code uint16, reason string) []byte {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, code)
+	return websocketFrame(websocketOpClose, append(payload, reason...))
+}
+
+// readWebSocketFrame reads a client frame, and returns its opcode and unmasked payload.
+// It returns an error if the frame isn't masked as RFC 6455 requires for client frames,
+// or if the payload is longer than maxSize.
+func readWebSocketFrame(r io.Reader, maxSize int) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = header[0] & 0x0f
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("unmasked client frame")
+	}
+	n := uint64(header[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err = io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err = io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > uint64(maxSize) {
+		return 0, nil, fmt.Errorf("frame too large: %d bytes", n)
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+// websocketSameOrigin reports whether the handshake request has no Origin header, or its Origin host equals
+// the Host header.
+func websocketSameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// headerContainsToken reports whether the comma separated values of the given header contain the token.
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, s := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
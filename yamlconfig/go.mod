@@ -0,0 +1,9 @@
+module github.com/goinsane/logng/v2/yamlconfig
+
+go 1.18
+
+require github.com/goinsane/logng/v2 v2.0.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/goinsane/logng/v2 => ../
@@ -0,0 +1,81 @@
+// Package yamlconfig provides parsing logng.Config from YAML documents.
+package yamlconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/goinsane/logng/v2"
+)
+
+// Parse parses the given YAML document as logng.Config, and creates a new logng.Logger by it.
+// The document has the same keys and values as the json document of logng.ParseConfig,
+// e.g. the severities are given by their names and the flags are given as a string or a sequence of names.
+func Parse(data []byte) (*logng.Logger, error) {
+	var x interface{}
+	if err := yaml.Unmarshal(data, &x); err != nil {
+		return nil, fmt.Errorf("unable to parse config: %w", err)
+	}
+	x, err := jsonValue(x)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse config: %w", err)
+	}
+	b, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse config: %w", err)
+	}
+	return logng.ParseConfig(b)
+}
+
+// Load reads the YAML document from the given file, and creates a new logng.Logger by Parse.
+func Load(path string) (*logng.Logger, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	return Parse(data)
+}
+
+// jsonValue converts the mappings which are decoded by yaml to the json objects recursively.
+// The keys of the mappings must be strings.
+func jsonValue(x interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			x[k] = v
+		}
+		return x, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid key %v", k)
+			}
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case []interface{}:
+		for i, v := range x {
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = v
+		}
+		return x, nil
+	default:
+		return x, nil
+	}
+}
@@ -0,0 +1,63 @@
+package yamlconfig_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goinsane/logng/v2/yamlconfig"
+)
+
+func ExampleParse() {
+	logger, err := yamlconfig.Parse([]byte(`
+severity: info
+output:
+  format: text
+  text_flags: severity|fields
+fields:
+  app: example
+`))
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Close()
+
+	logger.Info("this is info log.")
+	logger.Debug("this is debug log. it won't be shown.")
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logng-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "app.log")
+	configPath := filepath.Join(dir, "logng.yaml")
+	config := "severity: warn\noutput:\n  path: " + logPath + "\n  text_flags: [severity]\n"
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err := yamlconfig.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Warning("this is warning log.")
+	logger.Info("this is info log.")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "WARNING - this is warning log.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := yamlconfig.Parse([]byte("output: [")); err == nil {
+		t.Error("got nil error for the invalid document")
+	}
+}